@@ -3,75 +3,590 @@ package client
 import (
 	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"time"
+	"unicode/utf8"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
+	"GRPC-KV-Store-System/schemas/certs"
 	pb "GRPC-KV-Store-System/schemas/grpc"
+	"GRPC-KV-Store-System/schemas/logging"
+	"GRPC-KV-Store-System/schemas/rbac"
+	"GRPC-KV-Store-System/schemas/tracing"
 )
 
 type KVStoreClient struct {
 	client pb.KeyValueStoreClient
 	conn   *grpc.ClientConn
+	addr   string
+	// namespace is sent with every request on keys.
+	namespace string
+	// principal is who requests are made for, named to the KV store so it
+	// can authorize them. Empty when unknown.
+	principal string
+	timeouts  Timeouts
 }
 
-func StartClient(grpcServerAddr string) (*KVStoreClient, error) {
-	conn, err := grpc.NewClient(grpcServerAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// StartClient connects to a kvStore-service in plaintext, unless opts
+// give other transport credentials.
+func StartClient(grpcServerAddr string, opts ...grpc.DialOption) (*KVStoreClient, error) {
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(tracing.ClientHandler()),
+	}, opts...)
+
+	conn, err := grpc.NewClient(grpcServerAddr, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to gRPC server: %v", err)
 	}
 
 	client := pb.NewKeyValueStoreClient(conn)
-	log.Printf("Connected to gRPC server at %s", grpcServerAddr)
+	slog.Info("Connected to gRPC server", "addr", grpcServerAddr)
 
 	return &KVStoreClient{
-		client: client,
-		conn:   conn,
+		client:   client,
+		conn:     conn,
+		addr:     grpcServerAddr,
+		timeouts: DefaultTimeouts(),
 	}, nil
 }
 
+// SetTimeouts bounds requests made with a context without a deadline.
+func (c *KVStoreClient) SetTimeouts(timeouts Timeouts) {
+	c.timeouts = timeouts
+}
+
 func (c *KVStoreClient) Close() error {
 	return c.conn.Close()
 }
 
-func (c *KVStoreClient) Set(key, value string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (c *KVStoreClient) Set(ctx context.Context, key, value string) (int64, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "Set")
+	defer cancel()
+
+	req := &pb.SetRequest{Namespace: c.namespace, Key: key}
+	req.Value, req.ValueBytes = wireValue(value)
+
+	resp, err := c.client.Set(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Version, nil
+}
+
+func (c *KVStoreClient) SetWithTTL(ctx context.Context, key, value string, ttl time.Duration) (int64, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "Set")
+	defer cancel()
+
+	req := &pb.SetRequest{
+		Namespace:  c.namespace,
+		Key:        key,
+		TtlSeconds: int64(ttl / time.Second),
+	}
+	req.Value, req.ValueBytes = wireValue(value)
+
+	resp, err := c.client.Set(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Version, nil
+}
+
+func (c *KVStoreClient) CompareAndSwap(ctx context.Context, key string, expectedVersion int64, value string, ttl time.Duration) (int64, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "CompareAndSwap")
 	defer cancel()
 
-	_, err := c.client.Set(ctx, &pb.SetRequest{
-		Key:   key,
-		Value: value,
+	req := &pb.CompareAndSwapRequest{
+		Namespace:  c.namespace,
+		Key:        key,
+		Expected:   &pb.CompareAndSwapRequest_ExpectedVersion{ExpectedVersion: expectedVersion},
+		TtlSeconds: int64(ttl / time.Second),
+	}
+	req.Value, req.ValueBytes = wireValue(value)
+
+	resp, err := c.client.CompareAndSwap(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Version, nil
+}
+
+func (c *KVStoreClient) Increment(ctx context.Context, key string, delta int64, opts CounterOptions) (int64, int64, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "Increment")
+	defer cancel()
+
+	resp, err := c.client.Increment(ctx, &pb.CounterRequest{
+		Namespace:    c.namespace,
+		Key:          key,
+		Delta:        &delta,
+		InitialValue: opts.Initial,
+		Min:          opts.Min,
+		Max:          opts.Max,
 	})
+	if err != nil {
+		return 0, 0, err
+	}
 
-	return err
+	return resp.Value, resp.Version, nil
 }
 
-func (c *KVStoreClient) Get(key string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (c *KVStoreClient) Get(ctx context.Context, key string) (string, error) {
+	value, _, err := c.GetWithVersion(ctx, key)
+	return value, err
+}
+
+func (c *KVStoreClient) GetWithVersion(ctx context.Context, key string) (string, int64, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "Get")
 	defer cancel()
 
 	resp, err := c.client.Get(ctx, &pb.GetRequest{
-		Key: key,
+		Namespace: c.namespace,
+		Key:       key,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	return fromWire(resp.Value, resp.ValueBytes), resp.Version, nil
+}
+
+func (c *KVStoreClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "TTL")
+	defer cancel()
+
+	resp, err := c.client.TTL(ctx, &pb.TTLRequest{
+		Namespace: c.namespace,
+		Key:       key,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.TtlSeconds < 0 {
+		return NoExpiry, nil
+	}
+
+	return time.Duration(resp.TtlSeconds) * time.Second, nil
+}
+
+func (c *KVStoreClient) Scan(ctx context.Context, prefix string, limit int, cursor string) ([]KeyValue, string, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "Scan")
+	defer cancel()
+
+	resp, err := c.client.Scan(ctx, &pb.ScanRequest{
+		Namespace: c.namespace,
+		Prefix:    prefix,
+		Limit:     int32(limit),
+		PageToken: cursor,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := make([]KeyValue, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		items = append(items, KeyValue{
+			Key:     item.Key,
+			Value:   fromWire(item.Value, item.ValueBytes),
+			Version: item.Version,
+		})
+	}
+
+	return items, resp.NextPageToken, nil
+}
+
+func (c *KVStoreClient) Txn(ctx context.Context, txn Txn) (TxnResult, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "Txn")
+	defer cancel()
+
+	req := &pb.TxnRequest{
+		Namespace: c.namespace,
+		Compare:   make([]*pb.Compare, 0, len(txn.Compare)),
+		Success:   toTxnOps(txn.Success),
+		Failure:   toTxnOps(txn.Failure),
+	}
+	for _, cmp := range txn.Compare {
+		compare := &pb.Compare{
+			Key:     cmp.Key,
+			Target:  pb.Compare_Target(cmp.Target),
+			Result:  pb.Compare_Result(cmp.Result),
+			Version: cmp.Version,
+			Exists:  cmp.Exists,
+		}
+		compare.Value, compare.ValueBytes = wireValue(cmp.Value)
+		req.Compare = append(req.Compare, compare)
+	}
+
+	resp, err := c.client.Txn(ctx, req)
+	if err != nil {
+		return TxnResult{}, err
+	}
+
+	result := TxnResult{
+		Succeeded: resp.Succeeded,
+		Results:   make([]TxnOpResult, 0, len(resp.Results)),
+		Revision:  resp.Revision,
+	}
+	for _, r := range resp.Results {
+		result.Results = append(result.Results, TxnOpResult{
+			Key:     r.Key,
+			Value:   fromWire(r.Value, r.ValueBytes),
+			Version: r.Version,
+			Found:   r.Found,
+		})
+	}
+
+	return result, nil
+}
+
+func (c *KVStoreClient) Watch(ctx context.Context, prefix string, startRevision int64) (WatchStream, error) {
+	stream, err := c.client.Watch(c.outgoing(ctx), &pb.WatchRequest{
+		Namespace:     c.namespace,
+		Key:           prefix,
+		Prefix:        true,
+		StartRevision: startRevision,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The server sends headers once the watch is in place. A watch that
+	// could not start ends without any, and Recv returns why.
+	if header, _ := stream.Header(); header == nil {
+		_, err := stream.Recv()
+		return nil, err
+	}
+
+	return &watchStream{stream: stream}, nil
+}
+
+type watchStream struct {
+	stream pb.KeyValueStore_WatchClient
+}
+
+func (s *watchStream) Recv() (WatchEvent, error) {
+	ev, err := s.stream.Recv()
+	if err != nil {
+		return WatchEvent{}, err
+	}
+
+	eventType := EventPut
+	if ev.Type == pb.WatchEvent_DELETE {
+		eventType = EventDelete
+	}
+
+	return WatchEvent{
+		Type:     eventType,
+		Key:      ev.Key,
+		Value:    fromWire(ev.Value, ev.ValueBytes),
+		Revision: ev.Revision,
+	}, nil
+}
+
+func (c *KVStoreClient) BatchSet(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "BatchSet")
+	defer cancel()
+
+	req := &pb.BatchSetRequest{
+		Namespace: c.namespace,
+		Items:     make([]*pb.SetRequest, 0, len(items)),
+	}
+	for _, item := range items {
+		req.Items = append(req.Items, toSetRequest(item))
+	}
+
+	resp, err := c.client.BatchSet(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBatchResults(resp), nil
+}
+
+func (c *KVStoreClient) BatchGet(ctx context.Context, keys []string) ([]BatchResult, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "BatchGet")
+	defer cancel()
+
+	resp, err := c.client.BatchGet(ctx, &pb.BatchKeysRequest{
+		Namespace: c.namespace,
+		Keys:      keys,
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return resp.Value, nil
+	return toBatchResults(resp), nil
 }
 
-func (c *KVStoreClient) Delete(key string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (c *KVStoreClient) BatchDelete(ctx context.Context, keys []string) ([]BatchResult, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "BatchDelete")
+	defer cancel()
+
+	resp, err := c.client.BatchDelete(ctx, &pb.BatchKeysRequest{
+		Namespace: c.namespace,
+		Keys:      keys,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toBatchResults(resp), nil
+}
+
+func (c *KVStoreClient) BulkLoad(ctx context.Context, next func() (BatchItem, error)) (BulkLoadResult, error) {
+	// Cancelling aborts the stream if reading an item fails.
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "BulkLoad")
+	defer cancel()
+
+	stream, err := c.client.BulkLoad(ctx)
+	if err != nil {
+		return BulkLoadResult{}, err
+	}
+
+	for {
+		item, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return BulkLoadResult{}, err
+		}
+
+		req := toSetRequest(item)
+		req.Namespace = c.namespace
+
+		if err := stream.Send(req); err != nil {
+			// The server ended the stream; CloseAndRecv returns why.
+			break
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return BulkLoadResult{}, err
+	}
+
+	result := BulkLoadResult{
+		Loaded:   resp.Loaded,
+		Failed:   resp.Failed,
+		Failures: make([]BulkLoadFailure, 0, len(resp.Failures)),
+	}
+	for _, f := range resp.Failures {
+		result.Failures = append(result.Failures, BulkLoadFailure{
+			Index: f.Index,
+			Key:   f.Key,
+			Err:   status.Error(codes.Code(f.Code), f.Error),
+		})
+	}
+
+	return result, nil
+}
+
+func (c *KVStoreClient) Delete(ctx context.Context, key string) error {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "Delete")
 	defer cancel()
 
 	_, err := c.client.Delete(ctx, &pb.DeleteRequest{
-		Key: key,
+		Namespace: c.namespace,
+		Key:       key,
 	})
 
 	return err
 }
 
+func (c *KVStoreClient) Namespace(name string) ClientInterface {
+	return &KVStoreClient{
+		client:    c.client,
+		conn:      c.conn,
+		addr:      c.addr,
+		namespace: name,
+		principal: c.principal,
+		timeouts:  c.timeouts,
+	}
+}
+
+func (c *KVStoreClient) As(principal string) ClientInterface {
+	return &KVStoreClient{
+		client:    c.client,
+		conn:      c.conn,
+		addr:      c.addr,
+		namespace: c.namespace,
+		principal: principal,
+		timeouts:  c.timeouts,
+	}
+}
+
+// outgoing names the client's principal, and the request ID ctx carries,
+// in the metadata of requests made with ctx.
+func (c *KVStoreClient) outgoing(ctx context.Context) context.Context {
+	if id := logging.RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, logging.RequestIDHeader, id)
+	}
+	if c.principal == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, rbac.PrincipalHeader, c.principal)
+}
+
+func (c *KVStoreClient) CreateNamespace(ctx context.Context, name string) error {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "CreateNamespace")
+	defer cancel()
+
+	_, err := c.client.CreateNamespace(ctx, &pb.CreateNamespaceRequest{
+		Name: name,
+	})
+
+	return err
+}
+
+func (c *KVStoreClient) ListNamespaces(ctx context.Context) ([]string, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "ListNamespaces")
+	defer cancel()
+
+	resp, err := c.client.ListNamespaces(ctx, &pb.ListNamespacesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Names, nil
+}
+
+func (c *KVStoreClient) DropNamespace(ctx context.Context, name string) (int64, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "DropNamespace")
+	defer cancel()
+
+	resp, err := c.client.DropNamespace(ctx, &pb.DropNamespaceRequest{
+		Name: name,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.DeletedKeys, nil
+}
+
+func (c *KVStoreClient) Usage(ctx context.Context) (Usage, error) {
+	ctx, cancel := c.timeouts.withTimeout(c.outgoing(ctx), "Usage")
+	defer cancel()
+
+	resp, err := c.client.Usage(ctx, &pb.UsageRequest{
+		Namespace: c.namespace,
+	})
+	if err != nil {
+		return Usage{}, err
+	}
+
+	return Usage{
+		Namespace:     resp.Namespace,
+		Keys:          resp.KeyCount,
+		Bytes:         resp.Bytes,
+		MaxKeys:       resp.MaxKeys,
+		MaxBytes:      resp.MaxBytes,
+		MaxValueBytes: resp.MaxValueBytes,
+		OpsPerSecond:  resp.OpsPerSecond,
+	}, nil
+}
+
+func (c *KVStoreClient) Health(ctx context.Context) []BackendHealth {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	backend := BackendHealth{Addr: c.addr, Status: healthpb.HealthCheckResponse_UNKNOWN.String()}
+
+	resp, err := healthpb.NewHealthClient(c.conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: pb.KeyValueStore_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		backend.Err = err
+	} else {
+		backend.Status = resp.Status.String()
+	}
+
+	// Read after the check, which connects an idle connection.
+	backend.Connection = c.conn.GetState().String()
+
+	return []BackendHealth{backend}
+}
+
+func toSetRequest(item BatchItem) *pb.SetRequest {
+	req := &pb.SetRequest{
+		Key:        item.Key,
+		TtlSeconds: int64(item.TTL / time.Second),
+	}
+	req.Value, req.ValueBytes = wireValue(item.Value)
+
+	return req
+}
+
+func toBatchResults(resp *pb.BatchResponse) []BatchResult {
+	results := make([]BatchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		result := BatchResult{
+			Key:     r.Key,
+			Value:   fromWire(r.Value, r.ValueBytes),
+			Version: r.Version,
+		}
+		if r.Code != 0 {
+			result.Err = status.Error(codes.Code(r.Code), r.Error)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func toTxnOps(ops []TxnOp) []*pb.TxnOp {
+	converted := make([]*pb.TxnOp, 0, len(ops))
+	for _, op := range ops {
+		txnOp := &pb.TxnOp{
+			Type:       pb.TxnOp_Type(op.Type),
+			Key:        op.Key,
+			TtlSeconds: int64(op.TTL / time.Second),
+		}
+		txnOp.Value, txnOp.ValueBytes = wireValue(op.Value)
+		converted = append(converted, txnOp)
+	}
+	return converted
+}
+
+// wireValue splits value into the string and bytes fields of a request.
+// Proto strings must be valid UTF-8, so any other value is sent as bytes.
+func wireValue(value string) (string, []byte) {
+	if utf8.ValidString(value) {
+		return value, nil
+	}
+	return "", []byte(value)
+}
+
+// fromWire returns the value a response carries in either field.
+func fromWire(value string, valueBytes []byte) string {
+	if len(valueBytes) > 0 {
+		return string(valueBytes)
+	}
+	return value
+}
+
 // Ensure KVStoreClient implements ClientInterface
 var _ ClientInterface = (*KVStoreClient)(nil)
+
+// WithTLS is the dial option to connect over TLS, verifying servers with
+// opts.CAFile and presenting opts.CertFile to those that ask for a client
+// certificate.
+func WithTLS(opts certs.Options) (grpc.DialOption, error) {
+	config, err := certs.ClientConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
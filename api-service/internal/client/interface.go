@@ -1,9 +1,224 @@
 package client
 
-// ClientInterface defines the contract for KV store operations
+import (
+	"context"
+	"time"
+)
+
+// NoExpiry is returned by TTL for keys that never expire.
+const NoExpiry time.Duration = -1
+
+type KeyValue struct {
+	Key     string
+	Value   string
+	Version int64
+}
+
+type CompareTarget int
+
+const (
+	CompareValue CompareTarget = iota
+	CompareVersion
+	CompareExists
+)
+
+type CompareResult int
+
+const (
+	Equal CompareResult = iota
+	NotEqual
+	Greater
+	Less
+)
+
+// Compare is a condition on one key checked by Txn. A missing key is at
+// version 0.
+type Compare struct {
+	Key     string
+	Target  CompareTarget
+	Result  CompareResult
+	Value   string
+	Version int64
+	Exists  bool
+}
+
+type TxnOpType int
+
+const (
+	TxnGet TxnOpType = iota
+	TxnPut
+	TxnDelete
+)
+
+type TxnOp struct {
+	Type  TxnOpType
+	Key   string
+	Value string
+	TTL   time.Duration
+}
+
+type Txn struct {
+	Compare []Compare
+	Success []TxnOp
+	Failure []TxnOp
+}
+
+type TxnOpResult struct {
+	Key     string
+	Value   string
+	Version int64
+	Found   bool
+}
+
+type TxnResult struct {
+	Succeeded bool
+	Results   []TxnOpResult
+	Revision  int64
+}
+
+type EventType string
+
+const (
+	EventPut    EventType = "put"
+	EventDelete EventType = "delete"
+)
+
+type WatchEvent struct {
+	Type     EventType
+	Key      string
+	Value    string
+	Revision int64
+}
+
+// WatchStream delivers the events of a watch.
+type WatchStream interface {
+	// Recv blocks for the next event, or returns the error that ended
+	// the watch.
+	Recv() (WatchEvent, error)
+}
+
+// CounterOptions shape an Increment.
+type CounterOptions struct {
+	// Initial is the value a missing key starts from.
+	Initial int64
+	// Min and Max, when set, bound the new value, inclusive.
+	Min *int64
+	Max *int64
+}
+
+type BatchItem struct {
+	Key   string
+	Value string
+	TTL   time.Duration
+}
+
+// BatchResult is the outcome of one batch item. Err is the item's gRPC
+// status error when it failed.
+type BatchResult struct {
+	Key     string
+	Value   string
+	Version int64
+	Err     error
+}
+
+type BulkLoadFailure struct {
+	Index int64
+	Key   string
+	Err   error
+}
+
+type BulkLoadResult struct {
+	Loaded int64
+	Failed int64
+	// Failures holds the first failures, up to 100.
+	Failures []BulkLoadFailure
+}
+
+// Usage is what a namespace holds against its quotas. Quotas of zero are
+// unlimited.
+type Usage struct {
+	Namespace     string
+	Keys          int64
+	Bytes         int64
+	MaxKeys       int64
+	MaxBytes      int64
+	MaxValueBytes int64
+	OpsPerSecond  float64
+}
+
+// BackendHealth is how a kvStore-service backend answered a health check.
+type BackendHealth struct {
+	// Shard is the backend's shard name, empty when unsharded.
+	Shard string
+	Addr  string
+	// Connection is the state of the gRPC connection, such as READY or
+	// TRANSIENT_FAILURE.
+	Connection string
+	// Status is the serving status the backend reported for the
+	// KeyValueStore service, or UNKNOWN when the check failed.
+	Status string
+	Err    error
+}
+
+// Serving reports whether the backend can take requests.
+func (b BackendHealth) Serving() bool {
+	return b.Err == nil && b.Status == "SERVING"
+}
+
+// ClientInterface defines the contract for KV store operations. Requests
+// end when their ctx is done, and continue its trace and carry its request
+// ID to the KV store.
 type ClientInterface interface {
-	Set(key, value string) error
-	Get(key string) (string, error)
-	Delete(key string) error
+	// Writes return the version the key was given.
+	Set(ctx context.Context, key, value string) (int64, error)
+	SetWithTTL(ctx context.Context, key, value string, ttl time.Duration) (int64, error)
+	// CompareAndSwap writes value only if the key is still at
+	// expectedVersion; zero means the key must not exist yet.
+	CompareAndSwap(ctx context.Context, key string, expectedVersion int64, value string, ttl time.Duration) (int64, error)
+	// Increment atomically adds delta, which may be negative, to the
+	// integer key holds and returns the new value and version.
+	Increment(ctx context.Context, key string, delta int64, opts CounterOptions) (int64, int64, error)
+	Get(ctx context.Context, key string) (string, error)
+	GetWithVersion(ctx context.Context, key string) (string, int64, error)
+	// TTL returns how long key has left, rounded up to the second, or
+	// NoExpiry.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Scan lists up to limit keys starting with prefix in order, resuming
+	// after cursor. The returned cursor is empty on the last page.
+	Scan(ctx context.Context, prefix string, limit int, cursor string) ([]KeyValue, string, error)
+	// Txn runs txn.Success if every compare holds and txn.Failure
+	// otherwise, atomically.
+	Txn(ctx context.Context, txn Txn) (TxnResult, error)
+	// Watch streams changes to keys starting with prefix until ctx is
+	// done, replaying from startRevision when it is positive. It returns
+	// once the watch is in place.
+	Watch(ctx context.Context, prefix string, startRevision int64) (WatchStream, error)
+	// Batches return a result per item, in order. Items that fail do not
+	// stop the others.
+	BatchSet(ctx context.Context, items []BatchItem) ([]BatchResult, error)
+	BatchGet(ctx context.Context, keys []string) ([]BatchResult, error)
+	BatchDelete(ctx context.Context, keys []string) ([]BatchResult, error)
+	// BulkLoad streams the items next returns until it returns io.EOF.
+	// Items are committed as they arrive, so a failed load may have
+	// written some of them.
+	BulkLoad(ctx context.Context, next func() (BatchItem, error)) (BulkLoadResult, error)
+	Delete(ctx context.Context, key string) error
+	// Namespace returns a client for the keys of a namespace, sharing this
+	// client's connections. An empty name is the default namespace.
+	Namespace(name string) ClientInterface
+	// As returns a client whose requests name principal to the KV store,
+	// which authorizes them for it when it enforces an RBAC policy.
+	As(principal string) ClientInterface
+	CreateNamespace(ctx context.Context, name string) error
+	// ListNamespaces returns every namespace but the default one, in order.
+	ListNamespaces(ctx context.Context) ([]string, error)
+	// DropNamespace deletes a namespace and every key in it, returning
+	// how many keys were deleted.
+	DropNamespace(ctx context.Context, name string) (int64, error)
+	// Usage reports what the client's namespace holds against its quotas.
+	// Requests over a quota fail with ResourceExhausted.
+	Usage(ctx context.Context) (Usage, error)
+	// Health checks every backend over the grpc.health.v1 protocol.
+	Health(ctx context.Context) []BackendHealth
 	Close() error
 }
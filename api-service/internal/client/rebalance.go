@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultRebalancePageSize = 500
+
+type RebalanceOptions struct {
+	// PageSize is how many keys are read from a shard at a time.
+	PageSize int
+	// DryRun counts the keys that would move without moving them.
+	DryRun bool
+	// Progress, when set, is called after each page of keys is read.
+	Progress func(RebalanceStats)
+}
+
+type RebalanceStats struct {
+	Scanned int64
+	Moved   int64
+	// Conflicts are keys written on their old shard while being moved.
+	// They are left there; running Rebalance again moves them.
+	Conflicts int64
+}
+
+// Rebalance moves every key held by one of shards, in every namespace, to
+// the shard ring places it on, until ctx is done. The shards map must hold
+// a client for every shard on ring and for every shard being removed.
+//
+// It is meant to run once the api-services use ring, so new writes land
+// on a key's new shard: a key that already exists there is newer than the
+// copy being moved, which is dropped. Keys read before they are moved are
+// missing until Rebalance reaches them. Rebalance can be run again, and
+// only moves what is still out of place.
+func Rebalance(ctx context.Context, shards map[string]ClientInterface, ring *Ring, opts RebalanceOptions) (RebalanceStats, error) {
+	for _, name := range ring.Shards() {
+		if _, ok := shards[name]; !ok {
+			return RebalanceStats{}, fmt.Errorf("no client for shard %s", name)
+		}
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultRebalancePageSize
+	}
+
+	// Every namespace has to exist on the shards its keys move to before
+	// they can be moved.
+	namespaces := map[string]bool{"": true}
+	for name, shard := range shards {
+		names, err := shard.ListNamespaces(ctx)
+		if err != nil {
+			return RebalanceStats{}, fmt.Errorf("failed to list namespaces on shard %s: %w", name, err)
+		}
+
+		for _, namespace := range names {
+			namespaces[namespace] = true
+		}
+	}
+
+	var stats RebalanceStats
+	for _, namespace := range slices.Sorted(maps.Keys(namespaces)) {
+		if namespace != "" && !opts.DryRun {
+			for _, name := range ring.Shards() {
+				if err := shards[name].CreateNamespace(ctx, namespace); err != nil && status.Code(err) != codes.AlreadyExists {
+					return stats, fmt.Errorf("failed to create namespace %s on shard %s: %w", namespace, name, err)
+				}
+			}
+		}
+
+		scoped := make(map[string]ClientInterface, len(shards))
+		for name, shard := range shards {
+			scoped[name] = shard.Namespace(namespace)
+		}
+
+		if err := rebalanceKeyspace(ctx, scoped, ring, pageSize, opts, &stats); err != nil {
+			if namespace != "" {
+				return stats, fmt.Errorf("namespace %s: %w", namespace, err)
+			}
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+// rebalanceKeyspace moves the keys of one namespace, adding to stats.
+func rebalanceKeyspace(ctx context.Context, shards map[string]ClientInterface, ring *Ring, pageSize int, opts RebalanceOptions, stats *RebalanceStats) error {
+	for _, name := range slices.Sorted(maps.Keys(shards)) {
+		source := shards[name]
+
+		cursor := ""
+		for {
+			items, next, err := source.Scan(ctx, "", pageSize, cursor)
+			if status.Code(err) == codes.NotFound {
+				// The namespace is not on this shard.
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("failed to scan shard %s: %w", name, err)
+			}
+
+			for _, item := range items {
+				stats.Scanned++
+
+				owner := ring.Owner(item.Key)
+				if owner == name {
+					continue
+				}
+
+				if opts.DryRun {
+					stats.Moved++
+					continue
+				}
+
+				moved, err := moveKey(ctx, source, shards[owner], item)
+				if err != nil {
+					return fmt.Errorf("failed to move %s from %s to %s: %w", item.Key, name, owner, err)
+				}
+
+				if moved {
+					stats.Moved++
+				} else {
+					stats.Conflicts++
+				}
+			}
+
+			if opts.Progress != nil {
+				opts.Progress(*stats)
+			}
+
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+	}
+
+	return nil
+}
+
+// moveKey copies item to target unless target already has the key, then
+// deletes it from source if it is still at the version read. It reports
+// false if the key changed on source meanwhile.
+func moveKey(ctx context.Context, source, target ClientInterface, item KeyValue) (bool, error) {
+	ttl, err := source.TTL(ctx, item.Key)
+	if status.Code(err) == codes.NotFound {
+		// Deleted or expired since it was read.
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if ttl == NoExpiry {
+		ttl = 0
+	}
+
+	// Expected version 0 only creates the key, so a newer value written
+	// through the new ring is never overwritten.
+	if _, err := target.CompareAndSwap(ctx, item.Key, 0, item.Value, ttl); err != nil && status.Code(err) != codes.FailedPrecondition {
+		return false, err
+	}
+
+	result, err := source.Txn(ctx, Txn{
+		Compare: []Compare{{Key: item.Key, Target: CompareVersion, Result: Equal, Version: item.Version}},
+		Success: []TxnOp{{Type: TxnDelete, Key: item.Key}},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.Succeeded, nil
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultVirtualNodes is how many points each shard gets on a Ring. More
+// points spread keys more evenly at the cost of a larger ring.
+const DefaultVirtualNodes = 128
+
+// Shard is a kvStore-service backend. Keys are placed by Name, so a shard
+// can move to a new Addr without its keys moving.
+type Shard struct {
+	Name string
+	Addr string
+}
+
+// ParseShards parses a comma-separated list of name=address shards. A
+// shard given as a bare address is named after it.
+func ParseShards(s string) ([]Shard, error) {
+	var shards []Shard
+	seen := make(map[string]bool)
+
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		name, addr, found := strings.Cut(field, "=")
+		if !found {
+			addr = name
+		}
+
+		if name == "" || addr == "" {
+			return nil, fmt.Errorf("invalid shard %q, expected name=address", field)
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("shard %q is listed twice", name)
+		}
+		seen[name] = true
+
+		shards = append(shards, Shard{Name: name, Addr: addr})
+	}
+
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("no shards given")
+	}
+
+	return shards, nil
+}
+
+type ringPoint struct {
+	hash  uint64
+	shard string
+}
+
+// Ring places keys on shards by consistent hashing. Each shard owns the
+// arcs ending at its virtual nodes, so adding or removing a shard only
+// moves the keys on the arcs it gains or gives up.
+type Ring struct {
+	points []ringPoint
+	shards []string
+}
+
+func NewRing(shards []string, virtualNodes int) *Ring {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	r := &Ring{shards: slices.Clone(shards)}
+	for _, shard := range shards {
+		for n := range virtualNodes {
+			r.points = append(r.points, ringPoint{hash: hashKey(shard + "#" + strconv.Itoa(n)), shard: shard})
+		}
+	}
+
+	slices.SortFunc(r.points, func(a, b ringPoint) int {
+		if a.hash != b.hash {
+			return cmp.Compare(a.hash, b.hash)
+		}
+		// Break the rare tie the same way on every api-service.
+		return strings.Compare(a.shard, b.shard)
+	})
+
+	return r
+}
+
+// Shards returns the names of the shards on the ring.
+func (r *Ring) Shards() []string {
+	return slices.Clone(r.shards)
+}
+
+// Owner returns the shard key belongs to: the first virtual node at or
+// after the key's hash, wrapping around.
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	h := hashKey(key)
+	n, _ := slices.BinarySearchFunc(r.points, h, func(p ringPoint, h uint64) int {
+		return cmp.Compare(p.hash, h)
+	})
+	if n == len(r.points) {
+		n = 0
+	}
+
+	return r.points[n].shard
+}
+
+// hashKey is 64-bit FNV-1a with a final mix, since FNV alone spreads keys
+// that differ only in their last bytes poorly.
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+
+	return x
+}
@@ -0,0 +1,666 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBulkLoadFailures caps the failures a sharded BulkLoad reports, like a
+// single backend does.
+const maxBulkLoadFailures = 100
+
+// ShardedClient spreads keys across several kvStore-service backends with
+// a consistent-hash Ring. Single-key calls go to the shard that owns the
+// key; scans, batches, bulk loads and watches fan out to every shard and
+// merge what comes back. A Txn must keep all of its keys on one shard.
+type ShardedClient struct {
+	ring   *Ring
+	shards map[string]ClientInterface
+}
+
+func StartShardedClient(shards []Shard, virtualNodes int, opts ...grpc.DialOption) (*ShardedClient, error) {
+	clients := make(map[string]ClientInterface, len(shards))
+	for _, shard := range shards {
+		c, err := StartClient(shard.Addr, opts...)
+		if err != nil {
+			for _, opened := range clients {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("failed to connect to shard %s: %w", shard.Name, err)
+		}
+
+		clients[shard.Name] = c
+	}
+
+	slog.Info("Sharding keys", "backends", len(shards))
+
+	return NewShardedClient(clients, virtualNodes), nil
+}
+
+// NewShardedClient shards keys across clients, which are keyed by shard
+// name.
+func NewShardedClient(clients map[string]ClientInterface, virtualNodes int) *ShardedClient {
+	return &ShardedClient{
+		ring:   NewRing(slices.Sorted(maps.Keys(clients)), virtualNodes),
+		shards: clients,
+	}
+}
+
+// SetTimeouts bounds requests made with a context without a deadline, on
+// every shard that takes timeouts.
+func (c *ShardedClient) SetTimeouts(timeouts Timeouts) {
+	for _, shard := range c.shards {
+		if s, ok := shard.(interface{ SetTimeouts(Timeouts) }); ok {
+			s.SetTimeouts(timeouts)
+		}
+	}
+}
+
+// Ring returns the ring keys are placed by.
+func (c *ShardedClient) Ring() *Ring {
+	return c.ring
+}
+
+func (c *ShardedClient) shard(key string) ClientInterface {
+	return c.shards[c.ring.Owner(key)]
+}
+
+func (c *ShardedClient) Set(ctx context.Context, key, value string) (int64, error) {
+	return c.shard(key).Set(ctx, key, value)
+}
+
+func (c *ShardedClient) SetWithTTL(ctx context.Context, key, value string, ttl time.Duration) (int64, error) {
+	return c.shard(key).SetWithTTL(ctx, key, value, ttl)
+}
+
+func (c *ShardedClient) CompareAndSwap(ctx context.Context, key string, expectedVersion int64, value string, ttl time.Duration) (int64, error) {
+	return c.shard(key).CompareAndSwap(ctx, key, expectedVersion, value, ttl)
+}
+
+func (c *ShardedClient) Increment(ctx context.Context, key string, delta int64, opts CounterOptions) (int64, int64, error) {
+	return c.shard(key).Increment(ctx, key, delta, opts)
+}
+
+func (c *ShardedClient) Get(ctx context.Context, key string) (string, error) {
+	return c.shard(key).Get(ctx, key)
+}
+
+func (c *ShardedClient) GetWithVersion(ctx context.Context, key string) (string, int64, error) {
+	return c.shard(key).GetWithVersion(ctx, key)
+}
+
+func (c *ShardedClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.shard(key).TTL(ctx, key)
+}
+
+func (c *ShardedClient) Delete(ctx context.Context, key string) error {
+	return c.shard(key).Delete(ctx, key)
+}
+
+// scanCursor resumes a sharded Scan. Shards holds the cursor of the page
+// each unfinished shard resumes from; keys up to After on those pages were
+// already returned.
+type scanCursor struct {
+	After  string            `json:"after"`
+	Shards map[string]string `json:"shards"`
+}
+
+type scanPage struct {
+	items []KeyValue
+	// from is the cursor the page was read from and next the one after it.
+	from, next string
+}
+
+// Scan reads a page from every shard and merges them in key order. Each
+// shard resumes from the page holding the first key it has not returned.
+func (c *ShardedClient) Scan(ctx context.Context, prefix string, limit int, cursor string) ([]KeyValue, string, error) {
+	if limit < 0 {
+		return nil, "", status.Error(codes.InvalidArgument, "limit cannot be negative")
+	}
+
+	pos := scanCursor{Shards: make(map[string]string)}
+	if cursor == "" {
+		for name := range c.shards {
+			pos.Shards[name] = ""
+		}
+	} else if err := decodeScanCursor(cursor, &pos); err != nil {
+		return nil, "", status.Error(codes.InvalidArgument, "invalid cursor")
+	}
+
+	pages := make(map[string]*scanPage, len(pos.Shards))
+	for name, from := range pos.Shards {
+		shard, ok := c.shards[name]
+		if !ok {
+			return nil, "", status.Errorf(codes.InvalidArgument, "cursor names unknown shard %q", name)
+		}
+
+		page, err := scanAfter(ctx, shard, prefix, limit, from, pos.After)
+		if err != nil {
+			return nil, "", err
+		}
+		pages[name] = page
+	}
+
+	var items []KeyValue
+	for limit == 0 || len(items) < limit {
+		var lowest *scanPage
+		for name, page := range pages {
+			// A shard whose page ran out may still hold keys below the
+			// other shards' next ones.
+			if len(page.items) == 0 && page.next != "" {
+				refill, err := scanAfter(ctx, c.shards[name], prefix, limit, page.next, items[len(items)-1].Key)
+				if err != nil {
+					return nil, "", err
+				}
+				*page = *refill
+			}
+
+			if len(page.items) > 0 && (lowest == nil || page.items[0].Key < lowest.items[0].Key) {
+				lowest = page
+			}
+		}
+
+		if lowest == nil {
+			break
+		}
+
+		items = append(items, lowest.items[0])
+		lowest.items = lowest.items[1:]
+	}
+
+	next := scanCursor{Shards: make(map[string]string)}
+	for name, page := range pages {
+		switch {
+		case len(page.items) > 0:
+			next.Shards[name] = page.from
+		case page.next != "":
+			next.Shards[name] = page.next
+		}
+	}
+
+	if len(next.Shards) == 0 || len(items) == 0 {
+		return items, "", nil
+	}
+
+	next.After = items[len(items)-1].Key
+
+	return items, encodeScanCursor(next), nil
+}
+
+// scanAfter reads the first page from cursor on that has keys after after,
+// dropping the keys up to it.
+func scanAfter(ctx context.Context, shard ClientInterface, prefix string, limit int, cursor, after string) (*scanPage, error) {
+	for {
+		items, next, err := shard.Scan(ctx, prefix, limit, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		k := 0
+		for k < len(items) && items[k].Key <= after {
+			k++
+		}
+
+		if k < len(items) || next == "" {
+			return &scanPage{items: items[k:], from: cursor, next: next}, nil
+		}
+
+		cursor = next
+	}
+}
+
+func encodeScanCursor(cursor scanCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeScanCursor(s string, cursor *scanCursor) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, cursor)
+}
+
+// Txn runs on the shard that owns its keys. Shards cannot commit together,
+// so a transaction whose keys span shards is refused.
+func (c *ShardedClient) Txn(ctx context.Context, txn Txn) (TxnResult, error) {
+	owners := make(map[string]bool)
+	for _, compare := range txn.Compare {
+		owners[c.ring.Owner(compare.Key)] = true
+	}
+	for _, op := range slices.Concat(txn.Success, txn.Failure) {
+		owners[c.ring.Owner(op.Key)] = true
+	}
+
+	if len(owners) > 1 {
+		return TxnResult{}, status.Error(codes.InvalidArgument, "transaction keys are on more than one shard")
+	}
+
+	name := c.ring.Shards()[0]
+	for owner := range owners {
+		name = owner
+	}
+
+	return c.shards[name].Txn(ctx, txn)
+}
+
+// Watch merges a watch on every shard. Revisions are counted per shard, so
+// replaying from a start revision only works with a single shard.
+func (c *ShardedClient) Watch(ctx context.Context, prefix string, startRevision int64) (WatchStream, error) {
+	if startRevision > 0 && len(c.shards) > 1 {
+		return nil, status.Error(codes.InvalidArgument, "start revisions are per shard, so a sharded watch cannot replay from one")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	merged := &mergedWatch{
+		events: make(chan WatchEvent),
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+
+	var streams []WatchStream
+	for _, shard := range c.shards {
+		stream, err := shard.Watch(ctx, prefix, startRevision)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		streams = append(streams, stream)
+	}
+
+	for _, stream := range streams {
+		go merged.pump(stream)
+	}
+
+	return merged, nil
+}
+
+// mergedWatch interleaves the events of several watches. The first watch
+// to end ends it, and cancels the rest.
+type mergedWatch struct {
+	events chan WatchEvent
+	done   chan struct{}
+	once   sync.Once
+	err    error
+	cancel context.CancelFunc
+}
+
+func (m *mergedWatch) pump(stream WatchStream) {
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			m.once.Do(func() {
+				m.err = err
+				close(m.done)
+				m.cancel()
+			})
+			return
+		}
+
+		select {
+		case m.events <- ev:
+		case <-m.done:
+			return
+		}
+	}
+}
+
+func (m *mergedWatch) Recv() (WatchEvent, error) {
+	select {
+	case ev := <-m.events:
+		return ev, nil
+	case <-m.done:
+		return WatchEvent{}, m.err
+	}
+}
+
+func (c *ShardedClient) BatchSet(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	return c.batch(len(items), func(n int) string { return items[n].Key },
+		func(shard ClientInterface, positions []int) ([]BatchResult, error) {
+			sub := make([]BatchItem, 0, len(positions))
+			for _, n := range positions {
+				sub = append(sub, items[n])
+			}
+			return shard.BatchSet(ctx, sub)
+		})
+}
+
+func (c *ShardedClient) BatchGet(ctx context.Context, keys []string) ([]BatchResult, error) {
+	return c.batch(len(keys), func(n int) string { return keys[n] },
+		func(shard ClientInterface, positions []int) ([]BatchResult, error) {
+			return shard.BatchGet(ctx, pick(keys, positions))
+		})
+}
+
+func (c *ShardedClient) BatchDelete(ctx context.Context, keys []string) ([]BatchResult, error) {
+	return c.batch(len(keys), func(n int) string { return keys[n] },
+		func(shard ClientInterface, positions []int) ([]BatchResult, error) {
+			return shard.BatchDelete(ctx, pick(keys, positions))
+		})
+}
+
+// batch splits a batch of size items by shard, runs the parts at the same
+// time and puts the results back in order. When a shard fails as a whole,
+// each of its items reports that error.
+func (c *ShardedClient) batch(size int, key func(int) string, run func(ClientInterface, []int) ([]BatchResult, error)) ([]BatchResult, error) {
+	groups := make(map[string][]int)
+	for n := range size {
+		owner := c.ring.Owner(key(n))
+		groups[owner] = append(groups[owner], n)
+	}
+
+	results := make([]BatchResult, size)
+	var wg sync.WaitGroup
+
+	for name, positions := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			part, err := run(c.shards[name], positions)
+			if err == nil && len(part) != len(positions) {
+				err = status.Errorf(codes.Internal, "shard %s returned %d results for %d items", name, len(part), len(positions))
+			}
+
+			for k, n := range positions {
+				if err != nil {
+					results[n] = BatchResult{Key: key(n), Err: err}
+					continue
+				}
+				results[n] = part[k]
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
+
+func pick(keys []string, positions []int) []string {
+	picked := make([]string, 0, len(positions))
+	for _, n := range positions {
+		picked = append(picked, keys[n])
+	}
+	return picked
+}
+
+type shardLoad struct {
+	items chan BatchItem
+	// indexes maps each item sent to the shard to its index in the load.
+	indexes []int64
+	result  BulkLoadResult
+	err     error
+	done    chan struct{}
+}
+
+// BulkLoad streams every shard its own items at the same time. Failures
+// are reported at their index in the whole load.
+func (c *ShardedClient) BulkLoad(ctx context.Context, next func() (BatchItem, error)) (BulkLoadResult, error) {
+	// readErr is set before the item channels are closed, so the loads
+	// see it once they run out of items.
+	var readErr error
+
+	loads := make(map[string]*shardLoad, len(c.shards))
+	for name, shard := range c.shards {
+		load := &shardLoad{items: make(chan BatchItem, 64), done: make(chan struct{})}
+		loads[name] = load
+
+		go func() {
+			defer close(load.done)
+
+			load.result, load.err = shard.BulkLoad(ctx, func() (BatchItem, error) {
+				item, ok := <-load.items
+				if !ok {
+					return BatchItem{}, cmp.Or(readErr, io.EOF)
+				}
+				return item, nil
+			})
+		}()
+	}
+
+	var failed *shardLoad
+dispatch:
+	for index := int64(0); ; index++ {
+		item, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+
+		load := loads[c.ring.Owner(item.Key)]
+		select {
+		case load.items <- item:
+			load.indexes = append(load.indexes, index)
+		case <-load.done:
+			failed = load
+			break dispatch
+		}
+	}
+
+	for _, load := range loads {
+		close(load.items)
+	}
+	for _, load := range loads {
+		<-load.done
+	}
+
+	if readErr != nil {
+		return BulkLoadResult{}, readErr
+	}
+	if failed != nil {
+		return BulkLoadResult{}, failed.err
+	}
+
+	var result BulkLoadResult
+	var errs []error
+	for _, load := range loads {
+		if load.err != nil {
+			errs = append(errs, load.err)
+			continue
+		}
+
+		result.Loaded += load.result.Loaded
+		result.Failed += load.result.Failed
+		for _, f := range load.result.Failures {
+			f.Index = load.indexes[f.Index]
+			result.Failures = append(result.Failures, f)
+		}
+	}
+
+	if len(errs) > 0 {
+		return BulkLoadResult{}, errs[0]
+	}
+
+	slices.SortFunc(result.Failures, func(a, b BulkLoadFailure) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+	if len(result.Failures) > maxBulkLoadFailures {
+		result.Failures = result.Failures[:maxBulkLoadFailures]
+	}
+
+	return result, nil
+}
+
+// Namespace scopes every shard to the namespace. Its keys are placed on
+// the ring as they would be in the default namespace.
+func (c *ShardedClient) Namespace(name string) ClientInterface {
+	shards := make(map[string]ClientInterface, len(c.shards))
+	for shardName, shard := range c.shards {
+		shards[shardName] = shard.Namespace(name)
+	}
+
+	return &ShardedClient{ring: c.ring, shards: shards}
+}
+
+func (c *ShardedClient) As(principal string) ClientInterface {
+	shards := make(map[string]ClientInterface, len(c.shards))
+	for shardName, shard := range c.shards {
+		shards[shardName] = shard.As(principal)
+	}
+
+	return &ShardedClient{ring: c.ring, shards: shards}
+}
+
+// CreateNamespace creates the namespace on every shard. It only fails with
+// AlreadyExists if every shard has it, so a create that failed part way
+// can be run again.
+func (c *ShardedClient) CreateNamespace(ctx context.Context, name string) error {
+	existing := 0
+	for _, shardName := range c.ring.Shards() {
+		err := c.shards[shardName].CreateNamespace(ctx, name)
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.AlreadyExists:
+			existing++
+		default:
+			return err
+		}
+	}
+
+	if existing == len(c.shards) {
+		return status.Error(codes.AlreadyExists, "namespace already exists")
+	}
+
+	return nil
+}
+
+// ListNamespaces merges the namespaces of every shard.
+func (c *ShardedClient) ListNamespaces(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, shard := range c.shards {
+		names, err := shard.ListNamespaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			seen[name] = true
+		}
+	}
+
+	return slices.Sorted(maps.Keys(seen)), nil
+}
+
+// DropNamespace drops the namespace from every shard that has it. It only
+// fails with NotFound if none do.
+func (c *ShardedClient) DropNamespace(ctx context.Context, name string) (int64, error) {
+	var deleted int64
+	missing := 0
+	for _, shardName := range c.ring.Shards() {
+		n, err := c.shards[shardName].DropNamespace(ctx, name)
+		switch status.Code(err) {
+		case codes.OK:
+			deleted += n
+		case codes.NotFound:
+			missing++
+		default:
+			return deleted, err
+		}
+	}
+
+	if missing == len(c.shards) {
+		return 0, status.Error(codes.NotFound, "namespace not found")
+	}
+
+	return deleted, nil
+}
+
+// Usage adds up the usage of every shard that has the namespace. Each
+// shard enforces the quotas on its own keys, so the quotas of the whole
+// cluster are those of the shards added up, apart from the value size.
+func (c *ShardedClient) Usage(ctx context.Context) (Usage, error) {
+	var total Usage
+	found := 0
+	for _, shardName := range c.ring.Shards() {
+		usage, err := c.shards[shardName].Usage(ctx)
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return Usage{}, err
+		}
+
+		if found == 0 {
+			total = usage
+		} else {
+			total.Keys += usage.Keys
+			total.Bytes += usage.Bytes
+			total.MaxKeys = addQuota(total.MaxKeys, usage.MaxKeys)
+			total.MaxBytes = addQuota(total.MaxBytes, usage.MaxBytes)
+			total.OpsPerSecond = addQuota(total.OpsPerSecond, usage.OpsPerSecond)
+			if usage.MaxValueBytes == 0 || total.MaxValueBytes == 0 {
+				total.MaxValueBytes = 0
+			} else {
+				total.MaxValueBytes = max(total.MaxValueBytes, usage.MaxValueBytes)
+			}
+		}
+		found++
+	}
+
+	if found == 0 {
+		return Usage{}, status.Error(codes.NotFound, "namespace not found")
+	}
+
+	return total, nil
+}
+
+// addQuota adds up two quotas, either of which is unlimited at zero.
+func addQuota[T int64 | float64](a, b T) T {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return a + b
+}
+
+// Health checks every shard at once, in ring order.
+func (c *ShardedClient) Health(ctx context.Context) []BackendHealth {
+	shardNames := c.ring.Shards()
+	backends := make([][]BackendHealth, len(shardNames))
+
+	var wg sync.WaitGroup
+	for n, shardName := range shardNames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			backends[n] = c.shards[shardName].Health(ctx)
+			for m := range backends[n] {
+				backends[n][m].Shard = shardName
+			}
+		}()
+	}
+	wg.Wait()
+
+	return slices.Concat(backends...)
+}
+
+func (c *ShardedClient) Close() error {
+	var errs []error
+	for _, shard := range c.shards {
+		errs = append(errs, shard.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Ensure ShardedClient implements ClientInterface
+var _ ClientInterface = (*ShardedClient)(nil)
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds the requests of operations Timeouts do not name.
+const DefaultTimeout = 5 * time.Second
+
+// operations are the names Timeouts may give a timeout to, those of the
+// RPCs the client makes.
+var operations = map[string]bool{
+	"Set": true, "CompareAndSwap": true, "Increment": true, "Get": true, "TTL": true,
+	"Scan": true, "Txn": true, "BatchSet": true, "BatchGet": true, "BatchDelete": true,
+	"BulkLoad": true, "Delete": true, "CreateNamespace": true, "ListNamespaces": true,
+	"DropNamespace": true, "Usage": true,
+}
+
+// Timeouts bound how long each operation's requests may take. They only
+// apply to requests made with a context without a deadline of its own, so
+// a deadline the caller chose, shorter or longer, wins. Watches end with
+// their context and have no timeout.
+type Timeouts struct {
+	// Default bounds the operations Ops does not name.
+	Default time.Duration
+	// Ops maps an operation, named after its RPC such as Get or
+	// DropNamespace, to its timeout. Zero means none.
+	Ops map[string]time.Duration
+}
+
+// DefaultTimeouts gives dropping a namespace, which deletes every key in
+// it, a minute and bulk loads, which run for as long as there are items,
+// no timeout.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Default: DefaultTimeout,
+		Ops: map[string]time.Duration{
+			"DropNamespace": time.Minute,
+			"BulkLoad":      0,
+		},
+	}
+}
+
+// ParseTimeouts parses comma-separated op=duration pairs, such as
+// "Get=1s,Scan=10s", over the default timeouts.
+func ParseTimeouts(s string) (Timeouts, error) {
+	timeouts := DefaultTimeouts()
+	if s == "" {
+		return timeouts, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		op, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return Timeouts{}, fmt.Errorf("invalid timeout %q, expected op=duration", pair)
+		}
+		if !operations[op] {
+			return Timeouts{}, fmt.Errorf("unknown operation %q in timeout %q", op, pair)
+		}
+
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			return Timeouts{}, fmt.Errorf("invalid duration in timeout %q", pair)
+		}
+
+		timeouts.Ops[op] = timeout
+	}
+
+	return timeouts, nil
+}
+
+// For returns the timeout of op, zero when it has none.
+func (t Timeouts) For(op string) time.Duration {
+	if timeout, ok := t.Ops[op]; ok {
+		return timeout
+	}
+	return t.Default
+}
+
+// withTimeout bounds ctx by the timeout of op, unless ctx has a deadline.
+func (t Timeouts) withTimeout(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+
+	if timeout := t.For(op); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
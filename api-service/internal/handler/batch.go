@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+const (
+	// maxBulkLoadLine caps a single line of an NDJSON bulk load.
+	maxBulkLoadLine = 1 << 20
+	// maxBulkLoadFailures caps the failures a bulk load reports.
+	maxBulkLoadFailures = 100
+)
+
+type BatchRequest struct {
+	Op    string       `json:"op"`
+	Items []SetRequest `json:"items,omitempty"`
+	Keys  []string     `json:"keys,omitempty"`
+}
+
+func (r BatchRequest) keys() []string {
+	keys := make([]string, 0, len(r.Items))
+	for _, item := range r.Items {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
+func keyAccesses(namespace string, verb rbac.Verb, keys []string) []rbac.Access {
+	accesses := make([]rbac.Access, 0, len(keys))
+	for _, key := range keys {
+		accesses = append(accesses, rbac.OnKey(namespace, verb, key))
+	}
+	return accesses
+}
+
+type BatchItemResult struct {
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	ValueBase64 []byte `json:"value_base64,omitempty"`
+	ETag        string `json:"etag,omitempty"`
+	Status      int    `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Results []BatchItemResult `json:"results"`
+}
+
+type BulkLoadFailure struct {
+	Index  int64  `json:"index"`
+	Key    string `json:"key"`
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+type BulkLoadResponse struct {
+	Loaded   int64             `json:"loaded"`
+	Failed   int64             `json:"failed"`
+	Failures []BulkLoadFailure `json:"failures"`
+}
+
+// BatchHandler runs a batch of sets, gets or deletes, answering with a
+// status per item. A body of newline-delimited SetRequests is streamed to
+// the store as a bulk load instead.
+func (h *Handler) BatchHandler(w http.ResponseWriter, r *http.Request) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-ndjson" {
+		h.bulkLoad(w, r)
+		return
+	}
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var results []client.BatchResult
+	var err error
+	okStatus := http.StatusOK
+
+	switch req.Op {
+	case "set":
+		items := make([]client.BatchItem, 0, len(req.Items))
+		for _, item := range req.Items {
+			items = append(items, client.BatchItem{
+				Key:   item.Key,
+				Value: item.value(),
+				TTL:   time.Duration(item.TTLSeconds) * time.Second,
+			})
+		}
+
+		slog.InfoContext(r.Context(), "REST API: Setting keys", "keys", len(items))
+		if !h.authorize(w, r, keyAccesses(namespaceOf(r), rbac.Write, req.keys())...) {
+			return
+		}
+		results, err = h.keyspace(r).BatchSet(r.Context(), items)
+		okStatus = http.StatusCreated
+	case "get":
+		slog.InfoContext(r.Context(), "REST API: Getting keys", "keys", len(req.Keys))
+		if !h.authorize(w, r, keyAccesses(namespaceOf(r), rbac.Read, req.Keys)...) {
+			return
+		}
+		results, err = h.keyspace(r).BatchGet(r.Context(), req.Keys)
+	case "delete":
+		slog.InfoContext(r.Context(), "REST API: Deleting keys", "keys", len(req.Keys))
+		if !h.authorize(w, r, keyAccesses(namespaceOf(r), rbac.Delete, req.Keys)...) {
+			return
+		}
+		results, err = h.keyspace(r).BatchDelete(r.Context(), req.Keys)
+	default:
+		h.respondError(w, http.StatusBadRequest, "op must be set, get or delete")
+		return
+	}
+
+	if err != nil {
+		h.handleGRPCError(w, err)
+		return
+	}
+
+	resp := BatchResponse{
+		Results: make([]BatchItemResult, 0, len(results)),
+	}
+	for _, result := range results {
+		item := BatchItemResult{Key: result.Key, Status: okStatus}
+
+		if result.Err != nil {
+			st := status.Convert(result.Err)
+			item.Status = httpStatus(st.Code())
+			item.Error = st.Message()
+		} else if req.Op != "delete" {
+			item.Value, item.ValueBase64 = splitValue(result.Value)
+			item.ETag = formatETag(result.Version)
+		}
+
+		resp.Results = append(resp.Results, item)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) bulkLoad(w http.ResponseWriter, r *http.Request) {
+	slog.InfoContext(r.Context(), "REST API: Bulk loading keys")
+
+	rc := http.NewResponseController(w)
+	// A load of any size takes well past the server's read and write
+	// timeouts to upload.
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBulkLoadLine)
+
+	// Lines that are not a valid SetRequest are reported like any other
+	// failed item rather than aborting the load.
+	invalid := []BulkLoadFailure{}
+	var invalidCount int64
+	var line int64
+	// sentLines maps the index of each item sent to its line.
+	var sentLines []int64
+
+	next := func() (client.BatchItem, error) {
+		for scanner.Scan() {
+			index := line
+			line++
+
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+
+			var item SetRequest
+			if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+				invalidCount++
+				if len(invalid) < maxBulkLoadFailures {
+					invalid = append(invalid, BulkLoadFailure{Index: index, Status: http.StatusBadRequest, Error: "Invalid JSON"})
+				}
+				continue
+			}
+
+			// Items the principal may not write are reported like invalid
+			// lines, since the load cannot be turned down as a whole once
+			// it has started.
+			if h.policy != nil {
+				if err := h.policy.Authorize(principal(r), rbac.OnKey(namespaceOf(r), rbac.Write, item.Key)); err != nil {
+					invalidCount++
+					if len(invalid) < maxBulkLoadFailures {
+						invalid = append(invalid, BulkLoadFailure{Index: index, Key: item.Key, Status: http.StatusForbidden, Error: err.Error()})
+					}
+					continue
+				}
+			}
+
+			sentLines = append(sentLines, index)
+			return client.BatchItem{
+				Key:   item.Key,
+				Value: item.value(),
+				TTL:   time.Duration(item.TTLSeconds) * time.Second,
+			}, nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return client.BatchItem{}, err
+		}
+
+		return client.BatchItem{}, io.EOF
+	}
+
+	result, err := h.keyspace(r).BulkLoad(r.Context(), next)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			h.handleGRPCError(w, err)
+			return
+		}
+
+		h.respondError(w, http.StatusBadRequest, "Failed to read request body: "+err.Error())
+		return
+	}
+
+	resp := BulkLoadResponse{
+		Loaded:   result.Loaded,
+		Failed:   result.Failed + invalidCount,
+		Failures: invalid,
+	}
+	for _, f := range result.Failures {
+		st := status.Convert(f.Err)
+		resp.Failures = append(resp.Failures, BulkLoadFailure{
+			Index:  sentLines[f.Index],
+			Key:    f.Key,
+			Status: httpStatus(st.Code()),
+			Error:  st.Message(),
+		})
+	}
+
+	slices.SortFunc(resp.Failures, func(a, b BulkLoadFailure) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+	if len(resp.Failures) > maxBulkLoadFailures {
+		resp.Failures = resp.Failures[:maxBulkLoadFailures]
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
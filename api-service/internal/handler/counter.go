@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/schemas/logging"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+type IncrRequest struct {
+	// Delta defaults to 1. Negative deltas decrement.
+	Delta        *int64 `json:"delta,omitempty"`
+	InitialValue int64  `json:"initial_value,omitempty"`
+	Min          *int64 `json:"min,omitempty"`
+	Max          *int64 `json:"max,omitempty"`
+}
+
+type IncrResponse struct {
+	Key   string `json:"key"`
+	Value int64  `json:"value"`
+}
+
+// IncrHandler atomically adds to the integer a key holds. The body is
+// optional; without one the key is incremented by 1.
+func (h *Handler) IncrHandler(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+
+	if key == "" {
+		h.respondError(w, http.StatusBadRequest, "Key cannot be empty")
+		return
+	}
+
+	var req IncrRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	delta := int64(1)
+	if req.Delta != nil {
+		delta = *req.Delta
+	}
+
+	if req.Min != nil && req.Max != nil && *req.Min > *req.Max {
+		h.respondError(w, http.StatusBadRequest, "min cannot be greater than max")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "REST API: Incrementing key", logging.Key("key", key), "delta", delta)
+
+	if !h.authorize(w, r, rbac.OnKey(namespaceOf(r), rbac.Write, key)) {
+		return
+	}
+
+	value, version, err := h.keyspace(r).Increment(r.Context(), key, delta, client.CounterOptions{
+		Initial: req.InitialValue,
+		Min:     req.Min,
+		Max:     req.Max,
+	})
+	if err != nil {
+		// Out of range here means the counter would overflow or leave its
+		// bounds, which is a conflict with the key's current value.
+		if st, ok := status.FromError(err); ok && st.Code() == codes.OutOfRange {
+			h.respondError(w, http.StatusConflict, st.Message())
+			return
+		}
+
+		h.handleGRPCError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(version))
+	h.respondJSON(w, http.StatusOK, IncrResponse{
+		Key:   key,
+		Value: value,
+	})
+}
@@ -2,18 +2,33 @@ package handler
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"mime"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/schemas/logging"
+	"GRPC-KV-Store-System/schemas/rbac"
 )
 
 type Handler struct {
 	grpcClient client.ClientInterface
+	// policy is the RBAC policy requests are checked against, or nil to
+	// allow every request.
+	policy *rbac.Enforcer
 }
 
 func StartHandler(grpcClient client.ClientInterface) *Handler {
@@ -22,14 +37,52 @@ func StartHandler(grpcClient client.ClientInterface) *Handler {
 	}
 }
 
+// SetPolicy checks requests against an RBAC policy from now on.
+func (h *Handler) SetPolicy(policy *rbac.Enforcer) {
+	h.policy = policy
+}
+
+// quotaReason is the ErrorInfo reason the KV store gives requests over a
+// namespace's quota.
+const quotaReason = "QUOTA_EXCEEDED"
+
+// maxValueBytes caps a raw value sent to PUT /kv/{key}, keeping the
+// request under gRPC's default 4MB message limit.
+const maxValueBytes = 4<<20 - 64<<10
+
 type SetRequest struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+	// ValueBase64 is stored instead of Value when set, for values that
+	// are not valid UTF-8.
+	ValueBase64 []byte `json:"value_base64,omitempty"`
+	TTLSeconds  int64  `json:"ttl_seconds,omitempty"`
+}
+
+func (r SetRequest) value() string {
+	return pickValue(r.Value, r.ValueBase64)
+}
+
+// pickValue returns the decoded value_base64 of a request when it is set,
+// and its value otherwise.
+func pickValue(value string, valueBase64 []byte) string {
+	if len(valueBase64) > 0 {
+		return string(valueBase64)
+	}
+	return value
 }
 
+// GetResponse carries values that are not valid UTF-8, which JSON strings
+// cannot hold, base64-encoded in ValueBase64 with Value left empty.
 type GetResponse struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	ValueBase64 []byte `json:"value_base64,omitempty"`
+}
+
+type ListResponse struct {
+	Items      []GetResponse `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
 }
 
 type ErrorResponse struct {
@@ -52,17 +105,122 @@ func (h *Handler) SetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("REST API: Setting key=%s", req.Key)
+	h.storeValue(w, r, req.Key, req.value(), req.TTLSeconds)
+}
+
+// PutHandler stores the body under the key in the path. A JSON body is a
+// SetRequest without the key; an application/octet-stream body is the raw
+// value, with its TTL in the ttl_seconds query parameter.
+func (h *Handler) PutHandler(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+
+	if key == "" {
+		h.respondError(w, http.StatusBadRequest, "Key cannot be empty")
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/octet-stream" {
+		var ttlSeconds int64
+		if raw := r.URL.Query().Get("ttl_seconds"); raw != "" {
+			parsed, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				h.respondError(w, http.StatusBadRequest, "ttl_seconds must be an integer")
+				return
+			}
+			ttlSeconds = parsed
+		}
+
+		value, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxValueBytes))
+		if err != nil {
+			h.respondError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Value cannot be larger than %d bytes", maxValueBytes))
+			return
+		}
+
+		h.storeValue(w, r, key, string(value), ttlSeconds)
+		return
+	}
+
+	var req SetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Key != "" && req.Key != key {
+		h.respondError(w, http.StatusBadRequest, "Key in the body does not match the path")
+		return
+	}
+
+	h.storeValue(w, r, key, req.value(), req.TTLSeconds)
+}
+
+// storeValue writes value under key, only if the key is at the version in
+// an If-Match header when there is one.
+func (h *Handler) storeValue(w http.ResponseWriter, r *http.Request, key, value string, ttlSeconds int64) {
+	if ttlSeconds < 0 {
+		h.respondError(w, http.StatusBadRequest, "ttl_seconds cannot be negative")
+		return
+	}
+
+	if !h.authorize(w, r, rbac.OnKey(namespaceOf(r), rbac.Write, key)) {
+		return
+	}
+
+	ttl := time.Duration(ttlSeconds) * time.Second
+
+	var version int64
+	var err error
+	if ifMatch := r.Header.Values("If-Match"); len(ifMatch) > 0 {
+		slog.InfoContext(r.Context(), "REST API: Setting key", logging.Key("key", key), "if_match", strings.Join(ifMatch, ","))
+		version, err = h.compareAndSwap(r, key, parseIfMatch(ifMatch), value, ttl)
+	} else if ttl > 0 {
+		slog.InfoContext(r.Context(), "REST API: Setting key", logging.Key("key", key))
+		version, err = h.keyspace(r).SetWithTTL(r.Context(), key, value, ttl)
+	} else {
+		slog.InfoContext(r.Context(), "REST API: Setting key", logging.Key("key", key))
+		version, err = h.keyspace(r).Set(r.Context(), key, value)
+	}
 
-	err := h.grpcClient.Set(req.Key, req.Value)
 	if err != nil {
 		h.handleGRPCError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(version))
 	h.respondSuccess(w, http.StatusCreated, "Key-value pair stored successfully")
 }
 
+// maxSwapAttempts bounds how often compareAndSwap reads a key again after
+// it changed between the read and the swap.
+const maxSwapAttempts = 3
+
+// compareAndSwap writes value under key if it matches condition. A single
+// ETag is left to the store to compare; for * or a list, the current
+// version is read and swapped against, again if the key changes meanwhile.
+func (h *Handler) compareAndSwap(r *http.Request, key string, condition ifMatch, value string, ttl time.Duration) (int64, error) {
+	if !condition.any && len(condition.versions) == 1 {
+		return h.keyspace(r).CompareAndSwap(r.Context(), key, condition.versions[0], value, ttl)
+	}
+
+	for attempt := 1; ; attempt++ {
+		_, current, err := h.keyspace(r).GetWithVersion(r.Context(), key)
+		if status.Code(err) == codes.NotFound {
+			current = 0
+		} else if err != nil {
+			return 0, err
+		}
+
+		if !condition.matches(current) {
+			return 0, status.Error(codes.FailedPrecondition, "key does not match the expected version")
+		}
+
+		version, err := h.keyspace(r).CompareAndSwap(r.Context(), key, current, value, ttl)
+		if status.Code(err) != codes.FailedPrecondition || attempt == maxSwapAttempts {
+			return version, err
+		}
+	}
+}
+
 func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	key := vars["key"]
@@ -72,18 +230,78 @@ func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("REST API: Getting key=%s", key)
+	slog.InfoContext(r.Context(), "REST API: Getting key", logging.Key("key", key))
+
+	if !h.authorize(w, r, rbac.OnKey(namespaceOf(r), rbac.Read, key)) {
+		return
+	}
 
-	value, err := h.grpcClient.Get(key)
+	value, version, err := h.keyspace(r).GetWithVersion(r.Context(), key)
 	if err != nil {
 		h.handleGRPCError(w, err)
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, GetResponse{
-		Key:   key,
-		Value: value,
-	})
+	etag := formatETag(version)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Vary", "Accept")
+
+	if ifMatch := r.Header.Values("If-Match"); len(ifMatch) > 0 && !parseIfMatch(ifMatch).matches(version) {
+		h.respondError(w, http.StatusPreconditionFailed, "key does not match the expected version")
+		return
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if prefersOctetStream(r.Header.Get("Accept")) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, value)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, jsonValue(key, value))
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	cursor := query.Get("cursor")
+
+	limit := 100
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			h.respondError(w, http.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+		limit = parsed
+	}
+
+	slog.InfoContext(r.Context(), "REST API: Listing keys", logging.Key("prefix", prefix), "limit", limit)
+
+	if !h.authorize(w, r, rbac.OnPrefix(namespaceOf(r), rbac.Read, prefix)) {
+		return
+	}
+
+	items, next, err := h.keyspace(r).Scan(r.Context(), prefix, limit, cursor)
+	if err != nil {
+		h.handleGRPCError(w, err)
+		return
+	}
+
+	resp := ListResponse{
+		Items:      make([]GetResponse, 0, len(items)),
+		NextCursor: next,
+	}
+	for _, item := range items {
+		resp.Items = append(resp.Items, jsonValue(item.Key, item.Value))
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
 }
 
 func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
@@ -95,9 +313,13 @@ func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("REST API: Deleting key=%s", key)
+	slog.InfoContext(r.Context(), "REST API: Deleting key", logging.Key("key", key))
+
+	if !h.authorize(w, r, rbac.OnKey(namespaceOf(r), rbac.Delete, key)) {
+		return
+	}
 
-	err := h.grpcClient.Delete(key)
+	err := h.keyspace(r).Delete(r.Context(), key)
 	if err != nil {
 		h.handleGRPCError(w, err)
 		return
@@ -133,15 +355,184 @@ func (h *Handler) handleGRPCError(w http.ResponseWriter, err error) {
 		return
 	}
 
-	var httpStatus int
-	switch st.Code() {
+	if retry, ok := quotaExceeded(st); ok {
+		if retry > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+		}
+		h.respondError(w, http.StatusTooManyRequests, st.Message())
+		return
+	}
+
+	h.respondError(w, httpStatus(st.Code()), st.Message())
+}
+
+// quotaExceeded reports whether st turned a request down for going over
+// its namespace's quota, rather than the store running out of memory, and
+// how long the store asked to wait before retrying.
+func quotaExceeded(st *status.Status) (time.Duration, bool) {
+	if st.Code() != codes.ResourceExhausted {
+		return 0, false
+	}
+
+	var exceeded bool
+	var retry time.Duration
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			exceeded = exceeded || d.Reason == quotaReason
+		case *errdetails.RetryInfo:
+			retry = d.RetryDelay.AsDuration()
+		}
+	}
+
+	return retry, exceeded
+}
+
+// statusClientClosedRequest is nginx's status for requests the client gave
+// up on before they were answered. The client never sees it, but metrics
+// and logs tell those requests apart from failures.
+const statusClientClosedRequest = 499
+
+// httpStatus maps a gRPC status code from the KV store to an HTTP status.
+func httpStatus(code codes.Code) int {
+	switch code {
 	case codes.NotFound:
-		httpStatus = http.StatusNotFound
+		return http.StatusNotFound
 	case codes.InvalidArgument:
-		httpStatus = http.StatusBadRequest
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.OutOfRange:
+		return http.StatusGone
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.ResourceExhausted:
+		return http.StatusInsufficientStorage
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Canceled:
+		return statusClientClosedRequest
 	default:
-		httpStatus = http.StatusInternalServerError
+		return http.StatusInternalServerError
+	}
+}
+
+// jsonValue builds the JSON form of a key's value.
+func jsonValue(key, value string) GetResponse {
+	resp := GetResponse{Key: key}
+	resp.Value, resp.ValueBase64 = splitValue(value)
+	return resp
+}
+
+// splitValue returns value as a JSON string when it is valid UTF-8, and as
+// bytes to be base64-encoded otherwise.
+func splitValue(value string) (string, []byte) {
+	if utf8.ValidString(value) {
+		return value, nil
+	}
+	return "", []byte(value)
+}
+
+// prefersOctetStream reports whether an Accept header ranks raw bytes
+// above JSON. JSON wins ties unless octet-stream is named more exactly, so
+// clients that send no Accept header, or */*, keep getting JSON.
+func prefersOctetStream(accept string) bool {
+	octetQ, octetSpecificity := mediaQuality(accept, "application/octet-stream")
+	jsonQ, jsonSpecificity := mediaQuality(accept, "application/json")
+
+	if octetQ != jsonQ {
+		return octetQ > jsonQ
+	}
+	return octetQ > 0 && octetSpecificity > jsonSpecificity
+}
+
+// mediaQuality returns the q-value an Accept header gives mediaType, from
+// the most specific media range matching it, and that range's specificity.
+func mediaQuality(accept, mediaType string) (float64, int) {
+	quality, specificity := 0.0, -1
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		var s int
+		switch {
+		case mediaRange == mediaType:
+			s = 2
+		case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+			s = 1
+		case mediaRange == "*/*":
+			s = 0
+		default:
+			continue
+		}
+
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+
+		if s > specificity || (s == specificity && q > quality) {
+			quality, specificity = q, s
+		}
+	}
+
+	return quality, specificity
+}
+
+// formatETag renders a key version as a strong entity tag.
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ifMatch is an If-Match header: any current version, or one of a list.
+// Version 0 stands for a key that does not exist.
+type ifMatch struct {
+	any      bool
+	versions []int64
+}
+
+// parseIfMatch reads the comma-separated ETags of the If-Match header
+// lines. If-Match compares strongly, so weak ETags, and anything else this
+// API did not hand out, never match.
+func parseIfMatch(lines []string) ifMatch {
+	var condition ifMatch
+	for _, line := range lines {
+		for _, etag := range strings.Split(line, ",") {
+			etag = strings.TrimSpace(etag)
+			if etag == "*" {
+				condition.any = true
+			} else if version, ok := parseETag(etag); ok {
+				condition.versions = append(condition.versions, version)
+			}
+		}
+	}
+
+	return condition
+}
+
+// matches reports whether a key at version, 0 when it does not exist,
+// meets the condition.
+func (m ifMatch) matches(version int64) bool {
+	return (m.any && version != 0) || slices.Contains(m.versions, version)
+}
+
+func parseETag(etag string) (int64, bool) {
+	unquoted, err := strconv.Unquote(etag)
+	if err != nil {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 0 {
+		return 0, false
 	}
 
-	h.respondError(w, httpStatus, st.Message())
+	return version, true
 }
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+)
+
+type BackendStatus struct {
+	Shard      string `json:"shard,omitempty"`
+	Addr       string `json:"addr"`
+	Connection string `json:"connection"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status   string          `json:"status"`
+	Backends []BackendStatus `json:"backends,omitempty"`
+}
+
+// LivezHandler reports the process is up. It never calls the backends, so
+// an outage of theirs does not get the api-service restarted.
+func (h *Handler) LivezHandler(w http.ResponseWriter, r *http.Request) {
+	h.respondJSON(w, http.StatusOK, map[string]string{
+		"status": "alive",
+	})
+}
+
+// ReadyzHandler checks the health of every backend and answers 503 unless
+// all of them are serving, as requests for the keys of any one that is not
+// would fail. Callers without credentials only get the status, as the
+// backends' addresses and errors are not theirs to see.
+func (h *Handler) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	resp := ReadinessResponse{Status: "ready"}
+	statusCode := http.StatusOK
+
+	for _, backend := range h.grpcClient.Health(r.Context()) {
+		backendStatus := BackendStatus{
+			Shard:      backend.Shard,
+			Addr:       backend.Addr,
+			Connection: backend.Connection,
+			Status:     backend.Status,
+		}
+		if backend.Err != nil {
+			backendStatus.Error = backend.Err.Error()
+		}
+
+		if !backend.Serving() {
+			slog.WarnContext(r.Context(), "REST API: Backend is not ready", "backend", backend.Addr, "status", backend.Status, "connection", backend.Connection)
+			resp.Status = "not ready"
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		resp.Backends = append(resp.Backends, backendStatus)
+	}
+
+	if middleware.Anonymous(r.Context()) {
+		resp.Backends = nil
+	}
+
+	h.respondJSON(w, statusCode, resp)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+type NamespacesResponse struct {
+	Namespaces []string `json:"namespaces"`
+}
+
+type DropNamespaceResponse struct {
+	Message     string `json:"message"`
+	DeletedKeys int64  `json:"deleted_keys"`
+}
+
+type UsageResponse struct {
+	Namespace     string  `json:"namespace"`
+	KeyCount      int64   `json:"key_count"`
+	Bytes         int64   `json:"bytes"`
+	MaxKeys       int64   `json:"max_keys"`
+	MaxBytes      int64   `json:"max_bytes"`
+	MaxValueBytes int64   `json:"max_value_bytes"`
+	OpsPerSecond  float64 `json:"ops_per_second"`
+}
+
+// namespaceOf returns the namespace a request's path names, "" for the
+// default namespace outside /ns/{namespace}.
+func namespaceOf(r *http.Request) string {
+	return mux.Vars(r)["namespace"]
+}
+
+// keyspace returns the client for the namespace a request's path names,
+// or for the default namespace outside /ns/{namespace}.
+func (h *Handler) keyspace(r *http.Request) client.ClientInterface {
+	namespace := namespaceOf(r)
+	trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("kv.namespace", namespace))
+
+	return h.grpcClient.Namespace(namespace).As(principal(r))
+}
+
+func (h *Handler) ListNamespacesHandler(w http.ResponseWriter, r *http.Request) {
+	slog.InfoContext(r.Context(), "REST API: Listing namespaces")
+
+	if !h.authorize(w, r, rbac.Access{Verb: rbac.Admin}) {
+		return
+	}
+
+	names, err := h.caller(r).ListNamespaces(r.Context())
+	if err != nil {
+		h.handleGRPCError(w, err)
+		return
+	}
+
+	if names == nil {
+		names = []string{}
+	}
+
+	h.respondJSON(w, http.StatusOK, NamespacesResponse{Namespaces: names})
+}
+
+func (h *Handler) CreateNamespaceHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["namespace"]
+
+	slog.InfoContext(r.Context(), "REST API: Creating namespace", "namespace", name)
+
+	if !h.authorize(w, r, rbac.Access{Verb: rbac.Admin}) {
+		return
+	}
+
+	if err := h.caller(r).CreateNamespace(r.Context(), name); err != nil {
+		h.handleGRPCError(w, err)
+		return
+	}
+
+	h.respondSuccess(w, http.StatusCreated, "Namespace created successfully")
+}
+
+func (h *Handler) DropNamespaceHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["namespace"]
+
+	slog.InfoContext(r.Context(), "REST API: Dropping namespace", "namespace", name)
+
+	if !h.authorize(w, r, rbac.Access{Verb: rbac.Admin}) {
+		return
+	}
+
+	deleted, err := h.caller(r).DropNamespace(r.Context(), name)
+	if err != nil {
+		h.handleGRPCError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, DropNamespaceResponse{
+		Message:     "Namespace dropped successfully",
+		DeletedKeys: deleted,
+	})
+}
+
+func (h *Handler) UsageHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["namespace"]
+
+	slog.InfoContext(r.Context(), "REST API: Getting usage of namespace", "namespace", name)
+
+	if !h.authorize(w, r, rbac.Access{Namespace: name, Verb: rbac.Admin}) {
+		return
+	}
+
+	usage, err := h.keyspace(r).Usage(r.Context())
+	if err != nil {
+		h.handleGRPCError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, UsageResponse{
+		Namespace:     name,
+		KeyCount:      usage.Keys,
+		Bytes:         usage.Bytes,
+		MaxKeys:       usage.MaxKeys,
+		MaxBytes:      usage.MaxBytes,
+		MaxValueBytes: usage.MaxValueBytes,
+		OpsPerSecond:  usage.OpsPerSecond,
+	})
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+// principal returns the name of who made a request, or empty when the
+// API does not authenticate requests.
+func principal(r *http.Request) string {
+	p, _ := middleware.PrincipalFrom(r.Context())
+	return p.Name
+}
+
+// caller returns the client to make requests outside any namespace with,
+// on behalf of the request's principal.
+func (h *Handler) caller(r *http.Request) client.ClientInterface {
+	return h.grpcClient.As(principal(r))
+}
+
+// authorize checks the request's principal may make every one of
+// accesses, answering 403 with the reason when it may not.
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, accesses ...rbac.Access) bool {
+	if h.policy == nil {
+		return true
+	}
+
+	if err := h.policy.Authorize(principal(r), accesses...); err != nil {
+		h.respondError(w, http.StatusForbidden, err.Error())
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+type TxnCompare struct {
+	Key         string `json:"key"`
+	Target      string `json:"target"`
+	Result      string `json:"result,omitempty"`
+	Value       string `json:"value,omitempty"`
+	ValueBase64 []byte `json:"value_base64,omitempty"`
+	Version     int64  `json:"version,omitempty"`
+	Exists      bool   `json:"exists,omitempty"`
+}
+
+type TxnOp struct {
+	Op          string `json:"op"`
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	ValueBase64 []byte `json:"value_base64,omitempty"`
+	TTLSeconds  int64  `json:"ttl_seconds,omitempty"`
+}
+
+type TxnRequest struct {
+	Compare []TxnCompare `json:"compare"`
+	Success []TxnOp      `json:"success"`
+	Failure []TxnOp      `json:"failure"`
+}
+
+type TxnOpResult struct {
+	Op          string `json:"op"`
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	ValueBase64 []byte `json:"value_base64,omitempty"`
+	Version     int64  `json:"version,omitempty"`
+	Found       bool   `json:"found"`
+}
+
+type TxnResponse struct {
+	Succeeded bool          `json:"succeeded"`
+	Revision  int64         `json:"revision"`
+	Results   []TxnOpResult `json:"results"`
+}
+
+var (
+	compareTargets = map[string]client.CompareTarget{
+		"value":   client.CompareValue,
+		"version": client.CompareVersion,
+		"exists":  client.CompareExists,
+	}
+
+	compareResults = map[string]client.CompareResult{
+		"":          client.Equal,
+		"equal":     client.Equal,
+		"not_equal": client.NotEqual,
+		"greater":   client.Greater,
+		"less":      client.Less,
+	}
+
+	txnOpTypes = map[string]client.TxnOpType{
+		"get":    client.TxnGet,
+		"put":    client.TxnPut,
+		"delete": client.TxnDelete,
+	}
+
+	txnVerbs = map[client.TxnOpType]rbac.Verb{
+		client.TxnGet:    rbac.Read,
+		client.TxnPut:    rbac.Write,
+		client.TxnDelete: rbac.Delete,
+	}
+)
+
+// TxnHandler runs a transaction. It answers 200 whether or not the compares
+// held; succeeded in the body tells which branch ran.
+func (h *Handler) TxnHandler(w http.ResponseWriter, r *http.Request) {
+	var req TxnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	txn := client.Txn{
+		Compare: make([]client.Compare, 0, len(req.Compare)),
+	}
+
+	for _, c := range req.Compare {
+		target, ok := compareTargets[c.Target]
+		if !ok {
+			h.respondError(w, http.StatusBadRequest, "compare target must be value, version or exists")
+			return
+		}
+
+		result, ok := compareResults[c.Result]
+		if !ok {
+			h.respondError(w, http.StatusBadRequest, "compare result must be equal, not_equal, greater or less")
+			return
+		}
+
+		txn.Compare = append(txn.Compare, client.Compare{
+			Key:     c.Key,
+			Target:  target,
+			Result:  result,
+			Value:   pickValue(c.Value, c.ValueBase64),
+			Version: c.Version,
+			Exists:  c.Exists,
+		})
+	}
+
+	var ok bool
+	if txn.Success, ok = toTxnOps(req.Success); !ok {
+		h.respondError(w, http.StatusBadRequest, "op must be get, put or delete")
+		return
+	}
+	if txn.Failure, ok = toTxnOps(req.Failure); !ok {
+		h.respondError(w, http.StatusBadRequest, "op must be get, put or delete")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "REST API: Running transaction", "compares", len(txn.Compare), "success", len(txn.Success), "failure", len(txn.Failure))
+
+	if !h.authorize(w, r, txnAccesses(namespaceOf(r), txn)...) {
+		return
+	}
+
+	result, err := h.keyspace(r).Txn(r.Context(), txn)
+	if err != nil {
+		h.handleGRPCError(w, err)
+		return
+	}
+
+	ops := req.Success
+	if !result.Succeeded {
+		ops = req.Failure
+	}
+
+	resp := TxnResponse{
+		Succeeded: result.Succeeded,
+		Revision:  result.Revision,
+		Results:   make([]TxnOpResult, 0, len(result.Results)),
+	}
+	for n, r := range result.Results {
+		opResult := TxnOpResult{
+			Op:      ops[n].Op,
+			Key:     r.Key,
+			Version: r.Version,
+			Found:   r.Found,
+		}
+		opResult.Value, opResult.ValueBase64 = splitValue(r.Value)
+		resp.Results = append(resp.Results, opResult)
+	}
+
+	h.respondJSON(w, http.StatusOK, resp)
+}
+
+// txnAccesses returns what either branch of a transaction may touch, since
+// which one runs is not known up front.
+func txnAccesses(namespace string, txn client.Txn) []rbac.Access {
+	var accesses []rbac.Access
+	for _, c := range txn.Compare {
+		accesses = append(accesses, rbac.OnKey(namespace, rbac.Read, c.Key))
+	}
+	for _, op := range slices.Concat(txn.Success, txn.Failure) {
+		accesses = append(accesses, rbac.OnKey(namespace, txnVerbs[op.Type], op.Key))
+	}
+	return accesses
+}
+
+func toTxnOps(ops []TxnOp) ([]client.TxnOp, bool) {
+	converted := make([]client.TxnOp, 0, len(ops))
+	for _, op := range ops {
+		opType, ok := txnOpTypes[op.Op]
+		if !ok {
+			return nil, false
+		}
+
+		converted = append(converted, client.TxnOp{
+			Type:  opType,
+			Key:   op.Key,
+			Value: pickValue(op.Value, op.ValueBase64),
+			TTL:   time.Duration(op.TTLSeconds) * time.Second,
+		})
+	}
+	return converted, true
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/schemas/logging"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+type WatchEvent struct {
+	Type        string `json:"type"`
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	ValueBase64 []byte `json:"value_base64,omitempty"`
+	Revision    int64  `json:"revision"`
+}
+
+// WatchHandler streams changes to keys starting with prefix as server-sent
+// events. Each event's id is its revision, so a reconnecting EventSource
+// resumes where it left off through Last-Event-ID.
+func (h *Handler) WatchHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+
+	var startRevision int64
+	if raw := query.Get("start_revision"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed < 0 {
+			h.respondError(w, http.StatusBadRequest, "start_revision must be a non-negative integer")
+			return
+		}
+		startRevision = parsed
+	} else if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || parsed < 0 {
+			h.respondError(w, http.StatusBadRequest, "Last-Event-ID must be an event id sent by this API")
+			return
+		}
+		startRevision = parsed + 1
+	}
+
+	slog.InfoContext(r.Context(), "REST API: Watching", logging.Key("prefix", prefix), "start_revision", startRevision)
+
+	if !h.authorize(w, r, rbac.OnPrefix(namespaceOf(r), rbac.Read, prefix)) {
+		return
+	}
+
+	stream, err := h.keyspace(r).Watch(r.Context(), prefix, startRevision)
+	if err != nil {
+		h.handleGRPCError(w, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// A watch runs for as long as the client keeps it open, well past the
+	// server's write timeout.
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			if r.Context().Err() != nil {
+				return
+			}
+
+			data, _ := json.Marshal(ErrorResponse{Error: status.Convert(err).Message()})
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+			rc.Flush()
+			return
+		}
+
+		event := WatchEvent{
+			Type:     string(ev.Type),
+			Key:      ev.Key,
+			Revision: ev.Revision,
+		}
+		event.Value, event.ValueBase64 = splitValue(ev.Value)
+
+		data, _ := json.Marshal(event)
+		fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Revision, ev.Type, data)
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
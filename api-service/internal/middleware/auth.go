@@ -0,0 +1,445 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew is how far token expiry and not-before times are stretched to
+// allow for clocks that disagree.
+const clockSkew = 30 * time.Second
+
+// Principal is who a request was authenticated as.
+type Principal struct {
+	Name string
+	// AllNamespaces allows every namespace, and creating, listing and
+	// dropping them. Credentials that do not list namespaces get it.
+	AllNamespaces bool
+	// Namespaces the principal may use otherwise, "" being the default
+	// namespace. An empty list allows none.
+	Namespaces []string
+}
+
+// newPrincipal is the principal of credentials listing namespaces, which
+// is nil when they list none.
+func newPrincipal(name string, namespaces *[]string) Principal {
+	if namespaces == nil {
+		return Principal{Name: name, AllNamespaces: true}
+	}
+	return Principal{Name: name, Namespaces: *namespaces}
+}
+
+// Unrestricted reports whether p may use every namespace.
+func (p Principal) Unrestricted() bool {
+	return p.AllNamespaces
+}
+
+func (p Principal) allows(namespace string) bool {
+	return p.Unrestricted() || slices.Contains(p.Namespaces, namespace)
+}
+
+type principalKey struct{}
+
+// PrincipalFrom returns the principal an authenticated request was made
+// as.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+type anonymousKey struct{}
+
+// Anonymous reports whether a request to a public path came without valid
+// credentials, so should be told no more than anyone may know.
+func Anonymous(ctx context.Context) bool {
+	anonymous, _ := ctx.Value(anonymousKey{}).(bool)
+	return anonymous
+}
+
+type AuthOptions struct {
+	// APIKeysFile is a JSON file of the keys accepted in the X-API-Key
+	// header.
+	APIKeysFile string
+	// JWKSFile is a JSON Web Key Set of the keys bearer tokens may be
+	// signed with: oct keys for HS256 and RSA keys for RS256.
+	JWKSFile string
+	// Issuer and Audience, when set, must match the iss and aud claims of
+	// bearer tokens.
+	Issuer   string
+	Audience string
+	// Public lists the paths served without credentials.
+	Public []string
+}
+
+// apiKeysFile is the format of AuthOptions.APIKeysFile. Each key is given
+// either as is or as the hex SHA-256 of the key, so the file need not hold
+// the secret itself.
+type apiKeysFile struct {
+	Keys []struct {
+		Name       string    `json:"name"`
+		Key        string    `json:"key"`
+		SHA256     string    `json:"sha256"`
+		Namespaces *[]string `json:"namespaces"`
+	} `json:"keys"`
+}
+
+// jwk is a JSON Web Key, of which only oct and RSA keys are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// verifier checks the signatures of one key in a JWKS.
+type verifier struct {
+	kid    string
+	alg    string
+	secret []byte
+	public *rsa.PublicKey
+}
+
+func (v verifier) verify(signed, signature []byte) bool {
+	switch v.alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case "RS256":
+		digest := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(v.public, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
+
+// AuthMiddleware turns away requests without a valid API key or bearer
+// token, and requests for namespaces their principal may not use.
+type AuthMiddleware struct {
+	apiKeys   map[[sha256.Size]byte]Principal
+	verifiers []verifier
+	issuer    string
+	audience  string
+	public    map[string]bool
+	now       func() time.Time
+}
+
+func StartAuth(opts AuthOptions) (*AuthMiddleware, error) {
+	m := &AuthMiddleware{
+		apiKeys:  make(map[[sha256.Size]byte]Principal),
+		issuer:   opts.Issuer,
+		audience: opts.Audience,
+		public:   make(map[string]bool),
+		now:      time.Now,
+	}
+
+	for _, path := range opts.Public {
+		m.public[path] = true
+	}
+
+	if opts.APIKeysFile != "" {
+		if err := m.loadAPIKeys(opts.APIKeysFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.JWKSFile != "" {
+		if err := m.loadJWKS(opts.JWKSFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(m.apiKeys) == 0 && len(m.verifiers) == 0 {
+		return nil, errors.New("no API keys or token signing keys were loaded")
+	}
+
+	slog.Info("Authenticating requests", "api_keys", len(m.apiKeys), "token_signing_keys", len(m.verifiers))
+
+	return m, nil
+}
+
+func (m *AuthMiddleware) loadAPIKeys(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file apiKeysFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("invalid API keys file %s: %w", path, err)
+	}
+
+	for n, key := range file.Keys {
+		if key.Name == "" {
+			return fmt.Errorf("invalid API keys file %s: key %d has no name", path, n)
+		}
+
+		var hash [sha256.Size]byte
+		switch {
+		case key.Key != "" && key.SHA256 == "":
+			hash = sha256.Sum256([]byte(key.Key))
+		case key.SHA256 != "" && key.Key == "":
+			decoded, err := hex.DecodeString(key.SHA256)
+			if err != nil || len(decoded) != sha256.Size {
+				return fmt.Errorf("invalid API keys file %s: sha256 of key %s is not a hex SHA-256", path, key.Name)
+			}
+			copy(hash[:], decoded)
+		default:
+			return fmt.Errorf("invalid API keys file %s: key %s needs exactly one of key or sha256", path, key.Name)
+		}
+
+		m.apiKeys[hash] = newPrincipal(key.Name, key.Namespaces)
+	}
+
+	return nil
+}
+
+func (m *AuthMiddleware) loadJWKS(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("invalid JWKS file %s: %w", path, err)
+	}
+
+	for n, key := range set.Keys {
+		v, err := key.verifier()
+		if err != nil {
+			return fmt.Errorf("invalid JWKS file %s: key %d: %w", path, n, err)
+		}
+		m.verifiers = append(m.verifiers, v)
+	}
+
+	return nil
+}
+
+func (k jwk) verifier() (verifier, error) {
+	switch k.Kty {
+	case "oct":
+		if k.Alg != "" && k.Alg != "HS256" {
+			return verifier{}, fmt.Errorf("unsupported alg %q for an oct key", k.Alg)
+		}
+
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return verifier{}, errors.New("k is not a base64url secret")
+		}
+		return verifier{kid: k.Kid, alg: "HS256", secret: secret}, nil
+
+	case "RSA":
+		if k.Alg != "" && k.Alg != "RS256" {
+			return verifier{}, fmt.Errorf("unsupported alg %q for an RSA key", k.Alg)
+		}
+
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return verifier{}, errors.New("n and e are not a base64url RSA public key")
+		}
+
+		public := &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+		return verifier{kid: k.Kid, alg: "RS256", public: public}, nil
+	}
+
+	return verifier{}, fmt.Errorf("unsupported kty %q", k.Kty)
+}
+
+func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.public[r.URL.Path] {
+			// Public paths are served either way; credentials only decide
+			// how much they show.
+			ctx := context.WithValue(r.Context(), anonymousKey{}, true)
+			if principal, err := m.authenticate(r); err == nil {
+				ctx = context.WithValue(r.Context(), principalKey{}, principal)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		principal, err := m.authenticate(r)
+		if err != nil {
+			slog.WarnContext(r.Context(), "Authentication failed", "error", err)
+
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kv-store"`)
+			respondAuthError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if namespace, admin := requestNamespace(r.URL.Path); (admin && !principal.Unrestricted()) || !principal.allows(namespace) {
+			slog.WarnContext(r.Context(), "Forbidden", "principal", principal.Name, "namespace", namespace, "admin", admin)
+
+			respondAuthError(w, http.StatusForbidden, fmt.Sprintf("%s may not access %s", principal.Name, r.URL.Path))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	})
+}
+
+func (m *AuthMiddleware) authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		principal, ok := m.apiKeys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return Principal{}, errors.New("invalid API key")
+		}
+		return principal, nil
+	}
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		principal, err := m.verifyToken(strings.TrimSpace(token))
+		if err != nil {
+			return Principal{}, fmt.Errorf("invalid bearer token: %w", err)
+		}
+		return principal, nil
+	}
+
+	return Principal{}, errors.New("missing credentials, send an X-API-Key header or an Authorization: Bearer token")
+}
+
+// audience is the aud claim, which may be a single string or a list.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+type tokenClaims struct {
+	Subject    string    `json:"sub"`
+	Issuer     string    `json:"iss"`
+	Audience   audience  `json:"aud"`
+	ExpiresAt  *float64  `json:"exp"`
+	NotBefore  *float64  `json:"nbf"`
+	Namespaces *[]string `json:"namespaces"`
+}
+
+// verifyToken checks a compact JWT signed with HS256 or RS256. Tokens must
+// expire; their sub claim names the principal and an optional namespaces
+// claim restricts it.
+func (m *AuthMiddleware) verifyToken(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, errors.New("not a JWT")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, fmt.Errorf("bad header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, errors.New("bad signature encoding")
+	}
+
+	// The key, not the token, decides the algorithm, so a token cannot
+	// pass an RSA public key off as an HMAC secret.
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, v := range m.verifiers {
+		if v.alg != header.Alg || (header.Kid != "" && v.kid != header.Kid) {
+			continue
+		}
+		if v.verify(signed, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return Principal{}, fmt.Errorf("signature does not match any %s key", header.Alg)
+	}
+
+	var claims tokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, fmt.Errorf("bad claims: %w", err)
+	}
+
+	now := m.now()
+	switch {
+	case claims.Subject == "":
+		return Principal{}, errors.New("missing sub claim")
+	case claims.ExpiresAt == nil:
+		return Principal{}, errors.New("missing exp claim")
+	case now.Add(-clockSkew).After(unixTime(*claims.ExpiresAt)):
+		return Principal{}, errors.New("token has expired")
+	case claims.NotBefore != nil && now.Add(clockSkew).Before(unixTime(*claims.NotBefore)):
+		return Principal{}, errors.New("token is not valid yet")
+	case m.issuer != "" && claims.Issuer != m.issuer:
+		return Principal{}, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	case m.audience != "" && !slices.Contains(claims.Audience, m.audience):
+		return Principal{}, errors.New("token is not meant for this audience")
+	}
+
+	return newPrincipal(claims.Subject, claims.Namespaces), nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// requestNamespace returns the namespace a request path is for, and
+// whether it is one of the admin routes that create, list or drop
+// namespaces.
+func requestNamespace(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "/ns")
+	if !ok || (rest != "" && rest[0] != '/') {
+		return "", false
+	}
+
+	name, sub, _ := strings.Cut(strings.TrimPrefix(rest, "/"), "/")
+	return name, name == "" || sub == ""
+}
+
+func respondAuthError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
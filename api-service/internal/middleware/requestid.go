@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"GRPC-KV-Store-System/schemas/logging"
+)
+
+// RequestID tags each request's context, and so every record logged for
+// it here and in the KV store, with the ID in its X-Request-ID header, or
+// a new one when it has none or one too long or unprintable to keep. The
+// ID is echoed in the response's X-Request-ID header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(logging.RequestIDHeader)
+		if !logging.ValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+
+		w.Header().Set(logging.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
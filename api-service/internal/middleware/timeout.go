@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// RequestTimeoutHeader is the header a caller gives the time it is willing
+// to wait for a request in, as a Go duration such as 500ms or 2s.
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// TimeoutMiddleware puts the deadline a request's X-Request-Timeout header
+// asks for on its context, which the KV store is called with, so its work
+// is abandoned once the caller stops waiting. Timeouts over max are cut
+// down to it. Requests without the header keep the client's per-operation
+// timeouts.
+type TimeoutMiddleware struct {
+	max time.Duration
+}
+
+func StartTimeout(max time.Duration) *TimeoutMiddleware {
+	return &TimeoutMiddleware{max: max}
+}
+
+func (m *TimeoutMiddleware) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(RequestTimeoutHeader)
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		timeout, err := time.ParseDuration(header)
+		if err != nil || timeout <= 0 {
+			slog.InfoContext(r.Context(), "Invalid request timeout", "timeout", header)
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{
+				"error": RequestTimeoutHeader + " must be a positive duration, such as 500ms or 2s",
+			})
+			return
+		}
+
+		if m.max > 0 && timeout > m.max {
+			timeout = m.max
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
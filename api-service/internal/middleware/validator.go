@@ -1,17 +1,26 @@
 package middleware
 
 import (
-	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
+	"mime"
 	"net/http"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/getkin/kin-openapi/routers"
 	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// streamedBodies are the media types of bodies handlers read themselves,
+// as they arrive or up to a limit. Validating them would read them into
+// memory first, whatever their size.
+var streamedBodies = map[string]bool{
+	"application/x-ndjson":     true,
+	"application/octet-stream": true,
+}
+
 type ValidationMiddleware struct {
 	router routers.Router
 }
@@ -28,12 +37,21 @@ func StartValidator(specPath string) (*ValidationMiddleware, error) {
 		return nil, err
 	}
 
+	// Credentials are checked by AuthMiddleware, when enabled. Checking the
+	// security requirements here as well reads every body into memory.
+	doc.Security = nil
+	for _, path := range doc.Paths.Map() {
+		for _, operation := range path.Operations() {
+			operation.Security = nil
+		}
+	}
+
 	router, err := gorillamux.NewRouter(doc)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Println("OpenAPI spec loaded and validated successfully")
+	slog.Info("OpenAPI spec loaded and validated successfully")
 
 	return &ValidationMiddleware{
 		router: router,
@@ -49,14 +67,27 @@ func (m *ValidationMiddleware) Validate(next http.Handler) http.Handler {
 			return
 		}
 
+		ctx, span := tracer.Start(r.Context(), "validate request")
+
 		requestValidationInput := &openapi3filter.RequestValidationInput{
 			Request:    r,
 			PathParams: pathParams,
 			Route:      route,
+			Options:    &openapi3filter.Options{},
+		}
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); streamedBodies[mediaType] {
+			requestValidationInput.Options.ExcludeRequestBody = true
 		}
 
-		if err := openapi3filter.ValidateRequest(context.Background(), requestValidationInput); err != nil {
-			log.Printf("Validation error: %v", err)
+		err = openapi3filter.ValidateRequest(ctx, requestValidationInput)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "request validation failed")
+		}
+		span.End()
+
+		if err != nil {
+			slog.InfoContext(ctx, "Validation error", "error", err)
 
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusBadRequest)
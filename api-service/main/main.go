@@ -1,76 +1,222 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 
 	"GRPC-KV-Store-System/api-service/internal/client"
 	"GRPC-KV-Store-System/api-service/internal/handler"
 	"GRPC-KV-Store-System/api-service/internal/middleware"
+	"GRPC-KV-Store-System/schemas/certs"
+	"GRPC-KV-Store-System/schemas/logging"
+	"GRPC-KV-Store-System/schemas/rbac"
+	"GRPC-KV-Store-System/schemas/tracing"
 )
 
 var (
 	port           = flag.String("port", "8080", "HTTP server port")
 	grpcServerAddr = flag.String("grpc-addr", "localhost:50051", "gRPC server address")
+	shards         = flag.String("shards", "", "Comma-separated name=address kvStore-service backends to shard keys across. Overrides --grpc-addr")
+	virtualNodes   = flag.Int("vnodes", client.DefaultVirtualNodes, "Points each shard gets on the hash ring")
 	specPath       = flag.String("spec", "../schemas/rest/openapi.yaml", "OpenAPI spec path")
+
+	apiKeysFile = flag.String("api-keys-file", "", "JSON file of the API keys accepted in the X-API-Key header")
+	jwksFile    = flag.String("jwks-file", "", "JSON Web Key Set of the HS256 (oct) and RS256 (RSA) keys bearer tokens may be signed with")
+	jwtIssuer   = flag.String("jwt-issuer", "", "Issuer bearer tokens must name in their iss claim. Not checked when empty")
+	jwtAudience = flag.String("jwt-audience", "", "Audience bearer tokens must name in their aud claim. Not checked when empty")
+
+	rbacPolicy = flag.String("rbac-policy", "", "JSON file of roles on key prefixes and the principals bound to them, reloaded on SIGHUP. No access control when empty")
+	rbacDryRun = flag.Bool("rbac-dry-run", false, "Log why requests would be denied by --rbac-policy instead of denying them")
+
+	tlsCert              = flag.String("tls-cert", "", "PEM certificate to serve HTTPS with, reloaded when the file changes. Plain HTTP when empty")
+	tlsKey               = flag.String("tls-key", "", "PEM key of --tls-cert")
+	tlsCA                = flag.String("tls-ca", "", "PEM bundle of the CAs REST client certificates are verified with")
+	tlsRequireClientCert = flag.Bool("tls-require-client-cert", false, "Turn down REST clients without a certificate signed by one of --tls-ca's CAs")
+
+	grpcTLS     = flag.Bool("grpc-tls", false, "Connect to kvStore-service over TLS. Implied by --grpc-tls-ca and --grpc-tls-cert")
+	grpcTLSCA   = flag.String("grpc-tls-ca", "", "PEM bundle of the CAs kvStore-service certificates are verified with. The system roots when empty")
+	grpcTLSCert = flag.String("grpc-tls-cert", "", "PEM client certificate to present to kvStore-service, for mutual TLS. Reloaded when the file changes")
+	grpcTLSKey  = flag.String("grpc-tls-key", "", "PEM key of --grpc-tls-cert")
+
+	traceExporter    = flag.String("trace-exporter", "none", "Where to send OpenTelemetry spans: none, otlp, stdout or file")
+	traceEndpoint    = flag.String("trace-endpoint", "", "OTLP collector host:port. OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317 when empty")
+	traceInsecure    = flag.Bool("trace-insecure", false, "Send spans to the OTLP collector in plaintext")
+	traceFile        = flag.String("trace-file", "", "File the file exporter appends spans to as JSON")
+	traceSampleRatio = flag.Float64("trace-sample-ratio", 1, "Fraction of new traces to record. Requests with a traceparent header follow the caller's decision")
+
+	timeouts          = flag.String("timeouts", "", "Comma-separated op=duration timeouts of KV store operations, such as Get=1s,Scan=10s, over the defaults of 5s, DropNamespace=1m and BulkLoad=0 (none)")
+	maxRequestTimeout = flag.Duration("max-request-timeout", 10*time.Second, "Most a client may ask for in the X-Request-Timeout header, under the 15s the server takes to write a response. Longer timeouts are cut down to it")
+
+	logLevel          = flag.String("log-level", "info", "Least severe level logged: debug, info, warn or error")
+	logFormat         = flag.String("log-format", "text", "Format of log records: text or json")
+	logValues         = flag.String("log-values", "hash", "How values are logged: hash (a short SHA-256 and the length), omit or plain")
+	logRedactPrefixes = flag.String("log-redact-prefixes", strings.Join(logging.DefaultSensitivePrefixes, ","), "Comma-separated key prefixes, matched ignoring case, of keys logged like values")
+
+	healthCheck = flag.String("health-check", "", "GET this URL, such as http://localhost:8080/readyz, and exit with 1 unless it answers 200, for container health checks")
 )
 
 func main() {
 	flag.Parse()
 
+	if err := setupLogging(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *healthCheck != "" {
+		if err := checkHealth(*healthCheck); err != nil {
+			logging.Fatal("Unhealthy", "error", err)
+		}
+		slog.Info("Healthy")
+		return
+	}
+
 	if addr := os.Getenv("GRPC_SERVER_ENDPOINT"); addr != "" {
 		*grpcServerAddr = addr
 	}
 
-	log.Println("Starting REST API server...")
+	if list := os.Getenv("GRPC_SHARDS"); list != "" {
+		*shards = list
+	}
+
+	slog.Info("Starting REST API server...")
 
-	grpcClient, err := client.StartClient(*grpcServerAddr)
+	tracerProvider, err := tracing.StartProvider(tracing.Options{
+		ServiceName: "api-service",
+		Exporter:    *traceExporter,
+		Endpoint:    *traceEndpoint,
+		Insecure:    *traceInsecure,
+		File:        *traceFile,
+		SampleRatio: *traceSampleRatio,
+	})
 	if err != nil {
-		log.Fatalf("Failed to create gRPC client: %v", err)
+		logging.Fatal("Failed to set up tracing", "error", err)
+	}
+
+	defer shutdownTracing(tracerProvider)
+
+	grpcClient, err := startClient()
+	if err != nil {
+		logging.Fatal("Failed to create gRPC client", "error", err)
 	}
 
 	defer grpcClient.Close()
 
 	validator, err := middleware.StartValidator(*specPath)
 	if err != nil {
-		log.Fatalf("Failed to load OpenAPI spec: %v", err)
+		logging.Fatal("Failed to load OpenAPI spec", "error", err)
 	}
 
 	h := handler.StartHandler(grpcClient)
 
+	if *rbacPolicy != "" {
+		enforcer, err := rbac.LoadEnforcer(*rbacPolicy, *rbacDryRun)
+		if err != nil {
+			logging.Fatal("Failed to load RBAC policy", "error", err)
+		}
+		enforcer.ReloadOnSignal(syscall.SIGHUP)
+
+		h.SetPolicy(enforcer)
+	}
+
 	router := mux.NewRouter()
 
 	router.HandleFunc("/health", h.HealthHandler).Methods("GET")
-	router.HandleFunc("/kv", h.SetHandler).Methods("POST")
-	router.HandleFunc("/kv/{key}", h.GetHandler).Methods("GET")
-	router.HandleFunc("/kv/{key}", h.DeleteHandler).Methods("DELETE")
+	router.HandleFunc("/livez", h.LivezHandler).Methods("GET")
+	router.HandleFunc("/readyz", h.ReadyzHandler).Methods("GET")
+	router.HandleFunc("/ns", h.ListNamespacesHandler).Methods("GET")
+	router.HandleFunc("/ns/{namespace}", h.CreateNamespaceHandler).Methods("PUT")
+	router.HandleFunc("/ns/{namespace}", h.DropNamespaceHandler).Methods("DELETE")
+
+	// The key routes serve the default namespace at the root and every
+	// other one under /ns/{namespace}.
+	keyRoutes(router, h)
+	keyRoutes(router.PathPrefix("/ns/{namespace}").Subrouter(), h)
 
 	router.HandleFunc("/openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, *specPath)
 	}).Methods("GET")
 
-	validatedRouter := validator.Validate(router)
+	metrics := middleware.StartMetrics(router)
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+
+	var apiHandler http.Handler = validator.Validate(router)
+	apiHandler = middleware.StartTimeout(*maxRequestTimeout).Limit(apiHandler)
+
+	// Without API keys or token signing keys anyone who can reach the
+	// port may read and write every key.
+	if *apiKeysFile != "" || *jwksFile != "" {
+		auth, err := middleware.StartAuth(middleware.AuthOptions{
+			APIKeysFile: *apiKeysFile,
+			JWKSFile:    *jwksFile,
+			Issuer:      *jwtIssuer,
+			Audience:    *jwtAudience,
+			Public:      []string{"/health", "/livez", "/readyz", "/metrics", "/openapi.yaml"},
+		})
+		if err != nil {
+			logging.Fatal("Failed to set up authentication", "error", err)
+		}
+
+		apiHandler = auth.Authenticate(apiHandler)
+	} else {
+		slog.Warn("No --api-keys-file or --jwks-file given, the REST API is open to anyone")
+	}
+
+	// Requests turned down by authentication or validation are measured
+	// and traced as well, and every record logged for a request carries
+	// its ID.
+	apiHandler = metrics.Measure(apiHandler)
+	apiHandler = middleware.StartTracing(router).Trace(apiHandler)
+	apiHandler = middleware.RequestID(apiHandler)
 
 	srv := &http.Server{
 		Addr:         ":" + *port,
-		Handler:      validatedRouter,
+		Handler:      apiHandler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	scheme := "http"
+	if *tlsCert != "" {
+		srv.TLSConfig, err = certs.ServerConfig(certs.Options{
+			CertFile:          *tlsCert,
+			KeyFile:           *tlsKey,
+			CAFile:            *tlsCA,
+			RequireClientCert: *tlsRequireClientCert,
+		})
+		if err != nil {
+			logging.Fatal("Failed to set up TLS", "error", err)
+		}
+		scheme = "https"
+	} else if *tlsKey != "" || *tlsCA != "" || *tlsRequireClientCert {
+		logging.Fatal("--tls-key, --tls-ca and --tls-require-client-cert need --tls-cert")
+	}
+
 	go func() {
-		log.Printf("REST API server listening on port %s", *port)
-		log.Printf("OpenAPI spec available at http://localhost:%s/openapi.yaml", *port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+		slog.Info("REST API server listening", "port", *port, "scheme", scheme)
+		slog.Info("OpenAPI spec available", "url", scheme+"://localhost:"+*port+"/openapi.yaml")
+
+		var err error
+		if srv.TLSConfig != nil {
+			// The certificate comes from TLSConfig, which reloads it.
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logging.Fatal("Failed to start server", "error", err)
 		}
 	}()
 
@@ -78,5 +224,107 @@ func main() {
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down REST API server...")
+	slog.Info("Shutting down REST API server...")
+}
+
+func setupLogging() error {
+	var prefixes []string
+	for _, prefix := range strings.Split(*logRedactPrefixes, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	return logging.Setup(os.Stderr, logging.Options{
+		Level:  *logLevel,
+		Format: *logFormat,
+		Redaction: logging.Redaction{
+			Values:            *logValues,
+			SensitivePrefixes: prefixes,
+		},
+	})
+}
+
+// shutdownTracing sends the spans still buffered before the process exits.
+func shutdownTracing(provider *tracing.Provider) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := provider.Shutdown(ctx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+}
+
+// checkHealth fails unless url answers 200. It stands in for wget or curl
+// in images without either.
+func checkHealth(url string) error {
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %s", url, resp.Status)
+	}
+
+	return nil
+}
+
+func keyRoutes(router *mux.Router, h *handler.Handler) {
+	router.HandleFunc("/kv", h.SetHandler).Methods("POST")
+	router.HandleFunc("/kv", h.ListHandler).Methods("GET")
+	router.HandleFunc("/kv/batch", h.BatchHandler).Methods("POST")
+	router.HandleFunc("/kv/{key}", h.GetHandler).Methods("GET")
+	router.HandleFunc("/kv/{key}", h.PutHandler).Methods("PUT")
+	router.HandleFunc("/kv/{key}", h.DeleteHandler).Methods("DELETE")
+	router.HandleFunc("/kv/{key}/incr", h.IncrHandler).Methods("POST")
+	router.HandleFunc("/txn", h.TxnHandler).Methods("POST")
+	router.HandleFunc("/watch", h.WatchHandler).Methods("GET")
+	router.HandleFunc("/usage", h.UsageHandler).Methods("GET")
+}
+
+func startClient() (client.ClientInterface, error) {
+	opTimeouts, err := client.ParseTimeouts(*timeouts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --timeouts: %w", err)
+	}
+
+	var opts []grpc.DialOption
+	if *grpcTLS || *grpcTLSCA != "" || *grpcTLSCert != "" {
+		withTLS, err := client.WithTLS(certs.Options{
+			CertFile: *grpcTLSCert,
+			KeyFile:  *grpcTLSKey,
+			CAFile:   *grpcTLSCA,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up TLS: %w", err)
+		}
+		opts = append(opts, withTLS)
+	}
+
+	if *shards == "" {
+		c, err := client.StartClient(*grpcServerAddr, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		c.SetTimeouts(opTimeouts)
+		return c, nil
+	}
+
+	backends, err := client.ParseShards(*shards)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.StartShardedClient(backends, *virtualNodes, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetTimeouts(opTimeouts)
+	return c, nil
 }
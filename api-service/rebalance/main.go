@@ -0,0 +1,117 @@
+// Command rebalance moves keys between kvStore-service shards after shards
+// are added or removed. Point the api-services at the new shards first,
+// then run it with the same list, plus any shards being removed:
+//
+//	rebalance --shards a=kv-a:50051,b=kv-b:50051,c=kv-c:50051 --drain d=kv-d:50051
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"google.golang.org/grpc"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/schemas/certs"
+	"GRPC-KV-Store-System/schemas/logging"
+)
+
+var (
+	shards       = flag.String("shards", "", "Comma-separated name=address shards keys are placed on from now on")
+	drain        = flag.String("drain", "", "Comma-separated name=address shards being removed, whose keys all move")
+	virtualNodes = flag.Int("vnodes", client.DefaultVirtualNodes, "Points each shard gets on the hash ring. Must match the api-services")
+	pageSize     = flag.Int("page-size", 500, "Keys read from a shard at a time")
+	dryRun       = flag.Bool("dry-run", false, "Count the keys that would move without moving them")
+
+	grpcTLS     = flag.Bool("grpc-tls", false, "Connect to the shards over TLS. Implied by --grpc-tls-ca and --grpc-tls-cert")
+	grpcTLSCA   = flag.String("grpc-tls-ca", "", "PEM bundle of the CAs shard certificates are verified with. The system roots when empty")
+	grpcTLSCert = flag.String("grpc-tls-cert", "", "PEM client certificate to present to the shards, for mutual TLS")
+	grpcTLSKey  = flag.String("grpc-tls-key", "", "PEM key of --grpc-tls-cert")
+
+	logLevel  = flag.String("log-level", "info", "Least severe level logged: debug, info, warn or error")
+	logFormat = flag.String("log-format", "text", "Format of log records: text or json")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := logging.Setup(os.Stderr, logging.Options{Level: *logLevel, Format: *logFormat}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	members, err := client.ParseShards(*shards)
+	if err != nil {
+		logging.Fatal("Invalid --shards", "error", err)
+	}
+
+	all := members
+	if *drain != "" {
+		drained, err := client.ParseShards(*drain)
+		if err != nil {
+			logging.Fatal("Invalid --drain", "error", err)
+		}
+		all = append(all, drained...)
+	}
+
+	var opts []grpc.DialOption
+	if *grpcTLS || *grpcTLSCA != "" || *grpcTLSCert != "" {
+		withTLS, err := client.WithTLS(certs.Options{
+			CertFile: *grpcTLSCert,
+			KeyFile:  *grpcTLSKey,
+			CAFile:   *grpcTLSCA,
+		})
+		if err != nil {
+			logging.Fatal("Failed to set up TLS", "error", err)
+		}
+		opts = append(opts, withTLS)
+	}
+
+	clients := make(map[string]client.ClientInterface, len(all))
+	for _, shard := range all {
+		if _, dup := clients[shard.Name]; dup {
+			logging.Fatal("Shard is listed twice", "shard", shard.Name)
+		}
+
+		c, err := client.StartClient(shard.Addr, opts...)
+		if err != nil {
+			logging.Fatal("Failed to connect to shard", "shard", shard.Name, "error", err)
+		}
+		defer c.Close()
+
+		clients[shard.Name] = c
+	}
+
+	names := make([]string, 0, len(members))
+	for _, shard := range members {
+		names = append(names, shard.Name)
+	}
+	ring := client.NewRing(names, *virtualNodes)
+
+	slog.Info("Rebalancing", "from", slices.Sorted(maps.Keys(clients)), "onto", ring.Shards(), "dry_run", *dryRun)
+
+	// Interrupting stops the rebalance between keys; running it again
+	// carries on.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stats, err := client.Rebalance(ctx, clients, ring, client.RebalanceOptions{
+		PageSize: *pageSize,
+		DryRun:   *dryRun,
+		Progress: func(stats client.RebalanceStats) {
+			slog.Info("Rebalance progress", "scanned", stats.Scanned, "moved", stats.Moved)
+		},
+	})
+	if err != nil {
+		logging.Fatal("Rebalance failed", "moved", stats.Moved, "error", err)
+	}
+
+	slog.Info("Rebalanced", "scanned", stats.Scanned, "moved", stats.Moved, "conflicts", stats.Conflicts)
+}
@@ -0,0 +1,258 @@
+package test
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GRPC-KV-Store-System/api-service/internal/handler"
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+)
+
+var b64 = base64.RawURLEncoding
+
+// signToken builds a JWT of claims, signed with an HS256 secret or an
+// RS256 private key.
+func signToken(t *testing.T, kid string, key any, claims map[string]any) string {
+	t.Helper()
+
+	alg := "HS256"
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		alg = "RS256"
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := b64.EncodeToString(header) + "." + b64.EncodeToString(payload)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+	}
+
+	return signed + "." + b64.EncodeToString(signature)
+}
+
+func writeJSON(t *testing.T, dir, name string, v any) string {
+	t.Helper()
+
+	data, _ := json.Marshal(v)
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestAuth(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	hashed := sha256.Sum256([]byte("hashed-key"))
+
+	keysFile := writeJSON(t, dir, "keys.json", map[string]any{"keys": []map[string]any{
+		{"name": "admin", "key": "admin-key"},
+		{"name": "team-a", "key": "team-a-key", "namespaces": []string{"team-a"}},
+		{"name": "ops", "sha256": hex.EncodeToString(hashed[:])},
+		{"name": "nobody", "key": "nobody-key", "namespaces": []string{}},
+	}})
+	jwksFile := writeJSON(t, dir, "jwks.json", map[string]any{"keys": []map[string]any{
+		{"kty": "oct", "kid": "hs", "k": b64.EncodeToString(secret)},
+		{"kty": "RSA", "kid": "rs", "n": b64.EncodeToString(rsaKey.N.Bytes()), "e": b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes())},
+	}})
+
+	auth, err := middleware.StartAuth(middleware.AuthOptions{
+		APIKeysFile: keysFile,
+		JWKSFile:    jwksFile,
+		Audience:    "kv-store",
+		Public:      []string{"/health"},
+	})
+	if err != nil {
+		t.Fatalf("StartAuth failed: %v", err)
+	}
+
+	mockClient := NewMockClient()
+	mockClient.CreateNamespace(ctx, "team-a")
+	mockClient.CreateNamespace(ctx, "team-b")
+	router := auth.Authenticate(setupRouterWith(mockClient))
+
+	do := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	apiKey := func(key string) http.Header { return http.Header{"X-Api-Key": {key}} }
+	bearer := func(token string) http.Header { return http.Header{"Authorization": {"Bearer " + token}} }
+
+	expires := time.Now().Add(time.Hour).Unix()
+
+	t.Run("Public paths need no credentials", func(t *testing.T) {
+		if rr := do("GET", "/health", nil); rr.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Try requests without valid credentials", func(t *testing.T) {
+		for name, header := range map[string]http.Header{
+			"none":            nil,
+			"unknown key":     apiKey("wrong"),
+			"malformed token": bearer("not.a.jwt"),
+			"expired token":   bearer(signToken(t, "hs", secret, map[string]any{"sub": "ci", "aud": "kv-store", "exp": time.Now().Add(-time.Hour).Unix()})),
+			"no expiry":       bearer(signToken(t, "hs", secret, map[string]any{"sub": "ci", "aud": "kv-store"})),
+			"wrong audience":  bearer(signToken(t, "hs", secret, map[string]any{"sub": "ci", "aud": "other", "exp": expires})),
+			"wrong secret":    bearer(signToken(t, "hs", []byte("guessed"), map[string]any{"sub": "ci", "aud": "kv-store", "exp": expires})),
+		} {
+			rr := do("GET", "/kv", header)
+
+			var resp handler.ErrorResponse
+			json.NewDecoder(rr.Body).Decode(&resp)
+			if rr.Code != http.StatusUnauthorized || resp.Error == "" || rr.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("%s: expected a 401 error, got %d %+v", name, rr.Code, resp)
+			}
+		}
+	})
+
+	t.Run("Try passing an RSA public key off as an HMAC secret", func(t *testing.T) {
+		// A token forged with alg HS256 and the public key as the secret
+		// must not verify against the RSA key.
+		public := rsaKey.N.Bytes()
+		token := signToken(t, "rs", public, map[string]any{"sub": "ci", "aud": "kv-store", "exp": expires})
+
+		if rr := do("GET", "/kv", bearer(token)); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", rr.Code)
+		}
+
+		none := b64.EncodeToString([]byte(`{"alg":"none"}`)) + "." + b64.EncodeToString([]byte(`{"sub":"ci"}`)) + "."
+		if rr := do("GET", "/kv", bearer(none)); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for alg none, got %d", rr.Code)
+		}
+	})
+
+	t.Run("API keys authenticate", func(t *testing.T) {
+		for _, key := range []string{"admin-key", "hashed-key"} {
+			if rr := do("GET", "/kv", apiKey(key)); rr.Code != http.StatusOK {
+				t.Errorf("%s: expected status 200, got %d", key, rr.Code)
+			}
+		}
+	})
+
+	t.Run("HS256 and RS256 tokens authenticate", func(t *testing.T) {
+		for kid, key := range map[string]any{"hs": secret, "rs": rsaKey} {
+			token := signToken(t, kid, key, map[string]any{"sub": "ci", "aud": []string{"kv-store"}, "exp": expires})
+			if rr := do("GET", "/ns", bearer(token)); rr.Code != http.StatusOK {
+				t.Errorf("%s: expected status 200, got %d: %s", kid, rr.Code, rr.Body.String())
+			}
+		}
+	})
+
+	t.Run("Try using a namespace the principal is not allowed", func(t *testing.T) {
+		if rr := do("GET", "/ns/team-a/kv", apiKey("team-a-key")); rr.Code != http.StatusOK {
+			t.Errorf("Expected status 200 in its own namespace, got %d", rr.Code)
+		}
+
+		token := signToken(t, "rs", rsaKey, map[string]any{"sub": "b", "aud": "kv-store", "exp": expires, "namespaces": []string{"team-b"}})
+		for path, header := range map[string]http.Header{
+			"/ns/team-b/kv": apiKey("team-a-key"),
+			"/kv":           apiKey("team-a-key"),
+			"/ns":           apiKey("team-a-key"),
+			"/ns/team-a/kv": bearer(token),
+		} {
+			rr := do("GET", path, header)
+
+			var resp handler.ErrorResponse
+			json.NewDecoder(rr.Body).Decode(&resp)
+			if rr.Code != http.StatusForbidden || resp.Error == "" {
+				t.Errorf("%s: expected a 403 error, got %d %+v", path, rr.Code, resp)
+			}
+		}
+
+		if rr := do("DELETE", "/ns/team-a", apiKey("team-a-key")); rr.Code != http.StatusForbidden {
+			t.Errorf("Expected dropping a namespace to need an unrestricted principal, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Try credentials that list no namespaces", func(t *testing.T) {
+		token := signToken(t, "hs", secret, map[string]any{"sub": "c", "aud": "kv-store", "exp": expires, "namespaces": []string{}})
+
+		for _, header := range []http.Header{apiKey("nobody-key"), bearer(token)} {
+			for _, path := range []string{"/kv", "/ns", "/ns/team-a/kv"} {
+				if rr := do("GET", path, header); rr.Code != http.StatusForbidden {
+					t.Errorf("%s: expected an empty list to allow no namespace, got %d", path, rr.Code)
+				}
+			}
+		}
+	})
+
+	t.Run("Validation accepts authenticated requests", func(t *testing.T) {
+		validator, err := middleware.StartValidator("../../schemas/rest/openapi.yaml")
+		if err != nil {
+			t.Fatalf("StartValidator failed: %v", err)
+		}
+		validated := auth.Authenticate(validator.Validate(setupRouterWith(mockClient)))
+
+		req := httptest.NewRequest("POST", "http://localhost:8080/kv", strings.NewReader(`{"key":"a","value":"1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "admin-key")
+		rr := httptest.NewRecorder()
+
+		validated.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+}
+
+func TestStartAuth(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, file := range map[string]any{
+		"no keys":        map[string]any{"keys": []any{}},
+		"unnamed key":    map[string]any{"keys": []map[string]any{{"key": "k"}}},
+		"key and sha256": map[string]any{"keys": []map[string]any{{"name": "n", "key": "k", "sha256": "00"}}},
+		"bad sha256":     map[string]any{"keys": []map[string]any{{"name": "n", "sha256": "xyz"}}},
+	} {
+		path := writeJSON(t, dir, "keys.json", file)
+		if _, err := middleware.StartAuth(middleware.AuthOptions{APIKeysFile: path}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	path := writeJSON(t, dir, "jwks.json", map[string]any{"keys": []map[string]any{{"kty": "EC", "crv": "P-256"}}})
+	if _, err := middleware.StartAuth(middleware.AuthOptions{JWKSFile: path}); err == nil {
+		t.Error("Expected unsupported key types to be rejected")
+	}
+}
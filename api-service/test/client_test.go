@@ -2,27 +2,57 @@ package test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
 
+	"GRPC-KV-Store-System/api-service/internal/client"
 	"GRPC-KV-Store-System/api-service/internal/handler"
 )
 
 func setupRouter() *mux.Router {
-	mockClient := NewMockClient()
-	h := handler.StartHandler(mockClient)
+	return setupRouterWith(NewMockClient())
+}
 
+func setupRouterWith(mockClient *MockClient) *mux.Router {
+	return routes(handler.StartHandler(mockClient))
+}
+
+func routes(h *handler.Handler) *mux.Router {
 	router := mux.NewRouter()
 	router.HandleFunc("/health", h.HealthHandler).Methods("GET")
+	router.HandleFunc("/livez", h.LivezHandler).Methods("GET")
+	router.HandleFunc("/readyz", h.ReadyzHandler).Methods("GET")
+	router.HandleFunc("/ns", h.ListNamespacesHandler).Methods("GET")
+	router.HandleFunc("/ns/{namespace}", h.CreateNamespaceHandler).Methods("PUT")
+	router.HandleFunc("/ns/{namespace}", h.DropNamespaceHandler).Methods("DELETE")
+
+	// The key routes serve the default namespace at the root and every
+	// other one under /ns/{namespace}.
+	keyRoutes(router, h)
+	keyRoutes(router.PathPrefix("/ns/{namespace}").Subrouter(), h)
+
+	return router
+}
+
+func keyRoutes(router *mux.Router, h *handler.Handler) {
 	router.HandleFunc("/kv", h.SetHandler).Methods("POST")
+	router.HandleFunc("/kv", h.ListHandler).Methods("GET")
+	router.HandleFunc("/kv/batch", h.BatchHandler).Methods("POST")
 	router.HandleFunc("/kv/{key}", h.GetHandler).Methods("GET")
+	router.HandleFunc("/kv/{key}", h.PutHandler).Methods("PUT")
 	router.HandleFunc("/kv/{key}", h.DeleteHandler).Methods("DELETE")
-
-	return router
+	router.HandleFunc("/kv/{key}/incr", h.IncrHandler).Methods("POST")
+	router.HandleFunc("/txn", h.TxnHandler).Methods("POST")
+	router.HandleFunc("/watch", h.WatchHandler).Methods("GET")
+	router.HandleFunc("/usage", h.UsageHandler).Methods("GET")
 }
 
 func TestHealthEndpoint(t *testing.T) {
@@ -245,3 +275,756 @@ func TestMultipleOperations(t *testing.T) {
 
 	t.Log("Multiple operations successful")
 }
+
+func TestSetWithTTL(t *testing.T) {
+	router := setupRouter()
+
+	t.Run("Set Key-Value with ttl", func(t *testing.T) {
+		body := []byte(`{"key":"session","value":"token","ttl_seconds":60}`)
+		req := httptest.NewRequest("POST", "/kv", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Get Key-Value before expiry", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/kv/session", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Negative ttl rejected", func(t *testing.T) {
+		body := []byte(`{"key":"session","value":"token","ttl_seconds":-1}`)
+		req := httptest.NewRequest("POST", "/kv", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rr.Code)
+		}
+	})
+}
+
+func TestConditionalRequests(t *testing.T) {
+	router := setupRouter()
+
+	body := []byte(`{"key":"config","value":"v1"}`)
+	req := httptest.NewRequest("POST", "/kv", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag on set")
+	}
+
+	t.Run("Get returns the ETag", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/kv/config", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Header().Get("ETag") != etag {
+			t.Errorf("Expected ETag %s, got %s", etag, rr.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("Get with matching If-None-Match", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/kv/config", nil)
+		req.Header.Set("If-None-Match", etag)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotModified {
+			t.Errorf("Expected status 304, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Set with matching If-Match", func(t *testing.T) {
+		body := []byte(`{"key":"config","value":"v2"}`)
+		req := httptest.NewRequest("POST", "/kv", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etag)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d", rr.Code)
+		}
+		if rr.Header().Get("ETag") == etag {
+			t.Error("Expected a new ETag after the write")
+		}
+	})
+
+	t.Run("Set with stale If-Match", func(t *testing.T) {
+		body := []byte(`{"key":"config","value":"v3"}`)
+		req := httptest.NewRequest("POST", "/kv", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", etag)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusPreconditionFailed {
+			t.Errorf("Expected status 412, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Get with stale If-Match", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/kv/config", nil)
+		req.Header.Set("If-Match", etag)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusPreconditionFailed {
+			t.Errorf("Expected status 412, got %d", rr.Code)
+		}
+	})
+
+	set := func(key, ifMatch string) *httptest.ResponseRecorder {
+		body := []byte(`{"key":"` + key + `","value":"v"}`)
+		req := httptest.NewRequest("POST", "/kv", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Set with If-Match lists", func(t *testing.T) {
+		current := set("config", "*").Header().Get("ETag")
+		if current == "" {
+			t.Fatal("Expected If-Match: * to match an existing key")
+		}
+
+		rr := set("config", `"999", W/`+current+`, `+current)
+		if rr.Code != http.StatusCreated {
+			t.Errorf("Expected a list naming the current ETag to match, got %d", rr.Code)
+		}
+
+		req := httptest.NewRequest("GET", "/kv/config", nil)
+		req.Header.Set("If-Match", `"999", `+rr.Header().Get("ETag"))
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected Get with a matching list to answer 200, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Try If-Match that cannot match", func(t *testing.T) {
+		current := set("config", "*").Header().Get("ETag")
+
+		for _, tc := range []struct{ name, key, ifMatch string }{
+			{"* on a missing key", "missing", "*"},
+			{"weak ETag", "config", "W/" + current},
+			{"malformed ETag", "config", "not-an-etag"},
+		} {
+			if rr := set(tc.key, tc.ifMatch); rr.Code != http.StatusPreconditionFailed {
+				t.Errorf("%s: expected status 412, got %d", tc.name, rr.Code)
+			}
+		}
+	})
+}
+
+func TestListKeys(t *testing.T) {
+	router := setupRouter()
+
+	for _, key := range []string{"user:2", "user:1", "user:3", "order:1"} {
+		body := []byte(`{"key":"` + key + `","value":"v"}`)
+		req := httptest.NewRequest("POST", "/kv", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var cursor string
+
+	t.Run("List first page by prefix", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/kv?prefix=user:&limit=2", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rr.Code)
+		}
+
+		var resp handler.ListResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+
+		if len(resp.Items) != 2 || resp.Items[0].Key != "user:1" || resp.Items[1].Key != "user:2" {
+			t.Errorf("Expected user:1 and user:2, got %v", resp.Items)
+		}
+		if resp.NextCursor == "" {
+			t.Fatal("Expected a next_cursor")
+		}
+		cursor = resp.NextCursor
+	})
+
+	t.Run("List next page with the cursor", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/kv?prefix=user:&limit=2&cursor="+url.QueryEscape(cursor), nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		var resp handler.ListResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+
+		if len(resp.Items) != 1 || resp.Items[0].Key != "user:3" || resp.NextCursor != "" {
+			t.Errorf("Expected only user:3 on the last page, got %v (cursor %q)", resp.Items, resp.NextCursor)
+		}
+	})
+
+	t.Run("Invalid limit rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/kv?limit=0", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rr.Code)
+		}
+	})
+}
+
+func TestTxn(t *testing.T) {
+	router := setupRouter()
+
+	for _, kv := range [][2]string{{"alice", "100"}, {"bob", "0"}} {
+		body := []byte(`{"key":"` + kv[0] + `","value":"` + kv[1] + `"}`)
+		req := httptest.NewRequest("POST", "/kv", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	runTxn := func(t *testing.T, body string) (int, handler.TxnResponse) {
+		req := httptest.NewRequest("POST", "/txn", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		var resp handler.TxnResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		return rr.Code, resp
+	}
+
+	transfer := `{
+		"compare": [{"key": "alice", "target": "value", "result": "equal", "value": "100"}],
+		"success": [{"op": "put", "key": "alice", "value": "70"}, {"op": "put", "key": "bob", "value": "30"}],
+		"failure": [{"op": "get", "key": "alice"}]
+	}`
+
+	t.Run("Transaction takes the success branch", func(t *testing.T) {
+		code, resp := runTxn(t, transfer)
+		if code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", code)
+		}
+
+		if !resp.Succeeded || len(resp.Results) != 2 || resp.Results[1].Op != "put" || resp.Results[1].Key != "bob" {
+			t.Errorf("Unexpected response %+v", resp)
+		}
+	})
+
+	t.Run("Transaction takes the failure branch", func(t *testing.T) {
+		code, resp := runTxn(t, transfer)
+		if code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", code)
+		}
+
+		if resp.Succeeded || len(resp.Results) != 1 || resp.Results[0].Value != "70" {
+			t.Errorf("Unexpected response %+v", resp)
+		}
+	})
+
+	t.Run("Unknown op rejected", func(t *testing.T) {
+		code, _ := runTxn(t, `{"success": [{"op": "increment", "key": "alice"}]}`)
+		if code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", code)
+		}
+	})
+}
+
+func TestWatch(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := NewMockClient()
+	h := handler.StartHandler(mockClient)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/watch", h.WatchHandler).Methods("GET")
+
+	mockClient.Set(ctx, "config/a", "1")
+	mockClient.Set(ctx, "other", "ignored")
+	mockClient.Delete(ctx, "config/a")
+
+	t.Run("Replay events as server-sent events", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/watch?prefix=config/&start_revision=1", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rr.Code)
+		}
+
+		if contentType := rr.Header().Get("Content-Type"); contentType != "text/event-stream" {
+			t.Errorf("Expected text/event-stream, got %s", contentType)
+		}
+
+		body := rr.Body.String()
+		want := "id: 1\nevent: put\ndata: {\"type\":\"put\",\"key\":\"config/a\",\"value\":\"1\",\"revision\":1}\n\n" +
+			"id: 3\nevent: delete\ndata: {\"type\":\"delete\",\"key\":\"config/a\",\"revision\":3}\n\n" +
+			"event: error\n"
+		if !strings.HasPrefix(body, want) {
+			t.Errorf("Unexpected stream:\n%s", body)
+		}
+	})
+
+	t.Run("Resume after Last-Event-ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/watch?prefix=config/", nil)
+		req.Header.Set("Last-Event-ID", "1")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if !strings.HasPrefix(rr.Body.String(), "id: 3\nevent: delete\n") {
+			t.Errorf("Expected to resume at revision 3, got:\n%s", rr.Body.String())
+		}
+	})
+
+	t.Run("Compacted start revision", func(t *testing.T) {
+		mockClient.compact(2)
+
+		req := httptest.NewRequest("GET", "/watch?start_revision=1", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusGone {
+			t.Errorf("Expected status 410, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Invalid start revision rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/watch?start_revision=-1", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rr.Code)
+		}
+	})
+}
+
+func TestBatch(t *testing.T) {
+	router := setupRouter()
+
+	runBatch := func(t *testing.T, contentType, body string) (int, *bytes.Buffer) {
+		req := httptest.NewRequest("POST", "/kv/batch", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		return rr.Code, rr.Body
+	}
+
+	t.Run("Batch set", func(t *testing.T) {
+		code, body := runBatch(t, "application/json", `{"op":"set","items":[{"key":"a","value":"1"},{"key":"","value":"2"},{"key":"b","value":"3"}]}`)
+		if code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", code)
+		}
+
+		var resp handler.BatchResponse
+		json.NewDecoder(body).Decode(&resp)
+
+		if len(resp.Results) != 3 {
+			t.Fatalf("Expected 3 results, got %v", resp.Results)
+		}
+		if resp.Results[0].Status != http.StatusCreated || resp.Results[0].ETag == "" {
+			t.Errorf("Expected the first item stored with an ETag, got %+v", resp.Results[0])
+		}
+		if resp.Results[1].Status != http.StatusBadRequest {
+			t.Errorf("Expected the empty key to fail with 400, got %+v", resp.Results[1])
+		}
+	})
+
+	t.Run("Batch get", func(t *testing.T) {
+		_, body := runBatch(t, "application/json", `{"op":"get","keys":["b","missing"]}`)
+
+		var resp handler.BatchResponse
+		json.NewDecoder(body).Decode(&resp)
+
+		if resp.Results[0].Status != http.StatusOK || resp.Results[0].Value != "3" {
+			t.Errorf("Expected b to be '3', got %+v", resp.Results[0])
+		}
+		if resp.Results[1].Status != http.StatusNotFound {
+			t.Errorf("Expected the missing key to fail with 404, got %+v", resp.Results[1])
+		}
+	})
+
+	t.Run("Batch delete", func(t *testing.T) {
+		_, body := runBatch(t, "application/json", `{"op":"delete","keys":["a","a"]}`)
+
+		var resp handler.BatchResponse
+		json.NewDecoder(body).Decode(&resp)
+
+		if resp.Results[0].Status != http.StatusOK || resp.Results[1].Status != http.StatusNotFound {
+			t.Errorf("Expected the second delete to find nothing, got %+v", resp.Results)
+		}
+	})
+
+	t.Run("Unknown op rejected", func(t *testing.T) {
+		if code, _ := runBatch(t, "application/json", `{"op":"upsert"}`); code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", code)
+		}
+	})
+
+	t.Run("Bulk load newline-delimited items", func(t *testing.T) {
+		body := `{"key":"bulk:1","value":"1"}
+{"key":"","value":"2"}
+not json
+
+{"key":"bulk:2","value":"2","ttl_seconds":60}
+`
+		code, respBody := runBatch(t, "application/x-ndjson", body)
+		if code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", code)
+		}
+
+		var resp handler.BulkLoadResponse
+		json.NewDecoder(respBody).Decode(&resp)
+
+		if resp.Loaded != 2 || resp.Failed != 2 || len(resp.Failures) != 2 {
+			t.Fatalf("Unexpected response %+v", resp)
+		}
+		if resp.Failures[0].Index != 1 || resp.Failures[0].Status != http.StatusBadRequest || resp.Failures[1].Index != 2 {
+			t.Errorf("Expected failures on lines 1 and 2, got %+v", resp.Failures)
+		}
+	})
+}
+
+func TestBinaryValues(t *testing.T) {
+	router := setupRouter()
+	value := []byte{0x00, 0xff, 0xfe, 'k', 'v', 0x80}
+
+	t.Run("Put a raw value", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/kv/blob?ttl_seconds=60", bytes.NewReader(value))
+		req.Header.Set("Content-Type", "application/octet-stream")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated || rr.Header().Get("ETag") == "" {
+			t.Errorf("Expected status 201 with an ETag, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Get the raw value back", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/kv/blob", nil)
+		req.Header.Set("Accept", "application/octet-stream")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Header().Get("Content-Type") != "application/octet-stream" || !bytes.Equal(rr.Body.Bytes(), value) {
+			t.Errorf("Expected the raw bytes, got %q as %s", rr.Body.Bytes(), rr.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("Get the value as JSON", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "application/json, application/octet-stream", "application/octet-stream;q=0.5, application/json"} {
+			req := httptest.NewRequest("GET", "/kv/blob", nil)
+			req.Header.Set("Accept", accept)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			var resp handler.GetResponse
+			json.NewDecoder(rr.Body).Decode(&resp)
+
+			if resp.Value != "" || !bytes.Equal(resp.ValueBase64, value) {
+				t.Errorf("Expected value_base64 for Accept %q, got %+v", accept, resp)
+			}
+		}
+	})
+
+	t.Run("Put a base64 value as JSON", func(t *testing.T) {
+		body := []byte(`{"value_base64":"AP8="}`)
+		req := httptest.NewRequest("PUT", "/kv/blob", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", rr.Code)
+		}
+
+		req = httptest.NewRequest("GET", "/kv/blob", nil)
+		req.Header.Set("Accept", "application/octet-stream")
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if !bytes.Equal(rr.Body.Bytes(), []byte{0x00, 0xff}) {
+			t.Errorf("Expected the decoded bytes, got %q", rr.Body.Bytes())
+		}
+	})
+
+	t.Run("Text values stay in value", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/kv/text", strings.NewReader(`{"value":"hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		req = httptest.NewRequest("GET", "/kv/text", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if !strings.Contains(rr.Body.String(), `"value":"hello"`) || strings.Contains(rr.Body.String(), "value_base64") {
+			t.Errorf("Expected a plain value, got %s", rr.Body.String())
+		}
+	})
+
+	t.Run("Key in the body must match the path", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/kv/blob", strings.NewReader(`{"key":"other","value":"v"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Try a raw value that is too large", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/kv/blob", bytes.NewReader(make([]byte, 5<<20)))
+		req.Header.Set("Content-Type", "application/octet-stream")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status 413, got %d", rr.Code)
+		}
+	})
+}
+
+func TestIncr(t *testing.T) {
+	router := setupRouter()
+
+	incr := func(t *testing.T, key, body string) (int, handler.IncrResponse) {
+		t.Helper()
+
+		req := httptest.NewRequest("POST", "/kv/"+key+"/incr", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		var resp handler.IncrResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		return rr.Code, resp
+	}
+
+	t.Run("Increment by one without a body", func(t *testing.T) {
+		if code, resp := incr(t, "hits", ""); code != http.StatusOK || resp.Value != 1 {
+			t.Errorf("Expected 1, got %d %+v", code, resp)
+		}
+	})
+
+	t.Run("Increment from an initial value", func(t *testing.T) {
+		if code, resp := incr(t, "visits", `{"delta": 5, "initial_value": 100}`); code != http.StatusOK || resp.Value != 105 {
+			t.Errorf("Expected 105, got %d %+v", code, resp)
+		}
+	})
+
+	t.Run("Decrement with a negative delta", func(t *testing.T) {
+		if code, resp := incr(t, "visits", `{"delta": -10}`); code != http.StatusOK || resp.Value != 95 {
+			t.Errorf("Expected 95, got %d %+v", code, resp)
+		}
+	})
+
+	t.Run("Try incrementing past the max", func(t *testing.T) {
+		if code, _ := incr(t, "visits", `{"max": 95}`); code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", code)
+		}
+	})
+
+	t.Run("Try incrementing a value that is not an integer", func(t *testing.T) {
+		body := []byte(`{"key":"name","value":"alice"}`)
+		req := httptest.NewRequest("POST", "/kv", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if code, _ := incr(t, "name", ""); code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", code)
+		}
+	})
+}
+
+func TestNamespaces(t *testing.T) {
+	router := setupRouter()
+
+	do := func(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Create and list namespaces", func(t *testing.T) {
+		for _, name := range []string{"team-b", "team-a"} {
+			if rr := do(t, "PUT", "/ns/"+name, ""); rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+			}
+		}
+
+		rr := do(t, "GET", "/ns", "")
+
+		var resp handler.NamespacesResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		if rr.Code != http.StatusOK || !slices.Equal(resp.Namespaces, []string{"team-a", "team-b"}) {
+			t.Errorf("Unexpected namespaces %d %+v", rr.Code, resp)
+		}
+	})
+
+	t.Run("Keys are isolated by namespace", func(t *testing.T) {
+		do(t, "POST", "/ns/team-a/kv", `{"key":"config","value":"a"}`)
+		do(t, "POST", "/ns/team-b/kv", `{"key":"config","value":"b"}`)
+		do(t, "POST", "/kv", `{"key":"config","value":"default"}`)
+
+		for path, want := range map[string]string{
+			"/ns/team-a/kv/config": "a",
+			"/ns/team-b/kv/config": "b",
+			"/kv/config":           "default",
+		} {
+			var resp handler.GetResponse
+			rr := do(t, "GET", path, "")
+			json.NewDecoder(rr.Body).Decode(&resp)
+
+			if rr.Code != http.StatusOK || resp.Value != want {
+				t.Errorf("%s: expected '%s', got %d %+v", path, want, rr.Code, resp)
+			}
+		}
+	})
+
+	t.Run("Drop a namespace", func(t *testing.T) {
+		rr := do(t, "DELETE", "/ns/team-a", "")
+
+		var resp handler.DropNamespaceResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		if rr.Code != http.StatusOK || resp.DeletedKeys != 1 {
+			t.Errorf("Expected 1 key deleted, got %d %+v", rr.Code, resp)
+		}
+
+		if rr := do(t, "GET", "/ns/team-a/kv/config", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Try a namespace that does not exist", func(t *testing.T) {
+		if rr := do(t, "POST", "/ns/missing/kv", `{"key":"a","value":"1"}`); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+
+		if rr := do(t, "DELETE", "/ns/missing", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Try creating a namespace twice", func(t *testing.T) {
+		if rr := do(t, "PUT", "/ns/team-b", ""); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", rr.Code)
+		}
+	})
+}
+
+func TestQuotas(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := NewMockClient()
+	router := setupRouterWith(mockClient)
+
+	mockClient.CreateNamespace(ctx, "team-a")
+	mockClient.Namespace("team-a").(*MockClient).SetQuota(client.Usage{MaxKeys: 1, OpsPerSecond: 3})
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Try going over the key quota", func(t *testing.T) {
+		if rr := do("POST", "/ns/team-a/kv", `{"key":"a","value":"1"}`); rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		rr := do("POST", "/ns/team-a/kv", `{"key":"b","value":"1"}`)
+		if rr.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429, got %d", rr.Code)
+		}
+		if retry := rr.Header().Get("Retry-After"); retry != "" {
+			t.Errorf("Expected no Retry-After for a key quota, got '%s'", retry)
+		}
+	})
+
+	t.Run("Read usage", func(t *testing.T) {
+		rr := do("GET", "/ns/team-a/usage", "")
+
+		var resp handler.UsageResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		if rr.Code != http.StatusOK || resp.Namespace != "team-a" || resp.KeyCount != 1 || resp.Bytes != 2 || resp.MaxKeys != 1 {
+			t.Errorf("Unexpected usage %d %+v", rr.Code, resp)
+		}
+
+		if rr := do("GET", "/ns/missing/usage", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Try going over the rate quota", func(t *testing.T) {
+		do("GET", "/ns/team-a/kv/a", "")
+
+		rr := do("GET", "/ns/team-a/kv/a", "")
+		if rr.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429, got %d", rr.Code)
+		}
+		if retry := rr.Header().Get("Retry-After"); retry != "1" {
+			t.Errorf("Expected Retry-After '1', got '%s'", retry)
+		}
+
+		if rr := do("GET", "/usage", ""); rr.Code != http.StatusOK {
+			t.Errorf("Expected the default namespace to be unaffected, got %d", rr.Code)
+		}
+	})
+}
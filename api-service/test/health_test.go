@@ -0,0 +1,143 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/api-service/internal/handler"
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+func TestReadiness(t *testing.T) {
+	get := func(router http.Handler, path string, header ...string) (int, handler.ReadinessResponse) {
+		req := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var resp handler.ReadinessResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		return rr.Code, resp
+	}
+
+	t.Run("Ready while every shard is serving", func(t *testing.T) {
+		sharded, _ := startShards("a", "b")
+
+		code, resp := get(routes(handler.StartHandler(sharded)), "/readyz")
+		if code != http.StatusOK || resp.Status != "ready" || len(resp.Backends) != 2 {
+			t.Fatalf("Expected 200 ready with two backends, got %d %+v", code, resp)
+		}
+		if resp.Backends[0].Shard != "a" || resp.Backends[1].Shard != "b" {
+			t.Errorf("Expected backends in shard order, got %+v", resp.Backends)
+		}
+	})
+
+	t.Run("Not ready while a shard is not serving", func(t *testing.T) {
+		sharded, mocks := startShards("a", "b")
+		mocks["b"].SetStatus("NOT_SERVING")
+		router := routes(handler.StartHandler(sharded))
+
+		code, resp := get(router, "/readyz")
+		if code != http.StatusServiceUnavailable || resp.Status != "not ready" || resp.Backends[1].Status != "NOT_SERVING" {
+			t.Errorf("Expected 503 naming shard b, got %d %+v", code, resp)
+		}
+
+		// Liveness does not depend on the backends.
+		if code, _ := get(router, "/livez"); code != http.StatusOK {
+			t.Errorf("Expected /livez to answer 200, got %d", code)
+		}
+	})
+
+	t.Run("Only callers with credentials see the backends", func(t *testing.T) {
+		sharded, mocks := startShards("a", "b")
+		mocks["b"].SetStatus("NOT_SERVING")
+
+		auth, err := middleware.StartAuth(middleware.AuthOptions{
+			APIKeysFile: writeJSON(t, t.TempDir(), "keys.json", map[string]any{"keys": []map[string]any{
+				{"name": "admin", "key": "admin-key"},
+			}}),
+			Public: []string{"/readyz"},
+		})
+		if err != nil {
+			t.Fatalf("StartAuth failed: %v", err)
+		}
+		router := auth.Authenticate(routes(handler.StartHandler(sharded)))
+
+		for name, header := range map[string][]string{
+			"no credentials": nil,
+			"unknown key":    {"X-API-Key", "wrong"},
+		} {
+			code, resp := get(router, "/readyz", header...)
+			if code != http.StatusServiceUnavailable || resp.Status != "not ready" || resp.Backends != nil {
+				t.Errorf("%s: expected a bare 503, got %d %+v", name, code, resp)
+			}
+		}
+
+		code, resp := get(router, "/readyz", "X-API-Key", "admin-key")
+		if code != http.StatusServiceUnavailable || len(resp.Backends) != 2 {
+			t.Errorf("Expected 503 with both backends, got %d %+v", code, resp)
+		}
+	})
+
+	t.Run("Backends are checked over grpc.health.v1", func(t *testing.T) {
+		healthServer := health.NewServer()
+		healthServer.SetServingStatus(pb.KeyValueStore_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		grpcServer := grpc.NewServer()
+		healthpb.RegisterHealthServer(grpcServer, healthServer)
+		go grpcServer.Serve(lis)
+		defer grpcServer.Stop()
+
+		kvClient, err := client.StartClient(lis.Addr().String())
+		if err != nil {
+			t.Fatalf("StartClient failed: %v", err)
+		}
+		defer kvClient.Close()
+
+		backends := kvClient.Health(context.Background())
+		if len(backends) != 1 || !backends[0].Serving() || backends[0].Connection != "READY" || backends[0].Addr != lis.Addr().String() {
+			t.Errorf("Expected a ready, serving backend, got %+v", backends)
+		}
+
+		healthServer.SetServingStatus(pb.KeyValueStore_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+		if backends := kvClient.Health(context.Background()); backends[0].Serving() || backends[0].Status != "NOT_SERVING" {
+			t.Errorf("Expected a backend that is not serving, got %+v", backends)
+		}
+	})
+
+	t.Run("Try checking a backend that is down", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		addr := lis.Addr().String()
+		lis.Close()
+
+		kvClient, err := client.StartClient(addr)
+		if err != nil {
+			t.Fatalf("StartClient failed: %v", err)
+		}
+		defer kvClient.Close()
+
+		code, resp := get(routes(handler.StartHandler(kvClient)), "/readyz")
+		if code != http.StatusServiceUnavailable || resp.Backends[0].Error == "" || resp.Backends[0].Connection == "READY" {
+			t.Errorf("Expected 503 with the connection error, got %d %+v", code, resp)
+		}
+	})
+}
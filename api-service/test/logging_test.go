@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/api-service/internal/handler"
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+	"GRPC-KV-Store-System/schemas/logging"
+)
+
+// requestIDServer answers Set and remembers the request ID it was sent.
+type requestIDServer struct {
+	pb.UnimplementedKeyValueStoreServer
+	requestID chan string
+}
+
+func (s *requestIDServer) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.requestID <- strings.Join(md.Get(logging.RequestIDHeader), ",")
+	return &pb.SetResponse{Version: 1}, nil
+}
+
+func TestRequestID(t *testing.T) {
+	backend := &requestIDServer{requestID: make(chan string, 1)}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	pb.RegisterKeyValueStoreServer(grpcServer, backend)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	kvClient, err := client.StartClient(lis.Addr().String())
+	if err != nil {
+		t.Fatalf("StartClient failed: %v", err)
+	}
+	defer kvClient.Close()
+
+	server := middleware.RequestID(routes(handler.StartHandler(kvClient)))
+
+	put := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/kv/a", strings.NewReader(`{"value": "1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		rr := httptest.NewRecorder()
+
+		server.ServeHTTP(rr, req)
+		if rr.Code >= 300 {
+			t.Fatalf("Expected success, got %d: %s", rr.Code, rr.Body.String())
+		}
+		return rr
+	}
+
+	t.Run("The caller's request ID is echoed and sent to the KV store", func(t *testing.T) {
+		rr := put("req-123")
+
+		if got := rr.Header().Get("X-Request-ID"); got != "req-123" {
+			t.Errorf("Expected X-Request-ID req-123, got %q", got)
+		}
+		if got := <-backend.requestID; got != "req-123" {
+			t.Errorf("Expected the KV store to be sent req-123, got %q", got)
+		}
+	})
+
+	t.Run("A request ID is made up when missing", func(t *testing.T) {
+		rr := put("")
+
+		id := rr.Header().Get("X-Request-ID")
+		if id == "" {
+			t.Fatal("Expected an X-Request-ID header")
+		}
+		if got := <-backend.requestID; got != id {
+			t.Errorf("Expected the KV store to be sent %s, got %q", id, got)
+		}
+	})
+
+	t.Run("Try an overlong request ID", func(t *testing.T) {
+		rr := put(strings.Repeat("x", 200))
+
+		if id := rr.Header().Get("X-Request-ID"); id == "" || strings.HasPrefix(id, "xxx") {
+			t.Errorf("Expected a new request ID, got %q", id)
+		}
+		<-backend.requestID
+	})
+}
+
+// watchServer fails watches of the namespace "nope" and of the key "bad"
+// before they start, the way the KV store does, and ends the
+// others after a single event.
+type watchServer struct {
+	pb.UnimplementedKeyValueStoreServer
+}
+
+func (s *watchServer) Watch(req *pb.WatchRequest, stream pb.KeyValueStore_WatchServer) error {
+	if req.Namespace == "nope" {
+		return status.Error(codes.NotFound, "namespace not found")
+	}
+	if req.Key == "bad" {
+		return status.Error(codes.InvalidArgument, "invalid key")
+	}
+
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	stream.Send(&pb.WatchEvent{Type: pb.WatchEvent_PUT, Key: req.Key + "1", Value: "v", Revision: 1})
+	return status.Error(codes.Unavailable, "watch ended")
+}
+
+func TestRequestIDOnStreams(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(logging.RequestIDUnaryInterceptor),
+		grpc.ChainStreamInterceptor(logging.RequestIDStreamInterceptor))
+	pb.RegisterKeyValueStoreServer(grpcServer, &watchServer{})
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	kvClient, err := client.StartClient(lis.Addr().String())
+	if err != nil {
+		t.Fatalf("StartClient failed: %v", err)
+	}
+	defer kvClient.Close()
+
+	server := middleware.RequestID(routes(handler.StartHandler(kvClient)))
+
+	watch := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+
+	t.Run("Watches that started stream their events", func(t *testing.T) {
+		rr := watch("/watch?prefix=a")
+
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "event: put") {
+			t.Errorf("Expected an event stream, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("Try watches that fail to start", func(t *testing.T) {
+		for path, code := range map[string]int{
+			"/ns/nope/watch?prefix=a": http.StatusNotFound,
+			"/watch?prefix=bad":       http.StatusBadRequest,
+		} {
+			if rr := watch(path); rr.Code != code {
+				t.Errorf("%s: expected %d, got %d: %s", path, code, rr.Code, rr.Body.String())
+			}
+		}
+	})
+}
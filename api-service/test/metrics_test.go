@@ -0,0 +1,73 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"GRPC-KV-Store-System/api-service/internal/handler"
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	mockClient := NewMockClient()
+	mockClient.CreateNamespace(ctx, "team")
+
+	router := routes(handler.StartHandler(mockClient))
+	metrics := middleware.StartMetrics(router)
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+	server := metrics.Measure(router)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	scrape := func() string {
+		body, _ := io.ReadAll(do("GET", "/metrics", "").Body)
+		return string(body)
+	}
+
+	t.Run("Requests are counted by route template and code", func(t *testing.T) {
+		do("PUT", "/kv/a", `{"value": "1"}`)
+		do("GET", "/kv/a", "")
+		do("GET", "/kv/b", "")
+		do("GET", "/ns/team/kv/b", "")
+
+		body := scrape()
+		for _, line := range []string{
+			`api_http_requests_total{code="201",method="PUT",route="/kv/{key}"} 1`,
+			`api_http_requests_total{code="200",method="GET",route="/kv/{key}"} 1`,
+			`api_http_requests_total{code="404",method="GET",route="/kv/{key}"} 1`,
+			`api_http_requests_total{code="404",method="GET",route="/ns/{namespace}/kv/{key}"} 1`,
+			`api_http_request_duration_seconds_count{code="200",method="GET",route="/kv/{key}"} 1`,
+		} {
+			if !strings.Contains(body, line) {
+				t.Errorf("Expected %s in:\n%s", line, body)
+			}
+		}
+	})
+
+	t.Run("Unknown paths share one series", func(t *testing.T) {
+		do("GET", "/wp-admin", "")
+		do("GET", "/.env", "")
+
+		if line := `api_http_requests_total{code="404",method="GET",route="unmatched"} 2`; !strings.Contains(scrape(), line) {
+			t.Errorf("Expected %s", line)
+		}
+	})
+
+	t.Run("Measured responses still stream", func(t *testing.T) {
+		rr := do("GET", "/watch?prefix=a&start_revision=1", "")
+
+		if rr.Code != http.StatusOK || !rr.Flushed || !strings.HasPrefix(rr.Body.String(), "id: 1\n") {
+			t.Errorf("Expected a flushed stream of events, got %d flushed=%v:\n%s", rr.Code, rr.Flushed, rr.Body.String())
+		}
+	})
+}
@@ -1,47 +1,411 @@
 package test
 
 import (
+	"cmp"
+	"context"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"GRPC-KV-Store-System/api-service/internal/client"
 )
 
 // MockClient is a test double for the gRPC client
 type MockClient struct {
-	store map[string]string
+	store    map[string]string
+	expires  map[string]time.Time
+	versions map[string]int64
+	rev      int64
+
+	// events is every write, for Watch to replay. Revisions up to
+	// compacted count as dropped from the history.
+	events    []client.WatchEvent
+	compacted int64
+
+	// namespaces holds a client for every namespace created. Clients for
+	// namespaces that do not exist are missing, and fail every call.
+	namespaces map[string]*MockClient
+	missing    bool
+
+	// quota caps Set and Get like the store's quotas, except that the
+	// rate limit never refills. ops counts the operations charged to it.
+	quota client.Usage
+	ops   int64
+
+	// status is the serving status Health reports, SERVING when empty.
+	status string
 }
 
 func NewMockClient() *MockClient {
 	return &MockClient{
-		store: make(map[string]string),
+		store:      make(map[string]string),
+		expires:    make(map[string]time.Time),
+		versions:   make(map[string]int64),
+		namespaces: make(map[string]*MockClient),
 	}
 }
 
-func (m *MockClient) Set(key, value string) error {
-	if key == "" {
-		return status.Error(codes.InvalidArgument, "key cannot be empty")
+func (m *MockClient) Namespace(name string) client.ClientInterface {
+	if name == "" {
+		return m
 	}
 
-	m.store[key] = value
+	if ns, ok := m.namespaces[name]; ok {
+		return ns
+	}
+
+	missing := NewMockClient()
+	missing.missing = true
+	return missing
+}
+
+// As ignores the principal; the mock enforces no RBAC policy.
+func (m *MockClient) As(principal string) client.ClientInterface {
+	return m
+}
+
+func (m *MockClient) CreateNamespace(ctx context.Context, name string) error {
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "namespace cannot be empty")
+	}
+
+	if _, exists := m.namespaces[name]; exists {
+		return status.Error(codes.AlreadyExists, "namespace already exists")
+	}
+
+	m.namespaces[name] = NewMockClient()
+	return nil
+}
+
+func (m *MockClient) ListNamespaces(ctx context.Context) ([]string, error) {
+	return slices.Sorted(maps.Keys(m.namespaces)), nil
+}
+
+func (m *MockClient) DropNamespace(ctx context.Context, name string) (int64, error) {
+	ns, exists := m.namespaces[name]
+	if !exists {
+		return 0, status.Error(codes.NotFound, "namespace not found")
+	}
+
+	delete(m.namespaces, name)
+	return int64(len(ns.store)), nil
+}
+
+func (m *MockClient) Usage(ctx context.Context) (client.Usage, error) {
+	if err := m.namespaceErr(); err != nil {
+		return client.Usage{}, err
+	}
+
+	usage := m.quota
+	usage.Keys = int64(len(m.store))
+	usage.Bytes = 0
+	for key, value := range m.store {
+		usage.Bytes += int64(len(key) + len(value))
+	}
+	return usage, nil
+}
+
+func (m *MockClient) Health(ctx context.Context) []client.BackendHealth {
+	backend := client.BackendHealth{Addr: "mock", Connection: "READY", Status: cmp.Or(m.status, "SERVING")}
+	return []client.BackendHealth{backend}
+}
+
+// SetStatus sets the serving status Health reports, such as NOT_SERVING.
+func (m *MockClient) SetStatus(status string) {
+	m.status = status
+}
 
+// SetQuota sets the quotas of the namespace from the Max fields and
+// OpsPerSecond of quota.
+func (m *MockClient) SetQuota(quota client.Usage) {
+	m.quota = quota
+}
+
+// quotaErr charges an operation on key against the quota, failing like the
+// store does when it goes over.
+func (m *MockClient) quotaErr(key string, write bool) error {
+	m.ops++
+	if m.quota.OpsPerSecond > 0 && float64(m.ops) > m.quota.OpsPerSecond {
+		return quotaStatus("ops_per_second", time.Second)
+	}
+
+	if _, exists := m.store[key]; write && !exists && m.quota.MaxKeys > 0 && int64(len(m.store)) >= m.quota.MaxKeys {
+		return quotaStatus("max_keys", 0)
+	}
 	return nil
 }
 
-func (m *MockClient) Get(key string) (string, error) {
+func quotaStatus(quota string, retry time.Duration) error {
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   "QUOTA_EXCEEDED",
+		Domain:   "kvstore",
+		Metadata: map[string]string{"quota": quota},
+	}}
+	if retry > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retry)})
+	}
+
+	st, _ := status.New(codes.ResourceExhausted, "namespace is over its "+quota+" quota").WithDetails(details...)
+	return st.Err()
+}
+
+func (m *MockClient) namespaceErr() error {
+	if m.missing {
+		return status.Error(codes.NotFound, "namespace not found")
+	}
+	return nil
+}
+
+func (m *MockClient) Set(ctx context.Context, key, value string) (int64, error) {
+	if err := m.namespaceErr(); err != nil {
+		return 0, err
+	}
+
+	if key == "" {
+		return 0, status.Error(codes.InvalidArgument, "key cannot be empty")
+	}
+
+	if err := m.quotaErr(key, true); err != nil {
+		return 0, err
+	}
+
+	return m.put(key, value, 0), nil
+}
+
+func (m *MockClient) SetWithTTL(ctx context.Context, key, value string, ttl time.Duration) (int64, error) {
+	if err := m.namespaceErr(); err != nil {
+		return 0, err
+	}
+
+	if key == "" {
+		return 0, status.Error(codes.InvalidArgument, "key cannot be empty")
+	}
+
+	if ttl <= 0 {
+		return 0, status.Error(codes.InvalidArgument, "ttl must be positive")
+	}
+
+	return m.put(key, value, ttl), nil
+}
+
+func (m *MockClient) CompareAndSwap(ctx context.Context, key string, expectedVersion int64, value string, ttl time.Duration) (int64, error) {
+	if err := m.namespaceErr(); err != nil {
+		return 0, err
+	}
+
+	if key == "" {
+		return 0, status.Error(codes.InvalidArgument, "key cannot be empty")
+	}
+
+	m.expire(key)
+
+	if m.versions[key] != expectedVersion {
+		return 0, status.Error(codes.FailedPrecondition, "key does not match the expected version or value")
+	}
+
+	return m.put(key, value, ttl), nil
+}
+
+func (m *MockClient) Increment(ctx context.Context, key string, delta int64, opts client.CounterOptions) (int64, int64, error) {
+	if err := m.namespaceErr(); err != nil {
+		return 0, 0, err
+	}
+
+	if key == "" {
+		return 0, 0, status.Error(codes.InvalidArgument, "key cannot be empty")
+	}
+
+	m.expire(key)
+
+	value := opts.Initial
+	if current, ok := m.store[key]; ok {
+		parsed, err := strconv.ParseInt(current, 10, 64)
+		if err != nil {
+			return 0, 0, status.Error(codes.InvalidArgument, "value is not an integer")
+		}
+		value = parsed
+	}
+
+	value += delta
+	if (opts.Min != nil && value < *opts.Min) || (opts.Max != nil && value > *opts.Max) {
+		return 0, 0, status.Error(codes.OutOfRange, "value would be out of range")
+	}
+
+	expiresAt, expiring := m.expires[key]
+	version := m.put(key, strconv.FormatInt(value, 10), 0)
+	if expiring {
+		m.expires[key] = expiresAt
+	}
+
+	return value, version, nil
+}
+
+func (m *MockClient) Get(ctx context.Context, key string) (string, error) {
+	value, _, err := m.GetWithVersion(ctx, key)
+	return value, err
+}
+
+func (m *MockClient) GetWithVersion(ctx context.Context, key string) (string, int64, error) {
+	if err := m.namespaceErr(); err != nil {
+		return "", 0, err
+	}
+
 	if key == "" {
-		return "", status.Error(codes.InvalidArgument, "key cannot be empty")
+		return "", 0, status.Error(codes.InvalidArgument, "key cannot be empty")
 	}
 
+	if err := m.quotaErr(key, false); err != nil {
+		return "", 0, err
+	}
+
+	m.expire(key)
+
 	value, exists := m.store[key]
 	if !exists {
-		return "", status.Error(codes.NotFound, "key not found")
+		return "", 0, status.Error(codes.NotFound, "key not found")
+	}
+
+	return value, m.versions[key], nil
+}
+
+func (m *MockClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if _, _, err := m.GetWithVersion(ctx, key); err != nil {
+		return 0, err
+	}
+
+	expiresAt, ok := m.expires[key]
+	if !ok {
+		return client.NoExpiry, nil
+	}
+
+	return time.Until(expiresAt).Round(time.Second), nil
+}
+
+func (m *MockClient) Scan(ctx context.Context, prefix string, limit int, cursor string) ([]client.KeyValue, string, error) {
+	if err := m.namespaceErr(); err != nil {
+		return nil, "", err
+	}
+
+	if limit < 0 {
+		return nil, "", status.Error(codes.InvalidArgument, "limit cannot be negative")
+	}
+
+	var keys []string
+	for key := range m.store {
+		m.expire(key)
+		if _, exists := m.store[key]; exists && strings.HasPrefix(key, prefix) && key > cursor {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	next := ""
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+		next = keys[limit-1]
+	}
+
+	items := make([]client.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, client.KeyValue{Key: key, Value: m.store[key], Version: m.versions[key]})
 	}
 
-	return value, nil
+	return items, next, nil
 }
 
-func (m *MockClient) Delete(key string) error {
+func (m *MockClient) Txn(ctx context.Context, txn client.Txn) (client.TxnResult, error) {
+	if err := m.namespaceErr(); err != nil {
+		return client.TxnResult{}, err
+	}
+
+	succeeded := true
+	for _, c := range txn.Compare {
+		m.expire(c.Key)
+		value, exists := m.store[c.Key]
+
+		var order int
+		switch c.Target {
+		case client.CompareValue:
+			if !exists {
+				succeeded = false
+			}
+			order = strings.Compare(value, c.Value)
+		case client.CompareVersion:
+			order = cmp.Compare(m.versions[c.Key], c.Version)
+		case client.CompareExists:
+			if exists != c.Exists {
+				order = 1
+			}
+		}
+
+		switch c.Result {
+		case client.Equal:
+			succeeded = succeeded && order == 0
+		case client.NotEqual:
+			succeeded = succeeded && order != 0
+		case client.Greater:
+			succeeded = succeeded && order > 0
+		case client.Less:
+			succeeded = succeeded && order < 0
+		}
+	}
+
+	ops := txn.Success
+	if !succeeded {
+		ops = txn.Failure
+	}
+
+	// Every write in a transaction shares one revision.
+	rev := m.rev + 1
+	result := client.TxnResult{Succeeded: succeeded}
+	wrote := false
+
+	for _, op := range ops {
+		if op.Key == "" {
+			return client.TxnResult{}, status.Error(codes.InvalidArgument, "key cannot be empty")
+		}
+
+		m.expire(op.Key)
+		value, exists := m.store[op.Key]
+
+		switch op.Type {
+		case client.TxnGet:
+			result.Results = append(result.Results, client.TxnOpResult{Key: op.Key, Value: value, Version: m.versions[op.Key], Found: exists})
+		case client.TxnPut:
+			m.put(op.Key, op.Value, op.TTL)
+			m.versions[op.Key] = rev
+			wrote = true
+			result.Results = append(result.Results, client.TxnOpResult{Key: op.Key, Value: op.Value, Version: rev, Found: true})
+		case client.TxnDelete:
+			m.remove(op.Key)
+			wrote = wrote || exists
+			result.Results = append(result.Results, client.TxnOpResult{Key: op.Key, Found: exists})
+		}
+	}
+
+	if wrote {
+		m.rev = rev
+	}
+	result.Revision = m.rev
+
+	return result, nil
+}
+
+func (m *MockClient) Delete(ctx context.Context, key string) error {
+	if err := m.namespaceErr(); err != nil {
+		return err
+	}
+
 	if key == "" {
 		return status.Error(codes.InvalidArgument, "key cannot be empty")
 	}
@@ -50,14 +414,155 @@ func (m *MockClient) Delete(key string) error {
 		return status.Error(codes.NotFound, "key not found")
 	}
 
-	delete(m.store, key)
+	m.remove(key)
+	m.rev++
+	m.events = append(m.events, client.WatchEvent{Type: client.EventDelete, Key: key, Revision: m.rev})
 
 	return nil
 }
 
+func (m *MockClient) BatchSet(ctx context.Context, items []client.BatchItem) ([]client.BatchResult, error) {
+	if err := m.namespaceErr(); err != nil {
+		return nil, err
+	}
+
+	results := make([]client.BatchResult, 0, len(items))
+	for _, item := range items {
+		version, err := m.setItem(ctx, item)
+		results = append(results, client.BatchResult{Key: item.Key, Value: item.Value, Version: version, Err: err})
+	}
+	return results, nil
+}
+
+func (m *MockClient) BatchGet(ctx context.Context, keys []string) ([]client.BatchResult, error) {
+	if err := m.namespaceErr(); err != nil {
+		return nil, err
+	}
+
+	results := make([]client.BatchResult, 0, len(keys))
+	for _, key := range keys {
+		value, version, err := m.GetWithVersion(ctx, key)
+		results = append(results, client.BatchResult{Key: key, Value: value, Version: version, Err: err})
+	}
+	return results, nil
+}
+
+func (m *MockClient) BatchDelete(ctx context.Context, keys []string) ([]client.BatchResult, error) {
+	if err := m.namespaceErr(); err != nil {
+		return nil, err
+	}
+
+	results := make([]client.BatchResult, 0, len(keys))
+	for _, key := range keys {
+		results = append(results, client.BatchResult{Key: key, Err: m.Delete(ctx, key)})
+	}
+	return results, nil
+}
+
+func (m *MockClient) BulkLoad(ctx context.Context, next func() (client.BatchItem, error)) (client.BulkLoadResult, error) {
+	if err := m.namespaceErr(); err != nil {
+		return client.BulkLoadResult{}, err
+	}
+
+	var result client.BulkLoadResult
+	for index := int64(0); ; index++ {
+		item, err := next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return client.BulkLoadResult{}, err
+		}
+
+		if _, err := m.setItem(ctx, item); err != nil {
+			result.Failed++
+			result.Failures = append(result.Failures, client.BulkLoadFailure{Index: index, Key: item.Key, Err: err})
+			continue
+		}
+		result.Loaded++
+	}
+}
+
+func (m *MockClient) setItem(ctx context.Context, item client.BatchItem) (int64, error) {
+	if item.TTL > 0 {
+		return m.SetWithTTL(ctx, item.Key, item.Value, item.TTL)
+	}
+	return m.Set(ctx, item.Key, item.Value)
+}
+
+// Watch replays the recorded events from startRevision. The mock has no
+// concurrent writers, so once those are delivered the stream ends.
+func (m *MockClient) Watch(ctx context.Context, prefix string, startRevision int64) (client.WatchStream, error) {
+	if err := m.namespaceErr(); err != nil {
+		return nil, err
+	}
+
+	if startRevision > 0 && startRevision <= m.compacted {
+		return nil, status.Error(codes.OutOfRange, "start revision has been compacted")
+	}
+
+	stream := &mockWatchStream{}
+	if startRevision > 0 {
+		for _, ev := range m.events {
+			if ev.Revision >= startRevision && strings.HasPrefix(ev.Key, prefix) {
+				stream.events = append(stream.events, ev)
+			}
+		}
+	}
+
+	return stream, nil
+}
+
 func (m *MockClient) Close() error {
 	return nil
 }
 
+func (m *MockClient) put(key, value string, ttl time.Duration) int64 {
+	m.rev++
+	m.store[key] = value
+	m.versions[key] = m.rev
+	m.events = append(m.events, client.WatchEvent{Type: client.EventPut, Key: key, Value: value, Revision: m.rev})
+
+	if ttl > 0 {
+		m.expires[key] = time.Now().Add(ttl)
+	} else {
+		delete(m.expires, key)
+	}
+
+	return m.rev
+}
+
+func (m *MockClient) remove(key string) {
+	delete(m.store, key)
+	delete(m.expires, key)
+	delete(m.versions, key)
+}
+
+func (m *MockClient) expire(key string) {
+	if expiresAt, ok := m.expires[key]; ok && !time.Now().Before(expiresAt) {
+		m.remove(key)
+	}
+}
+
+// compact drops the recorded events up to rev.
+func (m *MockClient) compact(rev int64) {
+	m.compacted = rev
+}
+
+type mockWatchStream struct {
+	events []client.WatchEvent
+}
+
+func (s *mockWatchStream) Recv() (client.WatchEvent, error) {
+	if len(s.events) == 0 {
+		return client.WatchEvent{}, status.Error(codes.Unavailable, "watch ended")
+	}
+
+	ev := s.events[0]
+	s.events = s.events[1:]
+
+	return ev, nil
+}
+
 // Ensure MockClient implements ClientInterface
 var _ client.ClientInterface = (*MockClient)(nil)
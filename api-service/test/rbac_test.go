@@ -0,0 +1,136 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"GRPC-KV-Store-System/api-service/internal/handler"
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+func TestRBAC(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+
+	keysFile := writeJSON(t, dir, "keys.json", map[string]any{"keys": []map[string]any{
+		{"name": "billing", "key": "billing-key"},
+		{"name": "ops", "key": "ops-key"},
+	}})
+	policyFile := filepath.Join(dir, "policy.json")
+	os.WriteFile(policyFile, []byte(`{
+		"roles": {
+			"config-reader": [
+				{"prefix": "config:*", "verbs": ["read"]},
+				{"prefix": "app:", "verbs": ["read", "write"]},
+				{"prefix": "secrets:", "verbs": ["*"], "deny": true}
+			],
+			"team": [{"namespace": "team", "prefix": "app:", "verbs": ["read", "write"]}],
+			"admin": [{"prefix": "", "verbs": ["*"]}]
+		},
+		"bindings": {"billing": ["config-reader", "team"], "ops": ["admin"]}
+	}`), 0o644)
+
+	auth, err := middleware.StartAuth(middleware.AuthOptions{APIKeysFile: keysFile})
+	if err != nil {
+		t.Fatalf("StartAuth failed: %v", err)
+	}
+	enforcer, err := rbac.LoadEnforcer(policyFile, false)
+	if err != nil {
+		t.Fatalf("LoadEnforcer failed: %v", err)
+	}
+
+	mockClient := NewMockClient()
+	mockClient.Set(ctx, "config:db", "postgres://")
+	mockClient.CreateNamespace(ctx, "team")
+
+	h := handler.StartHandler(mockClient)
+	h.SetPolicy(enforcer)
+	router := auth.Authenticate(routes(h))
+
+	do := func(key, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", key)
+		// Newline-delimited bodies are bulk loads.
+		if strings.HasSuffix(body, "\n") {
+			req.Header.Set("Content-Type", "application/x-ndjson")
+		}
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Allowed requests go through", func(t *testing.T) {
+		for _, tc := range []struct{ key, method, path, body string }{
+			{"billing-key", "GET", "/kv/config:db", ""},
+			{"billing-key", "GET", "/kv?prefix=config:", ""},
+			{"billing-key", "PUT", "/kv/app:a", `{"value": "1"}`},
+			{"billing-key", "POST", "/kv/batch", `{"op": "get", "keys": ["config:db", "app:a"]}`},
+			{"ops-key", "DELETE", "/kv/config:db", ""},
+			{"ops-key", "GET", "/ns", ""},
+		} {
+			if rr := do(tc.key, tc.method, tc.path, tc.body); rr.Code >= 300 {
+				t.Errorf("%s %s: expected success, got %d: %s", tc.method, tc.path, rr.Code, rr.Body.String())
+			}
+		}
+	})
+
+	t.Run("Try requests the policy denies", func(t *testing.T) {
+		for _, tc := range []struct{ method, path, body, reason string }{
+			{"PUT", "/kv/config:db", `{"value": "mysql://"}`, `allows write key "config:db"`},
+			{"GET", "/kv/secrets:token", "", `denies read on keys starting with "secrets:"`},
+			{"GET", "/kv", "", `denies read`},
+			{"POST", "/kv/config:n/incr", "", "allows write"},
+			{"POST", "/kv/batch", `{"op": "delete", "keys": ["app:a"]}`, "allows delete"},
+			{"POST", "/txn", `{"success": [{"op": "put", "key": "app:a", "value": "2"}], "failure": [{"op": "get", "key": "secrets:token"}]}`, "denies read"},
+			{"GET", "/watch?prefix=secrets:", "", "denies read"},
+			{"PUT", "/ns/team", "", "allows admin"},
+		} {
+			rr := do("billing-key", tc.method, tc.path, tc.body)
+
+			var resp handler.ErrorResponse
+			json.NewDecoder(rr.Body).Decode(&resp)
+			if rr.Code != http.StatusForbidden || !strings.Contains(resp.Error, tc.reason) {
+				t.Errorf("%s %s: expected 403 explaining %q, got %d %+v", tc.method, tc.path, tc.reason, rr.Code, resp)
+			}
+		}
+	})
+
+	t.Run("Grants are on their own namespace", func(t *testing.T) {
+		if rr := do("billing-key", "PUT", "/ns/team/kv/app:a", `{"value": "1"}`); rr.Code != http.StatusCreated {
+			t.Errorf("Expected the team role to allow the write, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		for _, tc := range []struct{ key, path, reason string }{
+			{"billing-key", "/ns/team/kv/config:db", `allows read key "config:db" of namespace "team"`},
+			{"ops-key", "/ns/team/kv/app:a", `of namespace "team"`},
+			{"ops-key", "/ns/team/usage", "allows admin"},
+		} {
+			rr := do(tc.key, "GET", tc.path, "")
+
+			var resp handler.ErrorResponse
+			json.NewDecoder(rr.Body).Decode(&resp)
+			if rr.Code != http.StatusForbidden || !strings.Contains(resp.Error, tc.reason) {
+				t.Errorf("%s: expected 403 explaining %q, got %d %+v", tc.path, tc.reason, rr.Code, resp)
+			}
+		}
+	})
+
+	t.Run("Bulk loads fail the items the policy denies", func(t *testing.T) {
+		rr := do("billing-key", "POST", "/kv/batch", "{\"key\": \"app:b\", \"value\": \"1\"}\n{\"key\": \"config:b\", \"value\": \"1\"}\n")
+
+		var resp handler.BulkLoadResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		if rr.Code != http.StatusOK || resp.Loaded != 1 || resp.Failed != 1 || resp.Failures[0].Status != http.StatusForbidden || resp.Failures[0].Key != "config:b" {
+			t.Errorf("Unexpected response %d %+v", rr.Code, resp)
+		}
+	})
+}
@@ -0,0 +1,416 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+)
+
+func startShards(names ...string) (*client.ShardedClient, map[string]*MockClient) {
+	mocks := make(map[string]*MockClient)
+	clients := make(map[string]client.ClientInterface)
+	for _, name := range names {
+		mocks[name] = NewMockClient()
+		clients[name] = mocks[name]
+	}
+
+	return client.NewShardedClient(clients, client.DefaultVirtualNodes), mocks
+}
+
+func TestRing(t *testing.T) {
+	keys := make([]string, 10000)
+	for n := range keys {
+		keys[n] = fmt.Sprintf("user:%d", n)
+	}
+
+	three := client.NewRing([]string{"a", "b", "c"}, client.DefaultVirtualNodes)
+
+	t.Run("Keys spread evenly", func(t *testing.T) {
+		counts := make(map[string]int)
+		for _, key := range keys {
+			counts[three.Owner(key)]++
+		}
+
+		for _, shard := range three.Shards() {
+			if counts[shard] < 2500 || counts[shard] > 4200 {
+				t.Errorf("Expected about a third of the keys on %s, got %d", shard, counts[shard])
+			}
+		}
+	})
+
+	t.Run("Adding a shard only moves keys onto it", func(t *testing.T) {
+		four := client.NewRing([]string{"a", "b", "c", "d"}, client.DefaultVirtualNodes)
+
+		moved := 0
+		for _, key := range keys {
+			before, after := three.Owner(key), four.Owner(key)
+			if before == after {
+				continue
+			}
+
+			moved++
+			if after != "d" {
+				t.Fatalf("Expected %s to move to d, it moved from %s to %s", key, before, after)
+			}
+		}
+
+		if moved < 1800 || moved > 3200 {
+			t.Errorf("Expected about a quarter of the keys to move, got %d", moved)
+		}
+	})
+
+	t.Run("Parse shards", func(t *testing.T) {
+		shards, err := client.ParseShards("a=kv-a:50051, kv-b:50051")
+		if err != nil {
+			t.Fatalf("ParseShards failed: %v", err)
+		}
+
+		want := []client.Shard{{Name: "a", Addr: "kv-a:50051"}, {Name: "kv-b:50051", Addr: "kv-b:50051"}}
+		if !slices.Equal(shards, want) {
+			t.Errorf("Expected %v, got %v", want, shards)
+		}
+
+		if _, err := client.ParseShards("a=x:1,a=y:2"); err == nil {
+			t.Error("Expected a duplicate shard to be rejected")
+		}
+	})
+}
+
+func TestShardedClient(t *testing.T) {
+	ctx := context.Background()
+
+	sharded, mocks := startShards("a", "b", "c")
+	ring := sharded.Ring()
+
+	for n := range 50 {
+		key := fmt.Sprintf("user:%02d", n)
+		if _, err := sharded.Set(ctx, key, fmt.Sprint(n)); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	t.Run("Keys are stored on their shard only", func(t *testing.T) {
+		for name, mock := range mocks {
+			for key := range mock.store {
+				if owner := ring.Owner(key); owner != name {
+					t.Errorf("Expected %s on %s, found it on %s", key, owner, name)
+				}
+			}
+		}
+
+		if value, err := sharded.Get(ctx, "user:07"); err != nil || value != "7" {
+			t.Errorf("Expected '7', got '%s' (%v)", value, err)
+		}
+	})
+
+	t.Run("Scan merges every shard in order", func(t *testing.T) {
+		var keys []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 20 {
+				t.Fatal("Scan did not finish")
+			}
+
+			items, next, err := sharded.Scan(ctx, "user:", 7, cursor)
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+
+			if len(items) > 7 {
+				t.Fatalf("Expected at most 7 items, got %d", len(items))
+			}
+
+			for _, item := range items {
+				keys = append(keys, item.Key)
+			}
+
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+
+		if len(keys) != 50 || !slices.IsSorted(keys) || len(slices.Compact(slices.Clone(keys))) != 50 {
+			t.Errorf("Expected the 50 keys once each in order, got %v", keys)
+		}
+	})
+
+	t.Run("Try an invalid cursor", func(t *testing.T) {
+		if _, _, err := sharded.Scan(ctx, "", 10, "not a cursor"); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("Batches keep their order across shards", func(t *testing.T) {
+		keys := []string{"user:01", "missing", "user:02", "user:03"}
+		results, err := sharded.BatchGet(ctx, keys)
+		if err != nil {
+			t.Fatalf("BatchGet failed: %v", err)
+		}
+
+		for n, result := range results {
+			if result.Key != keys[n] {
+				t.Errorf("Expected %s at %d, got %s", keys[n], n, result.Key)
+			}
+		}
+
+		if results[0].Value != "1" || results[3].Value != "3" || status.Code(results[1].Err) != codes.NotFound {
+			t.Errorf("Unexpected results %v", results)
+		}
+	})
+
+	t.Run("Transactions must stay on one shard", func(t *testing.T) {
+		var same, other string
+		for n := 0; other == "" || same == ""; n++ {
+			key := fmt.Sprintf("user:%02d", n)
+			if key == "user:00" {
+				continue
+			}
+			if ring.Owner(key) == ring.Owner("user:00") {
+				same = key
+			} else {
+				other = key
+			}
+		}
+
+		txn := func(key string) client.Txn {
+			return client.Txn{Success: []client.TxnOp{
+				{Type: client.TxnPut, Key: "user:00", Value: "x"},
+				{Type: client.TxnPut, Key: key, Value: "x"},
+			}}
+		}
+
+		if _, err := sharded.Txn(ctx, txn(same)); err != nil {
+			t.Errorf("Expected a single-shard Txn to run, got %v", err)
+		}
+
+		if _, err := sharded.Txn(ctx, txn(other)); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("Watches cannot replay across shards", func(t *testing.T) {
+		if _, err := sharded.Watch(context.Background(), "", 1); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("Bulk load reports failures at their index", func(t *testing.T) {
+		n := 0
+		result, err := sharded.BulkLoad(ctx, func() (client.BatchItem, error) {
+			defer func() { n++ }()
+			switch {
+			case n == 300:
+				return client.BatchItem{}, io.EOF
+			case n%100 == 42:
+				return client.BatchItem{Key: ""}, nil
+			}
+			return client.BatchItem{Key: fmt.Sprintf("bulk:%03d", n), Value: "v"}, nil
+		})
+		if err != nil {
+			t.Fatalf("BulkLoad failed: %v", err)
+		}
+
+		if result.Loaded != 297 || result.Failed != 3 {
+			t.Fatalf("Expected 297 loaded and 3 failed, got %+v", result)
+		}
+
+		for k, f := range result.Failures {
+			if want := int64(k*100 + 42); f.Index != want {
+				t.Errorf("Expected failure %d at index %d, got %d", k, want, f.Index)
+			}
+		}
+	})
+}
+
+func TestRebalance(t *testing.T) {
+	ctx := context.Background()
+
+	sharded, mocks := startShards("a", "b")
+
+	for n := range 200 {
+		sharded.Set(ctx, fmt.Sprintf("user:%03d", n), fmt.Sprint(n))
+	}
+	sharded.SetWithTTL(ctx, "session", "s", time.Hour)
+
+	grown, grownMocks := startShards("a", "b", "c")
+	for name, mock := range mocks {
+		grownMocks[name].store = mock.store
+		grownMocks[name].versions = mock.versions
+		grownMocks[name].expires = mock.expires
+	}
+
+	clients := map[string]client.ClientInterface{"a": grownMocks["a"], "b": grownMocks["b"], "c": grownMocks["c"]}
+
+	t.Run("Dry run moves nothing", func(t *testing.T) {
+		stats, err := client.Rebalance(ctx, clients, grown.Ring(), client.RebalanceOptions{DryRun: true, PageSize: 16})
+		if err != nil {
+			t.Fatalf("Rebalance failed: %v", err)
+		}
+
+		if stats.Scanned != 201 || stats.Moved == 0 || len(grownMocks["c"].store) != 0 {
+			t.Errorf("Expected keys to be counted but not moved, got %+v", stats)
+		}
+	})
+
+	t.Run("Keys move to their new shard", func(t *testing.T) {
+		// A write through the new ring wins over the copy being moved.
+		var newer string
+		for n := range 200 {
+			if key := fmt.Sprintf("user:%03d", n); grown.Ring().Owner(key) == "c" {
+				newer = key
+				break
+			}
+		}
+		grown.Set(ctx, newer, "newer")
+
+		stats, err := client.Rebalance(ctx, clients, grown.Ring(), client.RebalanceOptions{PageSize: 16})
+		if err != nil {
+			t.Fatalf("Rebalance failed: %v", err)
+		}
+
+		if stats.Moved == 0 || stats.Conflicts != 0 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+
+		for name, mock := range grownMocks {
+			for key := range mock.store {
+				if owner := grown.Ring().Owner(key); owner != name {
+					t.Errorf("Expected %s on %s, found it on %s", key, owner, name)
+				}
+			}
+		}
+
+		for n := range 200 {
+			key := fmt.Sprintf("user:%03d", n)
+			want := fmt.Sprint(n)
+			if key == newer {
+				want = "newer"
+			}
+			if value, err := grown.Get(ctx, key); err != nil || value != want {
+				t.Errorf("Expected %s=%s, got '%s' (%v)", key, want, value, err)
+			}
+		}
+
+		if ttl, err := grown.TTL(ctx, "session"); err != nil || ttl <= 0 {
+			t.Errorf("Expected session to keep its TTL, got %v (%v)", ttl, err)
+		}
+
+		stats, _ = client.Rebalance(ctx, clients, grown.Ring(), client.RebalanceOptions{})
+		if stats.Moved != 0 {
+			t.Errorf("Expected a second run to move nothing, got %+v", stats)
+		}
+	})
+}
+
+func TestShardedNamespaces(t *testing.T) {
+	ctx := context.Background()
+
+	sharded, mocks := startShards("a", "b", "c")
+
+	t.Run("Namespaces are created on every shard", func(t *testing.T) {
+		if err := sharded.CreateNamespace(ctx, "tenant"); err != nil {
+			t.Fatalf("CreateNamespace failed: %v", err)
+		}
+
+		for name, mock := range mocks {
+			if _, ok := mock.namespaces["tenant"]; !ok {
+				t.Errorf("Expected the namespace on shard %s", name)
+			}
+		}
+
+		if err := sharded.CreateNamespace(ctx, "tenant"); status.Code(err) != codes.AlreadyExists {
+			t.Errorf("Expected AlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("A create that failed part way can be retried", func(t *testing.T) {
+		mocks["b"].CreateNamespace(ctx, "partial")
+
+		if err := sharded.CreateNamespace(ctx, "partial"); err != nil {
+			t.Fatalf("CreateNamespace failed: %v", err)
+		}
+
+		if names, _ := sharded.ListNamespaces(ctx); !slices.Equal(names, []string{"partial", "tenant"}) {
+			t.Errorf("Unexpected namespaces %v", names)
+		}
+	})
+
+	t.Run("Keys are sharded within a namespace", func(t *testing.T) {
+		tenant := sharded.Namespace("tenant")
+		for n := range 30 {
+			tenant.Set(ctx, fmt.Sprintf("key:%02d", n), fmt.Sprint(n))
+		}
+
+		items, _, err := tenant.Scan(ctx, "", 0, "")
+		if err != nil || len(items) != 30 {
+			t.Errorf("Expected 30 keys, got %d (%v)", len(items), err)
+		}
+
+		if items, _, _ := sharded.Scan(ctx, "", 0, ""); len(items) != 0 {
+			t.Errorf("Expected the default namespace to be empty, got %d keys", len(items))
+		}
+	})
+
+	t.Run("Usage adds up the shards", func(t *testing.T) {
+		for _, mock := range mocks {
+			mock.namespaces["tenant"].SetQuota(client.Usage{MaxKeys: 100, MaxValueBytes: 64})
+		}
+
+		usage, err := sharded.Namespace("tenant").Usage(ctx)
+		if err != nil || usage.Keys != 30 || usage.MaxKeys != 300 || usage.MaxValueBytes != 64 {
+			t.Errorf("Unexpected usage %+v (%v)", usage, err)
+		}
+	})
+
+	t.Run("Drop a namespace from every shard", func(t *testing.T) {
+		deleted, err := sharded.DropNamespace(ctx, "tenant")
+		if err != nil || deleted != 30 {
+			t.Errorf("Expected 30 keys deleted, got %d (%v)", deleted, err)
+		}
+
+		if _, err := sharded.DropNamespace(ctx, "tenant"); status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+}
+
+func TestRebalanceNamespaces(t *testing.T) {
+	ctx := context.Background()
+
+	small, mocks := startShards("a")
+	small.CreateNamespace(ctx, "tenant")
+
+	tenant := small.Namespace("tenant")
+	for n := range 50 {
+		tenant.Set(ctx, fmt.Sprintf("key:%02d", n), fmt.Sprint(n))
+	}
+
+	grown, grownMocks := startShards("a", "b")
+	grownMocks["a"] = mocks["a"]
+	clients := map[string]client.ClientInterface{"a": mocks["a"], "b": grownMocks["b"]}
+
+	stats, err := client.Rebalance(ctx, clients, grown.Ring(), client.RebalanceOptions{PageSize: 8})
+	if err != nil {
+		t.Fatalf("Rebalance failed: %v", err)
+	}
+
+	moved := grownMocks["b"].namespaces["tenant"]
+	if stats.Moved == 0 || moved == nil || int64(len(moved.store)) != stats.Moved {
+		t.Fatalf("Expected the namespace's keys to move to b, got %+v", stats)
+	}
+
+	for key := range moved.store {
+		if owner := grown.Ring().Owner(key); owner != "b" {
+			t.Errorf("Expected %s on %s, found it on b", key, owner)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/api-service/internal/handler"
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// deadlineServer answers Set and remembers how long it was given. Sets of
+// the key "slow" wait for the caller to give up.
+type deadlineServer struct {
+	pb.UnimplementedKeyValueStoreServer
+	remaining chan time.Duration
+	abandoned chan error
+}
+
+func (s *deadlineServer) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResponse, error) {
+	if req.Key == "slow" {
+		<-ctx.Done()
+		s.abandoned <- ctx.Err()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		s.remaining <- 0
+	} else {
+		s.remaining <- time.Until(deadline)
+	}
+	return &pb.SetResponse{Version: 1}, nil
+}
+
+func TestRequestTimeout(t *testing.T) {
+	backend := &deadlineServer{remaining: make(chan time.Duration, 1), abandoned: make(chan error, 1)}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	pb.RegisterKeyValueStoreServer(grpcServer, backend)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	kvClient, err := client.StartClient(lis.Addr().String())
+	if err != nil {
+		t.Fatalf("StartClient failed: %v", err)
+	}
+	defer kvClient.Close()
+
+	timeouts, err := client.ParseTimeouts("Set=1500ms")
+	if err != nil {
+		t.Fatalf("ParseTimeouts failed: %v", err)
+	}
+	kvClient.SetTimeouts(timeouts)
+
+	server := middleware.StartTimeout(3 * time.Second).Limit(routes(handler.StartHandler(kvClient)))
+
+	put := func(ctx context.Context, key, timeout string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(ctx, "PUT", "/kv/"+key, strings.NewReader(`{"value": "1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if timeout != "" {
+			req.Header.Set("X-Request-Timeout", timeout)
+		}
+		rr := httptest.NewRecorder()
+
+		server.ServeHTTP(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		name    string
+		timeout string
+		// The KV store should be given at most max, and more than max
+		// less a second.
+		max time.Duration
+	}{
+		{"Requests without X-Request-Timeout get the operation's timeout", "", 1500 * time.Millisecond},
+		{"X-Request-Timeout replaces the operation's timeout", "2500ms", 2500 * time.Millisecond},
+		{"Longer timeouts are cut down to the max", "1h", 3 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rr := put(context.Background(), "a", tt.timeout); rr.Code >= 300 {
+				t.Fatalf("Expected success, got %d: %s", rr.Code, rr.Body.String())
+			}
+
+			remaining := <-backend.remaining
+			if remaining > tt.max || remaining < tt.max-time.Second {
+				t.Errorf("Expected the KV store to be given about %s, got %s", tt.max, remaining)
+			}
+		})
+	}
+
+	t.Run("Requests that time out answer 504", func(t *testing.T) {
+		rr := put(context.Background(), "slow", "100ms")
+		if rr.Code != http.StatusGatewayTimeout {
+			t.Errorf("Expected 504, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		// The KV store may see its own deadline pass or the API cancel
+		// the call first.
+		if err := <-backend.abandoned; err == nil {
+			t.Error("Expected the KV store to give up")
+		}
+	})
+
+	t.Run("The KV store stops when the caller goes away", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		put(ctx, "slow", "")
+
+		select {
+		case err := <-backend.abandoned:
+			if err != context.Canceled {
+				t.Errorf("Expected the KV store to see the request cancelled, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Error("Expected the KV store to give up before the operation's timeout")
+		}
+	})
+
+	t.Run("Try an invalid X-Request-Timeout", func(t *testing.T) {
+		for _, timeout := range []string{"soon", "-1s", "0s"} {
+			if rr := put(context.Background(), "a", timeout); rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400 for %q, got %d", timeout, rr.Code)
+			}
+		}
+	})
+}
+
+func TestParseTimeouts(t *testing.T) {
+	t.Run("Operations not named keep their defaults", func(t *testing.T) {
+		timeouts, err := client.ParseTimeouts("Get=1s, Scan=0s")
+		if err != nil {
+			t.Fatalf("ParseTimeouts failed: %v", err)
+		}
+
+		want := map[string]time.Duration{
+			"Get":           time.Second,
+			"Scan":          0,
+			"Set":           client.DefaultTimeout,
+			"DropNamespace": time.Minute,
+			"BulkLoad":      0,
+		}
+		for op, timeout := range want {
+			if got := timeouts.For(op); got != timeout {
+				t.Errorf("Expected %s for %s, got %s", timeout, op, got)
+			}
+		}
+	})
+
+	t.Run("Try invalid timeouts", func(t *testing.T) {
+		for _, s := range []string{"Get", "Fetch=1s", "Get=soon", "Get=-1s"} {
+			if _, err := client.ParseTimeouts(s); err == nil {
+				t.Errorf("Expected an error for %q", s)
+			}
+		}
+	})
+}
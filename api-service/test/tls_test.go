@@ -0,0 +1,132 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/schemas/certs"
+)
+
+// issueCertificates writes a throwaway CA, and a server and a client
+// certificate for localhost it signed, to dir.
+func issueCertificates(t *testing.T, dir string) (ca string, server, client [2]string) {
+	t.Helper()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "api-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	write := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	issue := func(name string, usage x509.ExtKeyUsage) [2]string {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			DNSNames:     []string{"localhost"},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("Failed to issue certificate: %v", err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+
+		return [2]string{write(name+".crt", "CERTIFICATE", der), write(name+".key", "EC PRIVATE KEY", keyDER)}
+	}
+
+	return write("ca.crt", "CERTIFICATE", caDER), issue("server", x509.ExtKeyUsageServerAuth), issue("client", x509.ExtKeyUsageClientAuth)
+}
+
+func TestHTTPS(t *testing.T) {
+	ca, serverPair, clientPair := issueCertificates(t, t.TempDir())
+
+	serverConfig, err := certs.ServerConfig(certs.Options{
+		CertFile:          serverPair[0],
+		KeyFile:           serverPair[1],
+		CAFile:            ca,
+		RequireClientCert: true,
+	})
+	if err != nil {
+		t.Fatalf("ServerConfig failed: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	srv := &http.Server{Handler: setupRouter(), TLSConfig: serverConfig}
+	go srv.ServeTLS(lis, "", "")
+	defer srv.Close()
+
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	url := "https://localhost:" + port + "/health"
+
+	get := func(opts certs.Options) (*http.Response, error) {
+		config, err := certs.ClientConfig(opts)
+		if err != nil {
+			t.Fatalf("ClientConfig failed: %v", err)
+		}
+
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: config}, Timeout: 5 * time.Second}
+		return httpClient.Get(url)
+	}
+
+	t.Run("Serve HTTPS to clients with a certificate", func(t *testing.T) {
+		resp, err := get(certs.Options{CertFile: clientPair[0], KeyFile: clientPair[1], CAFile: ca})
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK || resp.TLS == nil {
+			t.Errorf("Expected 200 over TLS, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Try connecting without a client certificate", func(t *testing.T) {
+		if resp, err := get(certs.Options{CAFile: ca}); err == nil {
+			resp.Body.Close()
+			t.Error("Expected the handshake to fail")
+		}
+	})
+
+	t.Run("Try dialing with half a client certificate", func(t *testing.T) {
+		if _, err := client.WithTLS(certs.Options{CertFile: clientPair[0], CAFile: ca}); err == nil {
+			t.Error("Expected a certificate without its key to be rejected")
+		}
+	})
+}
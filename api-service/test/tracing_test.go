@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"GRPC-KV-Store-System/api-service/internal/client"
+	"GRPC-KV-Store-System/api-service/internal/handler"
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+	"GRPC-KV-Store-System/schemas/tracing"
+)
+
+// traceparentServer answers Set and remembers the traceparent it was sent.
+type traceparentServer struct {
+	pb.UnimplementedKeyValueStoreServer
+	traceparent chan string
+}
+
+func (s *traceparentServer) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.traceparent <- strings.Join(md.Get("traceparent"), ",")
+	return &pb.SetResponse{Version: 1}, nil
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	backend := &traceparentServer{traceparent: make(chan string, 1)}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	pb.RegisterKeyValueStoreServer(grpcServer, backend)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	kvClient, err := client.StartClient(lis.Addr().String())
+	if err != nil {
+		t.Fatalf("StartClient failed: %v", err)
+	}
+	defer kvClient.Close()
+
+	validator, err := middleware.StartValidator("../../schemas/rest/openapi.yaml")
+	if err != nil {
+		t.Fatalf("StartValidator failed: %v", err)
+	}
+
+	router := routes(handler.StartHandler(kvClient))
+	server := middleware.StartTracing(router).Trace(validator.Validate(router))
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	t.Run("Trace context flows from REST to gRPC", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "http://localhost:8080/kv/a", strings.NewReader(`{"value": "1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+		rr := httptest.NewRecorder()
+
+		server.ServeHTTP(rr, req)
+		if rr.Code >= 300 {
+			t.Fatalf("Expected success, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		if traceparent := <-backend.traceparent; !strings.Contains(traceparent, traceID) {
+			t.Errorf("Expected the backend to be sent trace %s, got %q", traceID, traceparent)
+		}
+
+		spans := make(map[string]sdktrace.ReadOnlySpan)
+		for _, span := range recorder.Ended() {
+			spans[span.Name()] = span
+		}
+
+		root, ok := spans["PUT /kv/{key}"]
+		if !ok {
+			t.Fatalf("Expected a span for the route, got %v", spans)
+		}
+		if root.SpanContext().TraceID().String() != traceID || root.Parent().SpanID().String() != "00f067aa0ba902b7" {
+			t.Errorf("Expected the route span to continue the caller's trace, got %s", root.SpanContext().TraceID())
+		}
+		for _, attr := range root.Attributes() {
+			if strings.Contains(attr.Value.Emit(), "/kv/a") {
+				t.Errorf("Expected the route span not to carry the key, got %s=%s", attr.Key, attr.Value.Emit())
+			}
+		}
+
+		for _, name := range []string{"validate request", "kvstore.KeyValueStore/Set"} {
+			span, ok := spans[name]
+			if !ok {
+				t.Errorf("Expected a %q span, got %v", name, spans)
+				continue
+			}
+			if span.Parent().SpanID() != root.SpanContext().SpanID() {
+				t.Errorf("Expected %q to be a child of the route span", name)
+			}
+		}
+	})
+
+	t.Run("Spans are written to a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "spans.json")
+		provider, err := tracing.StartProvider(tracing.Options{ServiceName: "api-test", Exporter: tracing.File, File: path, SampleRatio: 1})
+		if err != nil {
+			t.Fatalf("StartProvider failed: %v", err)
+		}
+
+		_, span := provider.Tracer("test").Start(context.Background(), "offline span")
+		span.End()
+		if err := provider.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown failed: %v", err)
+		}
+
+		data, _ := os.ReadFile(path)
+		if !strings.Contains(string(data), `"Name":"offline span"`) || !strings.Contains(string(data), "api-test") {
+			t.Errorf("Expected the span in the file, got:\n%s", data)
+		}
+	})
+
+	t.Run("Try an unknown exporter", func(t *testing.T) {
+		if _, err := tracing.StartProvider(tracing.Options{Exporter: "zipkin"}); err == nil {
+			t.Error("Expected an error")
+		}
+		if _, err := tracing.StartProvider(tracing.Options{Exporter: tracing.File}); err == nil {
+			t.Error("Expected the file exporter to need a file")
+		}
+	})
+}
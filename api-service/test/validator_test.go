@@ -0,0 +1,53 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"GRPC-KV-Store-System/api-service/internal/middleware"
+)
+
+// readCounter counts the bytes read from a request body.
+type readCounter struct {
+	io.Reader
+	n int
+}
+
+func (c *readCounter) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestValidatorStreamsBodies(t *testing.T) {
+	validator, err := middleware.StartValidator("../../schemas/rest/openapi.yaml")
+	if err != nil {
+		t.Fatalf("StartValidator failed: %v", err)
+	}
+
+	for _, tc := range []struct{ method, path, contentType, body string }{
+		{"POST", "/kv/batch", "application/x-ndjson", `{"key":"a","value":"1"}` + "\n"},
+		{"PUT", "/kv/blob", "application/octet-stream", "raw value"},
+	} {
+		body := &readCounter{Reader: strings.NewReader(tc.body)}
+
+		// The handler must get the body before anything has read it.
+		readBefore := -1
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			readBefore = body.n
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(tc.method, "http://localhost:8080"+tc.path, body)
+		req.Header.Set("Content-Type", tc.contentType)
+		rr := httptest.NewRecorder()
+
+		validator.Validate(next).ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK || readBefore != 0 {
+			t.Errorf("%s: expected the body to reach the handler unread, got %d after %d bytes were read", tc.contentType, rr.Code, readBefore)
+		}
+	}
+}
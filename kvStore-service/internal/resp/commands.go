@@ -0,0 +1,514 @@
+package resp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+)
+
+// maxSeconds is the longest ttl a time.Duration holds, in seconds.
+const maxSeconds = math.MaxInt64 / int64(time.Second)
+
+type command struct {
+	// arity is the number of arguments including the command name when
+	// positive, and the minimum number when negative, as in Redis.
+	arity int
+	run   func(s *Server, c *conn, args [][]byte)
+}
+
+var commands = map[string]command{
+	"ping":    {-1, (*Server).ping},
+	"echo":    {2, (*Server).echo},
+	"hello":   {-1, (*Server).hello},
+	"client":  {-2, (*Server).client},
+	"select":  {2, (*Server).selectDB},
+	"command": {-1, (*Server).command},
+	"quit":    {-1, (*Server).quit},
+
+	"get":    {2, (*Server).get},
+	"set":    {-3, (*Server).set},
+	"del":    {-2, (*Server).del},
+	"exists": {-2, (*Server).exists},
+	"expire": {3, (*Server).expire},
+	"ttl":    {2, (*Server).ttl},
+	"keys":   {2, (*Server).keys},
+	"scan":   {-2, (*Server).scan},
+	"incr":   {2, (*Server).incr},
+	"decr":   {2, (*Server).incr},
+	"incrby": {3, (*Server).incr},
+	"decrby": {3, (*Server).incr},
+	"mget":   {-2, (*Server).mget},
+	"mset":   {-3, (*Server).mset},
+}
+
+func (s *Server) dispatch(c *conn, args [][]byte) {
+	name := strings.ToLower(string(args[0]))
+
+	cmd, ok := commands[name]
+	if !ok {
+		var start strings.Builder
+		for _, arg := range args[1:] {
+			fmt.Fprintf(&start, "'%s' ", truncate(arg))
+		}
+		c.w.error(fmt.Sprintf("ERR unknown command '%s', with args beginning with: %s", truncate(args[0]), start.String()))
+		return
+	}
+
+	if (cmd.arity > 0 && len(args) != cmd.arity) || len(args) < -cmd.arity {
+		c.w.error(fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+		return
+	}
+
+	cmd.run(s, c, args)
+}
+
+// storeError replies with the Redis error closest to a store error.
+func storeError(c *conn, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotInteger):
+		c.w.error("ERR value is not an integer or out of range")
+	case errors.Is(err, store.ErrOutOfRange):
+		c.w.error("ERR increment or decrement would overflow")
+	case errors.Is(err, store.ErrMemoryLimit):
+		c.w.error("OOM command not allowed when used memory > 'maxmemory'.")
+	case errors.Is(err, store.ErrNotLeader):
+		c.w.error("READONLY You can't write against a read only replica.")
+	default:
+		c.w.error("ERR " + err.Error())
+	}
+}
+
+func (s *Server) ping(c *conn, args [][]byte) {
+	switch len(args) {
+	case 1:
+		c.w.simple("PONG")
+	case 2:
+		c.w.bulk(string(args[1]))
+	default:
+		c.w.error("ERR wrong number of arguments for 'ping' command")
+	}
+}
+
+func (s *Server) echo(c *conn, args [][]byte) {
+	c.w.bulk(string(args[1]))
+}
+
+// hello switches the protocol version, HELLO [protover [AUTH user pass]
+// [SETNAME name]], and describes the server. Without a password to check,
+// AUTH is accepted as Redis does for a user without one.
+func (s *Server) hello(c *conn, args [][]byte) {
+	proto := c.w.proto
+	if len(args) > 1 {
+		n, err := strconv.Atoi(string(args[1]))
+		if err != nil {
+			c.w.error("ERR Protocol version is not an integer or out of range")
+			return
+		}
+		if n != 2 && n != 3 {
+			c.w.error("NOPROTO unsupported protocol version")
+			return
+		}
+		proto = n
+	}
+
+	for n := 2; n < len(args); n++ {
+		switch opt := strings.ToLower(string(args[n])); {
+		case opt == "auth" && n+2 < len(args):
+			n += 2
+		case opt == "setname" && n+1 < len(args):
+			c.name = string(args[n+1])
+			n++
+		default:
+			c.w.error(fmt.Sprintf("ERR Syntax error in HELLO option '%s'", truncate(args[n])))
+			return
+		}
+	}
+
+	c.w.proto = proto
+
+	c.w.mapHeader(7)
+	c.w.bulk("server")
+	c.w.bulk("kvstore")
+	c.w.bulk("version")
+	c.w.bulk("1.0.0")
+	c.w.bulk("proto")
+	c.w.integer(int64(proto))
+	c.w.bulk("id")
+	c.w.integer(c.id)
+	c.w.bulk("mode")
+	c.w.bulk("standalone")
+	c.w.bulk("role")
+	c.w.bulk("master")
+	c.w.bulk("modules")
+	c.w.array(0)
+}
+
+// client answers the CLIENT subcommands libraries send when they connect.
+func (s *Server) client(c *conn, args [][]byte) {
+	switch sub := strings.ToLower(string(args[1])); {
+	case sub == "setname" && len(args) == 3:
+		c.name = string(args[2])
+		c.w.simple("OK")
+	case sub == "getname" && len(args) == 2:
+		if c.name == "" {
+			c.w.null()
+			return
+		}
+		c.w.bulk(c.name)
+	case sub == "id" && len(args) == 2:
+		c.w.integer(c.id)
+	case sub == "setinfo" && len(args) == 4:
+		c.w.simple("OK")
+	default:
+		c.w.error(fmt.Sprintf("ERR unknown subcommand or wrong number of arguments for '%s'", truncate(args[1])))
+	}
+}
+
+// selectDB only accepts database 0, the only one there is.
+func (s *Server) selectDB(c *conn, args [][]byte) {
+	if string(args[1]) != "0" {
+		c.w.error("ERR DB index is out of range")
+		return
+	}
+	c.w.simple("OK")
+}
+
+// command describes no commands. Clients such as redis-cli only use it
+// for hints.
+func (s *Server) command(c *conn, args [][]byte) {
+	c.w.array(0)
+}
+
+func (s *Server) quit(c *conn, args [][]byte) {
+	c.w.simple("OK")
+	c.quit = true
+}
+
+func (s *Server) get(c *conn, args [][]byte) {
+	value, err := s.store.Get(string(args[1]))
+	if errors.Is(err, store.ErrKeyNotFound) {
+		c.w.null()
+		return
+	}
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	c.w.bulk(value)
+}
+
+type setOptions struct {
+	nx, xx, get, keepTTL bool
+	ttl                  time.Duration
+}
+
+func parseSetOptions(args [][]byte) (setOptions, string) {
+	var opts setOptions
+	expiry := false
+
+	for n := 0; n < len(args); n++ {
+		opt := strings.ToLower(string(args[n]))
+		switch opt {
+		case "nx":
+			opts.nx = true
+		case "xx":
+			opts.xx = true
+		case "get":
+			opts.get = true
+		case "keepttl":
+			opts.keepTTL = true
+		case "ex", "px", "exat", "pxat":
+			if expiry || n+1 == len(args) {
+				return opts, "ERR syntax error"
+			}
+			expiry = true
+
+			n++
+			amount, err := strconv.ParseInt(string(args[n]), 10, 64)
+			if err != nil {
+				return opts, "ERR value is not an integer or out of range"
+			}
+
+			switch opt {
+			case "ex":
+				opts.ttl = time.Duration(min(amount, maxSeconds)) * time.Second
+			case "px":
+				opts.ttl = time.Duration(min(amount, maxSeconds*1000)) * time.Millisecond
+			case "exat":
+				opts.ttl = time.Until(time.Unix(amount, 0))
+			case "pxat":
+				opts.ttl = time.Until(time.UnixMilli(amount))
+			}
+
+			if opts.ttl <= 0 {
+				return opts, "ERR invalid expire time in 'set' command"
+			}
+		default:
+			return opts, "ERR syntax error"
+		}
+	}
+
+	if (opts.nx && opts.xx) || (expiry && opts.keepTTL) {
+		return opts, "ERR syntax error"
+	}
+
+	return opts, ""
+}
+
+// set runs SET key value [NX | XX] [GET] [EX | PX | EXAT | PXAT | KEEPTTL].
+func (s *Server) set(c *conn, args [][]byte) {
+	key, value := string(args[1]), string(args[2])
+
+	opts, syntaxErr := parseSetOptions(args[3:])
+	if syntaxErr != "" {
+		c.w.error(syntaxErr)
+		return
+	}
+
+	if !opts.nx && !opts.xx && !opts.get && !opts.keepTTL {
+		var err error
+		if opts.ttl > 0 {
+			_, err = s.store.SetWithTTL(key, value, opts.ttl)
+		} else {
+			_, err = s.store.Set(key, value)
+		}
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+
+		c.w.simple("OK")
+		return
+	}
+
+	// The conditional forms swap on the version read, retrying if the key
+	// changes in between.
+	for {
+		old, version, err := s.store.GetWithVersion(key)
+		exists := err == nil
+		if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+			storeError(c, err)
+			return
+		}
+
+		if (opts.nx && exists) || (opts.xx && !exists) {
+			if opts.get && exists {
+				c.w.bulk(old)
+				return
+			}
+			c.w.null()
+			return
+		}
+
+		ttl := opts.ttl
+		if opts.keepTTL && exists {
+			if remaining, err := s.store.TTL(key); err == nil && remaining > 0 {
+				ttl = remaining
+			}
+		}
+
+		_, err = s.store.CompareAndSwap(key, store.Condition{Version: version}, value, ttl)
+		if errors.Is(err, store.ErrVersionMismatch) {
+			continue
+		}
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+
+		switch {
+		case !opts.get:
+			c.w.simple("OK")
+		case exists:
+			c.w.bulk(old)
+		default:
+			c.w.null()
+		}
+		return
+	}
+}
+
+func (s *Server) del(c *conn, args [][]byte) {
+	var deleted int64
+	for _, key := range args[1:] {
+		err := s.store.Delete(string(key))
+		if errors.Is(err, store.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+		deleted++
+	}
+
+	c.w.integer(deleted)
+}
+
+// exists counts the keys that exist, once for each time they are named.
+func (s *Server) exists(c *conn, args [][]byte) {
+	var found int64
+	for _, key := range args[1:] {
+		_, _, err := s.store.GetWithVersion(string(key))
+		if errors.Is(err, store.ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+		found++
+	}
+
+	c.w.integer(found)
+}
+
+// expire sets a key's ttl by rewriting its value with one, so the key
+// gets a new version. A ttl that is not positive deletes the key.
+func (s *Server) expire(c *conn, args [][]byte) {
+	key := string(args[1])
+
+	seconds, err := strconv.ParseInt(string(args[2]), 10, 64)
+	if err != nil {
+		c.w.error("ERR value is not an integer or out of range")
+		return
+	}
+	seconds = min(seconds, maxSeconds)
+
+	if seconds <= 0 {
+		err := s.store.Delete(key)
+		if errors.Is(err, store.ErrKeyNotFound) {
+			c.w.integer(0)
+			return
+		}
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+		c.w.integer(1)
+		return
+	}
+
+	for {
+		value, version, err := s.store.GetWithVersion(key)
+		if errors.Is(err, store.ErrKeyNotFound) {
+			c.w.integer(0)
+			return
+		}
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+
+		_, err = s.store.CompareAndSwap(key, store.Condition{Version: version}, value, time.Duration(seconds)*time.Second)
+		if errors.Is(err, store.ErrVersionMismatch) {
+			continue
+		}
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+
+		c.w.integer(1)
+		return
+	}
+}
+
+// ttl replies with the seconds a key has left, -1 if it never expires and
+// -2 if it does not exist.
+func (s *Server) ttl(c *conn, args [][]byte) {
+	ttl, err := s.store.TTL(string(args[1]))
+	switch {
+	case errors.Is(err, store.ErrKeyNotFound):
+		c.w.integer(-2)
+	case err != nil:
+		storeError(c, err)
+	case ttl == store.NoExpiry:
+		c.w.integer(-1)
+	default:
+		c.w.integer(int64((ttl + time.Second/2) / time.Second))
+	}
+}
+
+// incr runs INCR, DECR, INCRBY and DECRBY.
+func (s *Server) incr(c *conn, args [][]byte) {
+	name := strings.ToLower(string(args[0]))
+
+	delta := int64(1)
+	if len(args) == 3 {
+		var err error
+		delta, err = strconv.ParseInt(string(args[2]), 10, 64)
+		if err != nil {
+			c.w.error("ERR value is not an integer or out of range")
+			return
+		}
+	}
+
+	if strings.HasPrefix(name, "decr") {
+		if delta == math.MinInt64 {
+			c.w.error("ERR decrement would overflow")
+			return
+		}
+		delta = -delta
+	}
+
+	value, _, err := s.store.Increment(string(args[1]), delta, store.CounterOptions{})
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	c.w.integer(value)
+}
+
+func (s *Server) mget(c *conn, args [][]byte) {
+	values := make([]*string, 0, len(args)-1)
+	for _, key := range args[1:] {
+		value, err := s.store.Get(string(key))
+		if errors.Is(err, store.ErrKeyNotFound) {
+			values = append(values, nil)
+			continue
+		}
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+		values = append(values, &value)
+	}
+
+	c.w.array(len(values))
+	for _, value := range values {
+		if value == nil {
+			c.w.null()
+			continue
+		}
+		c.w.bulk(*value)
+	}
+}
+
+// mset writes every pair in one transaction, so they are set atomically as
+// in Redis.
+func (s *Server) mset(c *conn, args [][]byte) {
+	if len(args)%2 != 1 {
+		c.w.error("ERR wrong number of arguments for 'mset' command")
+		return
+	}
+
+	var txn store.Txn
+	for n := 1; n < len(args); n += 2 {
+		txn.Success = append(txn.Success, store.TxnOp{Type: store.TxnPut, Key: string(args[n]), Value: string(args[n+1])})
+	}
+
+	if _, err := s.store.Txn(txn); err != nil {
+		storeError(c, err)
+		return
+	}
+
+	c.w.simple("OK")
+}
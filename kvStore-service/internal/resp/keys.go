@@ -0,0 +1,257 @@
+package resp
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+)
+
+const (
+	// keysPage is how many keys KEYS reads from the store at a time.
+	keysPage = 1000
+	// defaultScanCount is the page size of a SCAN without COUNT.
+	defaultScanCount = 10
+	// maxCursors is how many SCAN cursors are remembered. Older ones fail
+	// as invalid.
+	maxCursors = 1 << 16
+)
+
+// cursors maps the numeric SCAN cursors Redis clients expect to the key
+// the next page starts at. They are shared by every connection, since
+// clients with a pool of connections may continue a scan on another one.
+type cursors struct {
+	mu     sync.Mutex
+	last   uint64
+	starts map[uint64]string
+}
+
+func newCursors() *cursors {
+	return &cursors{starts: make(map[uint64]string)}
+}
+
+// add returns a new cursor that resumes at start, forgetting the oldest
+// once there are maxCursors.
+func (c *cursors) add(start string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.last++
+	c.starts[c.last] = start
+	delete(c.starts, c.last-maxCursors)
+
+	return c.last
+}
+
+func (c *cursors) start(cursor uint64) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	start, ok := c.starts[cursor]
+	return start, ok
+}
+
+// keys lists every key matching a glob pattern.
+func (s *Server) keys(c *conn, args [][]byte) {
+	pattern := string(args[1])
+
+	var matched []string
+	opts := store.ScanOptions{Prefix: literalPrefix(pattern), Limit: keysPage}
+	for {
+		items, more, err := s.store.Scan(opts)
+		if err != nil {
+			storeError(c, err)
+			return
+		}
+
+		for _, item := range items {
+			if match(pattern, item.Key) {
+				matched = append(matched, item.Key)
+			}
+		}
+
+		if !more {
+			break
+		}
+		opts.Start = items[len(items)-1].Key + "\x00"
+	}
+
+	c.w.array(len(matched))
+	for _, key := range matched {
+		c.w.bulk(key)
+	}
+}
+
+// scan runs SCAN cursor [MATCH pattern] [COUNT count] [TYPE type]. As in
+// Redis, COUNT is how many keys to look at, so a page with MATCH may hold
+// fewer. Every value is a string, so other types match nothing.
+func (s *Server) scan(c *conn, args [][]byte) {
+	cursor, err := strconv.ParseUint(string(args[1]), 10, 64)
+	if err != nil {
+		c.w.error("ERR invalid cursor")
+		return
+	}
+
+	pattern := "*"
+	count := defaultScanCount
+	typ := "string"
+	for n := 2; n < len(args); n += 2 {
+		if n+1 == len(args) {
+			c.w.error("ERR syntax error")
+			return
+		}
+
+		switch value := string(args[n+1]); strings.ToLower(string(args[n])) {
+		case "match":
+			pattern = value
+		case "count":
+			count, err = strconv.Atoi(value)
+			if err != nil || count < 1 {
+				c.w.error("ERR value is not an integer or out of range")
+				return
+			}
+		case "type":
+			typ = strings.ToLower(value)
+		default:
+			c.w.error("ERR syntax error")
+			return
+		}
+	}
+
+	opts := store.ScanOptions{Prefix: literalPrefix(pattern), Limit: count}
+	if cursor != 0 {
+		start, ok := s.cursors.start(cursor)
+		if !ok {
+			c.w.error("ERR invalid cursor")
+			return
+		}
+		opts.Start = start
+	}
+
+	items, more, err := s.store.Scan(opts)
+	if err != nil {
+		storeError(c, err)
+		return
+	}
+
+	var matched []string
+	for _, item := range items {
+		if typ == "string" && match(pattern, item.Key) {
+			matched = append(matched, item.Key)
+		}
+	}
+
+	next := uint64(0)
+	if more {
+		next = s.cursors.add(items[len(items)-1].Key + "\x00")
+	}
+
+	c.w.array(2)
+	c.w.bulk(strconv.FormatUint(next, 10))
+	c.w.array(len(matched))
+	for _, key := range matched {
+		c.w.bulk(key)
+	}
+}
+
+// literalPrefix returns the part of a glob pattern before its first
+// special character, which every matching key starts with.
+func literalPrefix(pattern string) string {
+	if n := strings.IndexAny(pattern, `*?[\`); n >= 0 {
+		return pattern[:n]
+	}
+	return pattern
+}
+
+// match reports whether s matches a Redis glob pattern: * matches any
+// run of bytes, ? any one byte, [abc], [a-z] and [^a] a byte in or out of a
+// set, and \ escapes the byte after it.
+//
+// On a mismatch only the last * is tried again, one byte further into s:
+// whatever an earlier * could take, the last one can take as well. So
+// matching takes at most len(pattern) * len(s) steps, however many stars
+// the pattern has.
+func match(pattern, s string) bool {
+	var starPattern, starS string
+	star := false
+
+	for {
+		switch {
+		case pattern != "" && pattern[0] == '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			if pattern == "" {
+				return true
+			}
+			starPattern, starS, star = pattern, s, true
+			continue
+		case pattern != "" && s != "":
+			if rest, ok := matchByte(pattern, s[0]); ok {
+				pattern, s = rest, s[1:]
+				continue
+			}
+		case pattern == "" && s == "":
+			return true
+		}
+
+		if !star || starS == "" {
+			return false
+		}
+		starS = starS[1:]
+		pattern, s = starPattern, starS
+	}
+}
+
+// matchByte matches b against the first element of pattern, which is not
+// a *, and returns the pattern after that element.
+func matchByte(pattern string, b byte) (string, bool) {
+	switch pattern[0] {
+	case '?':
+		return pattern[1:], true
+	case '[':
+		rest, ok := matchClass(pattern[1:], b)
+		return rest[1:], ok
+	case '\\':
+		if len(pattern) > 1 {
+			pattern = pattern[1:]
+		}
+	}
+
+	return pattern[1:], pattern[0] == b
+}
+
+// matchClass matches b against the set that starts pattern, just after its
+// '['. It returns pattern from the closing ']' on, so the caller can step
+// past it. A set with no closing ']' runs to the end of the pattern.
+func matchClass(pattern string, b byte) (string, bool) {
+	negate := strings.HasPrefix(pattern, "^")
+	if negate {
+		pattern = pattern[1:]
+	}
+
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) > 1:
+			pattern = pattern[1:]
+			matched = matched || pattern[0] == b
+		case len(pattern) > 2 && pattern[1] == '-' && pattern[2] != ']':
+			lo, hi := pattern[0], pattern[2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			matched = matched || (b >= lo && b <= hi)
+			pattern = pattern[2:]
+		default:
+			matched = matched || pattern[0] == b
+		}
+		pattern = pattern[1:]
+	}
+
+	if pattern == "" {
+		// Keep a byte for the caller to step past.
+		pattern = "]"
+	}
+
+	return pattern, matched != negate
+}
@@ -0,0 +1,229 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync/atomic"
+)
+
+const (
+	// maxBulkLen caps a single argument, as Redis's proto-max-bulk-len does.
+	maxBulkLen = 512 << 20
+	// maxArgs caps the arguments of one command.
+	maxArgs = 1 << 20
+	// maxInlineLen caps a command typed as a plain line of text.
+	maxInlineLen = 64 << 10
+	// bulkChunk is how much of a bulk string is read, and allocated, at a
+	// time.
+	bulkChunk = 64 << 10
+)
+
+// ProtocolError is a request that does not follow RESP, or that is too
+// big to read. The connection it came in on cannot be read any further.
+type ProtocolError struct {
+	Reason string
+}
+
+func (e *ProtocolError) Error() string {
+	return "Protocol error: " + e.Reason
+}
+
+// reader reads the commands a client sends: arrays of bulk strings, or
+// inline commands typed into a terminal such as telnet.
+type reader struct {
+	br *bufio.Reader
+	// budget is shared by every connection, and held is what this one has
+	// taken from it for the command being read and run.
+	budget *budget
+	held   int64
+}
+
+// budget caps the bytes of the bulk strings being read and run across
+// every connection.
+type budget struct {
+	max  int64
+	used atomic.Int64
+}
+
+// take reserves n bytes, reporting false, and reserving nothing, when that
+// would go over max. A zero max is no cap.
+func (b *budget) take(n int64) bool {
+	if b.used.Add(n) > b.max && b.max > 0 {
+		b.used.Add(-n)
+		return false
+	}
+	return true
+}
+
+// release returns what the reader holds once its command has run.
+func (r *reader) release() {
+	r.budget.used.Add(-r.held)
+	r.held = 0
+}
+
+// readCommand returns the arguments of the next command. It returns no
+// arguments for an empty line, which clients may send to keep alive.
+func (r *reader) readCommand() ([][]byte, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 || line[0] != '*' {
+		return bytes.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n > maxArgs {
+		return nil, &ProtocolError{Reason: "invalid multibulk length"}
+	}
+
+	// Like bulk strings, the arguments are only made room for as they
+	// arrive.
+	var args [][]byte
+	for range n {
+		arg, err := r.readBulk()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
+
+func (r *reader) readBulk() ([]byte, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 || line[0] != '$' {
+		return nil, &ProtocolError{Reason: fmt.Sprintf("expected '$', got '%s'", truncate(line))}
+	}
+
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n < 0 || n > maxBulkLen {
+		return nil, &ProtocolError{Reason: "invalid bulk length"}
+	}
+
+	// The string is read, and taken from the budget, as it arrives, so a
+	// length without the data behind it takes up no memory.
+	arg := make([]byte, 0, min(n+2, bulkChunk))
+	for len(arg) < n+2 {
+		chunk := min(n+2-len(arg), bulkChunk)
+		if !r.budget.take(int64(chunk)) {
+			return nil, &ProtocolError{Reason: "too much data in flight, try again later"}
+		}
+		r.held += int64(chunk)
+
+		arg = slices.Grow(arg, chunk)
+		read, err := io.ReadFull(r.br, arg[len(arg):len(arg)+chunk])
+		arg = arg[:len(arg)+read]
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if arg[n] != '\r' || arg[n+1] != '\n' {
+		return nil, &ProtocolError{Reason: "bulk string is not terminated by CRLF"}
+	}
+
+	return arg[:n], nil
+}
+
+// readLine reads up to a newline, dropping it and any carriage return
+// before it.
+func (r *reader) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.br.ReadSlice('\n')
+		line = append(line, chunk...)
+
+		if len(line) > maxInlineLen {
+			return nil, &ProtocolError{Reason: "too big inline request"}
+		}
+
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, err
+		}
+	}
+
+	line = bytes.TrimSuffix(line[:len(line)-1], []byte("\r"))
+	return line, nil
+}
+
+func truncate(b []byte) string {
+	if len(b) > 32 {
+		return string(b[:32]) + "..."
+	}
+	return string(b)
+}
+
+// writer writes replies in the protocol version the client chose with
+// HELLO, which only changes how nulls and maps are sent.
+type writer struct {
+	bw    *bufio.Writer
+	proto int
+}
+
+func (w *writer) simple(s string) {
+	w.bw.WriteByte('+')
+	w.bw.WriteString(s)
+	w.bw.WriteString("\r\n")
+}
+
+// error writes an error reply. Its first word is the error code, such as
+// ERR or WRONGTYPE.
+func (w *writer) error(s string) {
+	w.bw.WriteByte('-')
+	w.bw.WriteString(s)
+	w.bw.WriteString("\r\n")
+}
+
+func (w *writer) integer(n int64) {
+	w.header(':', n)
+}
+
+func (w *writer) bulk(s string) {
+	w.header('$', int64(len(s)))
+	w.bw.WriteString(s)
+	w.bw.WriteString("\r\n")
+}
+
+func (w *writer) null() {
+	if w.proto == 3 {
+		w.bw.WriteString("_\r\n")
+		return
+	}
+	w.bw.WriteString("$-1\r\n")
+}
+
+// array starts an array of n replies, which the caller writes next.
+func (w *writer) array(n int) {
+	w.header('*', int64(n))
+}
+
+// mapHeader starts a map of n key and value pairs. RESP2 has no maps, so
+// they are sent as flat arrays.
+func (w *writer) mapHeader(n int) {
+	if w.proto == 3 {
+		w.header('%', int64(n))
+		return
+	}
+	w.header('*', int64(2*n))
+}
+
+func (w *writer) header(kind byte, n int64) {
+	w.bw.WriteByte(kind)
+	w.bw.Write(strconv.AppendInt(w.bw.AvailableBuffer(), n, 10))
+	w.bw.WriteString("\r\n")
+}
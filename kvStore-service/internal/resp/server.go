@@ -0,0 +1,183 @@
+// Package resp serves a store over the Redis protocol (RESP2 and RESP3), so
+// redis-cli and Redis client libraries can use it unchanged.
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+)
+
+// Limits bound what clients can make a Server wait for and hold on to.
+type Limits struct {
+	// ReadTimeout disconnects clients that take longer to send a command,
+	// idle ones included. Zero means no timeout.
+	ReadTimeout time.Duration
+	// MaxInflightBytes caps the bytes of the arguments being read and run
+	// across every connection. Zero means no cap.
+	MaxInflightBytes int64
+}
+
+// DefaultLimits lets clients idle for five minutes and the arguments of
+// commands in flight take up a gigabyte.
+func DefaultLimits() Limits {
+	return Limits{ReadTimeout: 5 * time.Minute, MaxInflightBytes: 1 << 30}
+}
+
+// Server maps a subset of Redis commands onto a store.Store. Commands go
+// straight to the store, with no access control or quotas.
+type Server struct {
+	store       store.Store
+	cursors     *cursors
+	nextID      atomic.Int64
+	readTimeout time.Duration
+	budget      *budget
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+func StartServer(kvStore store.Store) *Server {
+	limits := DefaultLimits()
+	return &Server{
+		store:       kvStore,
+		cursors:     newCursors(),
+		readTimeout: limits.ReadTimeout,
+		budget:      &budget{max: limits.MaxInflightBytes},
+		listeners:   make(map[net.Listener]struct{}),
+		conns:       make(map[net.Conn]struct{}),
+	}
+}
+
+// SetLimits replaces the default limits. It must be called before Serve.
+func (s *Server) SetLimits(limits Limits) {
+	s.readTimeout = limits.ReadTimeout
+	s.budget = &budget{max: limits.MaxInflightBytes}
+}
+
+// Serve accepts connections on lis until Close is called, when it returns
+// nil.
+func (s *Server) Serve(lis net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		lis.Close()
+		return nil
+	}
+	s.listeners[lis] = struct{}{}
+	s.mu.Unlock()
+
+	for {
+		nc, err := lis.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			nc.Close()
+			return nil
+		}
+		s.conns[nc] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go s.serveConn(nc)
+	}
+}
+
+// Close stops every listener and closes every connection, waiting for the
+// commands in flight to finish.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for lis := range s.listeners {
+		lis.Close()
+	}
+	for nc := range s.conns {
+		nc.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	return nil
+}
+
+// conn is the state of one client connection.
+type conn struct {
+	id int64
+	r  reader
+	w  writer
+
+	name string
+	quit bool
+}
+
+func (s *Server) serveConn(nc net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, nc)
+		s.mu.Unlock()
+
+		nc.Close()
+	}()
+
+	c := &conn{
+		id: s.nextID.Add(1),
+		r:  reader{br: bufio.NewReader(nc), budget: s.budget},
+		w:  writer{bw: bufio.NewWriter(nc), proto: 2},
+	}
+	defer c.r.release()
+
+	for !c.quit {
+		if s.readTimeout > 0 {
+			nc.SetReadDeadline(time.Now().Add(s.readTimeout))
+		}
+
+		args, err := c.r.readCommand()
+		if err != nil {
+			var protoErr *ProtocolError
+			if errors.As(err, &protoErr) {
+				c.w.error("ERR " + protoErr.Error())
+				c.w.bw.Flush()
+			} else if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) && !errors.Is(err, os.ErrDeadlineExceeded) {
+				slog.Warn("RESP connection failed", "remote", nc.RemoteAddr().String(), "error", err)
+			}
+			return
+		}
+
+		if len(args) > 0 {
+			s.dispatch(c, args)
+		}
+		c.r.release()
+
+		// Replies to pipelined commands go out together once every
+		// command that has arrived has run.
+		if c.r.br.Buffered() == 0 || c.quit {
+			if err := c.w.bw.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
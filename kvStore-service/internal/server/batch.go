@@ -0,0 +1,327 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+const (
+	// maxBatchItems caps the items of a single BatchSet, BatchGet or
+	// BatchDelete. Larger imports should use BulkLoad.
+	maxBatchItems = 1000
+
+	// bulkLoadChunk is how many BulkLoad items are committed together.
+	bulkLoadChunk = 500
+	// maxBulkLoadFailures caps the failures a BulkLoad reports.
+	maxBulkLoadFailures = 100
+)
+
+func (i *Server) BatchSet(ctx context.Context, req *pb.BatchSetRequest) (*pb.BatchResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: BatchSet", "items", len(req.Items))
+
+	if len(req.Items) > maxBatchItems {
+		return nil, status.Errorf(codes.InvalidArgument, "a batch cannot have more than %d items", maxBatchItems)
+	}
+
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.BatchResult, len(req.Items))
+	var ops []store.TxnOp
+	var positions []int
+
+	for n, item := range req.Items {
+		results[n] = &pb.BatchResult{Key: item.Key}
+
+		if err := validateSetItem(item); err != nil {
+			setItemError(results[n], err)
+			continue
+		}
+
+		if item.Namespace != "" && item.Namespace != req.Namespace {
+			setItemError(results[n], status.Error(codes.InvalidArgument, "item namespace does not match the batch"))
+			continue
+		}
+
+		ops = append(ops, setOp(item))
+		positions = append(positions, n)
+	}
+
+	if err := i.admit(req.Namespace, kv, len(ops), ops); err != nil {
+		return nil, err
+	}
+
+	applied, err := runBatch(kv, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, r := range applied {
+		results[positions[k]].Version = r.Version
+	}
+
+	slog.InfoContext(ctx, "Successfully stored keys", "stored", len(applied), "items", len(req.Items))
+
+	return &pb.BatchResponse{Results: results}, nil
+}
+
+func (i *Server) BatchGet(ctx context.Context, req *pb.BatchKeysRequest) (*pb.BatchResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: BatchGet", "keys", len(req.Keys))
+
+	results, ops, positions, err := batchKeys(req.Keys, store.TxnGet)
+	if err != nil {
+		return nil, err
+	}
+
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.admit(req.Namespace, kv, len(ops), nil); err != nil {
+		return nil, err
+	}
+
+	// Running the reads as one transaction gives them a consistent view.
+	applied, err := runBatch(kv, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	found := 0
+	for k, r := range applied {
+		result := results[positions[k]]
+		if !r.Found {
+			setItemError(result, status.Error(codes.NotFound, "key not found"))
+			continue
+		}
+
+		result.Value, result.ValueBytes = responseValue(r.Value)
+		result.Version = r.Version
+		found++
+	}
+
+	slog.InfoContext(ctx, "Successfully retrieved keys", "found", found, "keys", len(req.Keys))
+
+	return &pb.BatchResponse{Results: results}, nil
+}
+
+func (i *Server) BatchDelete(ctx context.Context, req *pb.BatchKeysRequest) (*pb.BatchResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: BatchDelete", "keys", len(req.Keys))
+
+	results, ops, positions, err := batchKeys(req.Keys, store.TxnDelete)
+	if err != nil {
+		return nil, err
+	}
+
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.admit(req.Namespace, kv, len(ops), nil); err != nil {
+		return nil, err
+	}
+
+	applied, err := runBatch(kv, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := 0
+	for k, r := range applied {
+		if !r.Found {
+			setItemError(results[positions[k]], status.Error(codes.NotFound, "key not found"))
+			continue
+		}
+		deleted++
+	}
+
+	slog.InfoContext(ctx, "Successfully deleted keys", "deleted", deleted, "keys", len(req.Keys))
+
+	return &pb.BatchResponse{Results: results}, nil
+}
+
+func (i *Server) BulkLoad(stream pb.KeyValueStore_BulkLoadServer) error {
+	slog.InfoContext(stream.Context(), "Processing Request: BulkLoad")
+
+	resp := &pb.BulkLoadResponse{}
+	var ops []store.TxnOp
+
+	// Items are committed to their own namespaces, so a chunk is flushed
+	// early when the namespace changes.
+	keyspaces := make(map[string]store.Store)
+	var namespace string
+	// pending is what the items not yet flushed add to the namespace.
+	var pending store.Usage
+
+	fail := func(index int64, key string, err error) {
+		resp.Failed++
+		if len(resp.Failures) < maxBulkLoadFailures {
+			st := status.Convert(err)
+			resp.Failures = append(resp.Failures, &pb.BulkLoadFailure{
+				Index: index,
+				Key:   key,
+				Code:  int32(st.Code()),
+				Error: st.Message(),
+			})
+		}
+	}
+
+	// A load over its namespace's rate quota is slowed down rather than
+	// failed.
+	flush := func() error {
+		if len(ops) == 0 {
+			return nil
+		}
+		if err := i.throttle(stream.Context(), namespace, len(ops)); err != nil {
+			return err
+		}
+		if _, err := runBatch(keyspaces[namespace], ops); err != nil {
+			return err
+		}
+		resp.Loaded += int64(len(ops))
+		ops = ops[:0]
+		pending = store.Usage{}
+		return nil
+	}
+
+	for index := int64(0); ; index++ {
+		item, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := validateSetItem(item); err != nil {
+			fail(index, item.Key, err)
+			continue
+		}
+
+		if _, ok := keyspaces[item.Namespace]; !ok {
+			kv, err := i.keyspace(stream.Context(), item.Namespace)
+			if err != nil {
+				fail(index, item.Key, err)
+				continue
+			}
+			keyspaces[item.Namespace] = kv
+		}
+
+		if item.Namespace != namespace {
+			if err := flush(); err != nil {
+				return err
+			}
+			namespace = item.Namespace
+		}
+
+		op := setOp(item)
+		grown, err := i.growth(namespace, keyspaces[namespace], []store.TxnOp{op}, pending)
+		if err != nil {
+			fail(index, item.Key, err)
+			continue
+		}
+		pending = grown
+
+		ops = append(ops, op)
+		if len(ops) == bulkLoadChunk {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	slog.InfoContext(stream.Context(), "Successfully bulk loaded keys", "loaded", resp.Loaded, "failed", resp.Failed)
+
+	return stream.SendAndClose(resp)
+}
+
+// runBatch runs ops on kv as a single transaction, so they take the store
+// lock and are journaled once.
+func runBatch(kv store.Store, ops []store.TxnOp) ([]store.TxnOpResult, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	result, err := kv.Txn(store.Txn{Success: ops})
+	if err != nil {
+		if errors.Is(err, store.ErrNotLeader) {
+			return nil, notLeaderError(err)
+		}
+		if errors.Is(err, store.ErrMemoryLimit) {
+			return nil, status.Error(codes.ResourceExhausted, "memory limit reached, no keys could be evicted")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to apply batch: %v", err)
+	}
+
+	return result.Results, nil
+}
+
+// batchKeys builds an op of opType for every non-empty key, returning the
+// results to fill in and the position in them of each op.
+func batchKeys(keys []string, opType store.TxnOpType) ([]*pb.BatchResult, []store.TxnOp, []int, error) {
+	if len(keys) > maxBatchItems {
+		return nil, nil, nil, status.Errorf(codes.InvalidArgument, "a batch cannot have more than %d items", maxBatchItems)
+	}
+
+	results := make([]*pb.BatchResult, len(keys))
+	var ops []store.TxnOp
+	var positions []int
+
+	for n, key := range keys {
+		results[n] = &pb.BatchResult{Key: key}
+
+		if key == "" {
+			setItemError(results[n], status.Error(codes.InvalidArgument, store.ErrEmptyKey.Error()))
+			continue
+		}
+
+		ops = append(ops, store.TxnOp{Type: opType, Key: key})
+		positions = append(positions, n)
+	}
+
+	return results, ops, positions, nil
+}
+
+func validateSetItem(item *pb.SetRequest) error {
+	if item.Key == "" {
+		return status.Error(codes.InvalidArgument, store.ErrEmptyKey.Error())
+	}
+
+	if item.TtlSeconds < 0 {
+		return status.Error(codes.InvalidArgument, "ttl_seconds cannot be negative")
+	}
+
+	return nil
+}
+
+func setOp(item *pb.SetRequest) store.TxnOp {
+	return store.TxnOp{
+		Type:  store.TxnPut,
+		Key:   item.Key,
+		Value: requestValue(item.Value, item.ValueBytes),
+		TTL:   time.Duration(item.TtlSeconds) * time.Second,
+	}
+}
+
+func setItemError(result *pb.BatchResult, err error) {
+	st := status.Convert(err)
+	result.Code = int32(st.Code())
+	result.Error = st.Message()
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	"GRPC-KV-Store-System/schemas/logging"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+const (
+	// notLeaderReason marks the ErrorInfo of writes a follower turned down.
+	// Its metadata names the leader under "leader".
+	notLeaderReason = "NOT_LEADER"
+	errorDomain     = "kvstore"
+
+	// forwardedHeader is set on writes a follower forwards, so they are
+	// never forwarded twice.
+	forwardedHeader = "x-kv-forwarded"
+)
+
+// notLeaderError is the Unavailable status of a write sent to a follower.
+// It names the leader, when one is known, so clients can retry there.
+func notLeaderError(err error) error {
+	st := status.New(codes.Unavailable, err.Error())
+
+	var notLeader *store.NotLeaderError
+	if errors.As(err, &notLeader) && notLeader.Leader != "" {
+		detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   notLeaderReason,
+			Domain:   errorDomain,
+			Metadata: map[string]string{"leader": notLeader.Leader},
+		})
+		if detailErr == nil {
+			st = detailed
+		}
+	}
+
+	return st.Err()
+}
+
+// LeaderFromError returns the leader named by a write a follower turned
+// down, or empty if err is not such an error.
+func LeaderFromError(err error) string {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.Unavailable {
+		return ""
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason == notLeaderReason {
+			return info.Metadata["leader"]
+		}
+	}
+
+	return ""
+}
+
+// Forwarder sends writes that a follower turned down on to the leader, so
+// clients can write through any node of a cluster. Streaming writes are
+// not forwarded; they fail with the leader's address instead.
+type Forwarder struct {
+	dialOptions []grpc.DialOption
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func StartForwarder(opts ...grpc.DialOption) *Forwarder {
+	return &Forwarder{
+		dialOptions: opts,
+		conns:       make(map[string]*grpc.ClientConn),
+	}
+}
+
+// UnaryInterceptor forwards a unary call to the leader when this node
+// turns it down for not being the leader.
+func (f *Forwarder) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+
+	leader := LeaderFromError(err)
+	if leader == "" {
+		return resp, err
+	}
+
+	if md, _ := metadata.FromIncomingContext(ctx); len(md.Get(forwardedHeader)) > 0 {
+		return resp, err
+	}
+
+	reply, replyErr := newReply(info.FullMethod)
+	if replyErr != nil {
+		slog.WarnContext(ctx, "Not forwarding", "method", info.FullMethod, "error", replyErr)
+		return resp, err
+	}
+
+	conn, dialErr := f.conn(leader)
+	if dialErr != nil {
+		slog.ErrorContext(ctx, "Failed to connect to leader", "leader", leader, "error", dialErr)
+		return resp, err
+	}
+
+	slog.InfoContext(ctx, "Forwarding to leader", "method", info.FullMethod, "leader", leader)
+
+	// The leader authorizes the write again, for the same principal, which
+	// it takes from this node as a trusted proxy.
+	pairs := []string{forwardedHeader, "1"}
+	if principal, ok := PrincipalFrom(ctx); ok {
+		pairs = append(pairs, rbac.PrincipalHeader, principal)
+	} else if md, _ := metadata.FromIncomingContext(ctx); len(md.Get(rbac.PrincipalHeader)) > 0 {
+		pairs = append(pairs, rbac.PrincipalHeader, md.Get(rbac.PrincipalHeader)[0])
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		pairs = append(pairs, logging.RequestIDHeader, id)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, pairs...)
+	if err := conn.Invoke(ctx, info.FullMethod, req, reply); err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+func (f *Forwarder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var errs []error
+	for addr, conn := range f.conns {
+		errs = append(errs, conn.Close())
+		delete(f.conns, addr)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f *Forwarder) conn(addr string) (*grpc.ClientConn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if conn, ok := f.conns[addr]; ok {
+		return conn, nil
+	}
+
+	conn, err := grpc.NewClient(addr, f.dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	f.conns[addr] = conn
+	return conn, nil
+}
+
+// newReply returns an empty response message for fullMethod, which looks
+// like "/package.Service/Method".
+func newReply(fullMethod string) (proto.Message, error) {
+	name := protoreflect.FullName(strings.Replace(strings.TrimPrefix(fullMethod, "/"), "/", ".", 1))
+
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	method, ok := desc.(protoreflect.MethodDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a method", name)
+	}
+
+	reply, err := protoregistry.GlobalTypes.FindMessageByName(method.Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+
+	return reply.New().Interface(), nil
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// leaderReporter is implemented by stores replicated across a cluster.
+type leaderReporter interface {
+	Leader() string
+}
+
+// failureReporter is implemented by stores that can stop taking writes
+// for good, such as a durable store whose write-ahead log has failed.
+type failureReporter interface {
+	Err() error
+}
+
+// Health serves grpc.health.v1. The overall status, under the empty
+// service name, is serving for as long as the process is up. The
+// KeyValueStore service is serving while the store can answer requests,
+// which for a replicated store means while a leader is known and for a
+// durable store while its write-ahead log still takes writes.
+type Health struct {
+	*health.Server
+	store store.Store
+	done  chan struct{}
+}
+
+// StartHealth serves the health of kvStore, checking it every interval.
+func StartHealth(kvStore store.Store, interval time.Duration) *Health {
+	h := &Health{
+		Server: health.NewServer(),
+		store:  kvStore,
+		done:   make(chan struct{}),
+	}
+
+	h.update()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-h.done:
+				return
+			case <-ticker.C:
+				h.update()
+			}
+		}
+	}()
+
+	return h
+}
+
+func (h *Health) update() {
+	status := healthpb.HealthCheckResponse_SERVING
+	if replicated, ok := h.store.(leaderReporter); ok && replicated.Leader() == "" {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	if failing, ok := h.store.(failureReporter); ok && failing.Err() != nil {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	h.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	h.SetServingStatus(pb.KeyValueStore_ServiceDesc.ServiceName, status)
+}
+
+// Shutdown reports every service as not serving from now on, so clients
+// move elsewhere while in-flight requests finish.
+func (h *Health) Shutdown() {
+	close(h.done)
+	h.Server.Shutdown()
+	slog.Info("Reporting every service as not serving")
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+)
+
+// Metrics counts and times every RPC by method and status code, and
+// reports the store's keys and memory at each scrape.
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func StartMetrics(kvStore store.Store) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "kvstore",
+			Name:      "grpc_requests_total",
+			Help:      "RPCs handled, by service, method and status code.",
+		}, []string{"service", "method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "kvstore",
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Time taken to handle RPCs, by service, method and status code. Streams are timed until they end.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "method", "code"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	if reporter, ok := kvStore.(store.MemoryReporter); ok {
+		m.registry.MustRegister(storeCollector{reporter})
+	}
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observe(info.FullMethod, err, start)
+
+	return resp, err
+}
+
+func (m *Metrics) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.observe(info.FullMethod, err, start)
+
+	return err
+}
+
+func (m *Metrics) observe(fullMethod string, err error, start time.Time) {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	code := status.Code(err).String()
+
+	m.requests.WithLabelValues(service, method, code).Inc()
+	m.duration.WithLabelValues(service, method, code).Observe(time.Since(start).Seconds())
+}
+
+var (
+	keysDesc = prometheus.NewDesc("kvstore_keys",
+		"Keys in the store, including expired keys not yet swept.", nil, nil)
+	usedBytesDesc = prometheus.NewDesc("kvstore_used_bytes",
+		"Approximate memory the keys and values take up.", nil, nil)
+	maxBytesDesc = prometheus.NewDesc("kvstore_max_bytes",
+		"Memory budget of the store, zero when unlimited.", nil, nil)
+	evictionsDesc = prometheus.NewDesc("kvstore_evictions_total",
+		"Keys evicted to make room for writes.", nil, nil)
+	expirationsDesc = prometheus.NewDesc("kvstore_expirations_total",
+		"Keys removed because their TTL ran out.", nil, nil)
+	rejectedWritesDesc = prometheus.NewDesc("kvstore_rejected_writes_total",
+		"Writes failed because the memory budget was used up.", nil, nil)
+)
+
+// storeCollector reads the store's stats once per scrape.
+type storeCollector struct {
+	reporter store.MemoryReporter
+}
+
+func (c storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- keysDesc
+	ch <- usedBytesDesc
+	ch <- maxBytesDesc
+	ch <- evictionsDesc
+	ch <- expirationsDesc
+	ch <- rejectedWritesDesc
+}
+
+func (c storeCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.reporter.MemoryStats()
+
+	ch <- prometheus.MustNewConstMetric(keysDesc, prometheus.GaugeValue, float64(stats.Keys))
+	ch <- prometheus.MustNewConstMetric(usedBytesDesc, prometheus.GaugeValue, float64(stats.UsedBytes))
+	ch <- prometheus.MustNewConstMetric(maxBytesDesc, prometheus.GaugeValue, float64(stats.MaxBytes))
+	ch <- prometheus.MustNewConstMetric(evictionsDesc, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(expirationsDesc, prometheus.CounterValue, float64(stats.Expirations))
+	ch <- prometheus.MustNewConstMetric(rejectedWritesDesc, prometheus.CounterValue, float64(stats.RejectedWrites))
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// keyspace returns the keyspace of the namespace a request names, tracing
+// its calls under the request's span.
+func (i *Server) keyspace(ctx context.Context, namespace string) (store.Store, error) {
+	kv, err := i.namespaces.Get(namespace)
+	if err != nil {
+		return nil, namespaceError(err)
+	}
+	return traced(ctx, kv), nil
+}
+
+func (i *Server) CreateNamespace(ctx context.Context, req *pb.CreateNamespaceRequest) (*pb.CreateNamespaceResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: CreateNamespace", "name", req.Name)
+
+	if err := i.namespaces.Create(req.Name); err != nil {
+		return nil, namespaceError(err)
+	}
+
+	slog.InfoContext(ctx, "Successfully created namespace", "name", req.Name)
+
+	return &pb.CreateNamespaceResponse{
+		Message: "namespace created successfully",
+	}, nil
+}
+
+func (i *Server) ListNamespaces(ctx context.Context, req *pb.ListNamespacesRequest) (*pb.ListNamespacesResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: ListNamespaces")
+
+	names, err := i.namespaces.List()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list namespaces: %v", err)
+	}
+
+	slog.InfoContext(ctx, "Successfully listed namespaces", "namespaces", len(names))
+
+	return &pb.ListNamespacesResponse{
+		Names: names,
+	}, nil
+}
+
+func (i *Server) DropNamespace(ctx context.Context, req *pb.DropNamespaceRequest) (*pb.DropNamespaceResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: DropNamespace", "name", req.Name)
+
+	dropped, err := i.namespaces.Drop(req.Name)
+	if err != nil {
+		return nil, namespaceError(err)
+	}
+
+	slog.InfoContext(ctx, "Successfully dropped namespace", "name", req.Name, "keys", dropped)
+
+	return &pb.DropNamespaceResponse{
+		Message:     "namespace dropped successfully",
+		DeletedKeys: int64(dropped),
+	}, nil
+}
+
+func namespaceError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrInvalidNamespace):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, store.ErrNamespaceNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, store.ErrNamespaceExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, store.ErrNotLeader):
+		return notLeaderError(err)
+	case errors.Is(err, store.ErrMemoryLimit):
+		return status.Error(codes.ResourceExhausted, "memory limit reached, no keys could be evicted")
+	}
+	return status.Errorf(codes.Internal, "namespace operation failed: %v", err)
+}
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// quotaReason marks the ErrorInfo of requests turned down for going over a
+// namespace's quota. Its metadata names the namespace and the quota.
+const quotaReason = "QUOTA_EXCEEDED"
+
+// Quota caps what a namespace may hold and how fast it may be used. Zero
+// fields are unlimited.
+type Quota struct {
+	MaxKeys       int64 `json:"max_keys"`
+	MaxBytes      int64 `json:"max_bytes"`
+	MaxValueBytes int64 `json:"max_value_bytes"`
+	// OpsPerSecond counts every key read or written, so a batch of ten
+	// keys is ten operations, while a scan page or a watch is one. Bursts
+	// of up to a second's worth are allowed.
+	OpsPerSecond float64 `json:"ops_per_second"`
+}
+
+// Quotas are the quotas of every namespace: its own entry in Namespaces,
+// or Default when it has none. The default namespace is named "".
+type Quotas struct {
+	Default    Quota            `json:"default"`
+	Namespaces map[string]Quota `json:"namespaces"`
+}
+
+func (q Quotas) For(namespace string) Quota {
+	if quota, ok := q.Namespaces[namespace]; ok {
+		return quota
+	}
+	return q.Default
+}
+
+// LoadQuotas reads quotas from a JSON file.
+func LoadQuotas(path string) (Quotas, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Quotas{}, err
+	}
+
+	var quotas Quotas
+	if err := json.Unmarshal(data, &quotas); err != nil {
+		return Quotas{}, fmt.Errorf("invalid quotas file %s: %w", path, err)
+	}
+
+	for name, quota := range quotas.Namespaces {
+		if quota.MaxKeys < 0 || quota.MaxBytes < 0 || quota.MaxValueBytes < 0 || quota.OpsPerSecond < 0 {
+			return Quotas{}, fmt.Errorf("invalid quotas file %s: quotas of namespace %q cannot be negative", path, name)
+		}
+	}
+	if d := quotas.Default; d.MaxKeys < 0 || d.MaxBytes < 0 || d.MaxValueBytes < 0 || d.OpsPerSecond < 0 {
+		return Quotas{}, fmt.Errorf("invalid quotas file %s: default quotas cannot be negative", path)
+	}
+
+	return quotas, nil
+}
+
+// SetQuotas replaces the quotas of every namespace. Rate limits start over
+// with a full second's worth of operations.
+func (i *Server) SetQuotas(quotas Quotas) {
+	i.quotaMu.Lock()
+	defer i.quotaMu.Unlock()
+
+	i.quotas = quotas
+	i.limiters = make(map[string]*tokenBucket)
+}
+
+func (i *Server) quota(namespace string) Quota {
+	i.quotaMu.Lock()
+	defer i.quotaMu.Unlock()
+
+	return i.quotas.For(namespace)
+}
+
+// limiter returns the rate limit of a namespace, or nil if it has none.
+func (i *Server) limiter(namespace string) *tokenBucket {
+	i.quotaMu.Lock()
+	defer i.quotaMu.Unlock()
+
+	rate := i.quotas.For(namespace).OpsPerSecond
+	if rate <= 0 {
+		return nil
+	}
+
+	b, ok := i.limiters[namespace]
+	if !ok {
+		b = newTokenBucket(rate)
+		i.limiters[namespace] = b
+	}
+	return b
+}
+
+// admit charges ops against the rate quota of a namespace and checks that
+// writes, the puts of which are applied to kv, fit its other quotas.
+// Deletes and gets among writes are ignored.
+func (i *Server) admit(namespace string, kv store.Store, ops int, writes []store.TxnOp) error {
+	if b := i.limiter(namespace); b != nil {
+		if wait := b.take(float64(ops), time.Now()); wait > 0 {
+			return quotaError(namespace, "ops_per_second", wait,
+				"namespace is over its quota of %g operations per second", b.rate)
+		}
+	}
+
+	_, err := i.growth(namespace, kv, writes, store.Usage{})
+	return err
+}
+
+// throttle waits until ops fit the rate quota of a namespace, for streams
+// that are better slowed down than failed.
+func (i *Server) throttle(ctx context.Context, namespace string, ops int) error {
+	b := i.limiter(namespace)
+	if b == nil {
+		return nil
+	}
+
+	for {
+		wait := b.take(float64(ops), time.Now())
+		if wait <= 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// growth returns what the puts among writes add to a namespace on top of
+// pending writes not yet applied, failing if that takes it over its key,
+// byte or value size quotas. It is approximate: writes checked at the same
+// time may together go over.
+func (i *Server) growth(namespace string, kv store.Store, writes []store.TxnOp, pending store.Usage) (store.Usage, error) {
+	q := i.quota(namespace)
+	if q.MaxKeys == 0 && q.MaxBytes == 0 && q.MaxValueBytes == 0 {
+		return pending, nil
+	}
+
+	grown := pending
+	for _, op := range writes {
+		if op.Type != store.TxnPut {
+			continue
+		}
+
+		if q.MaxValueBytes > 0 && int64(len(op.Value)) > q.MaxValueBytes {
+			return pending, quotaError(namespace, "max_value_bytes", 0,
+				"value of key %s is %d bytes, over the namespace's quota of %d", op.Key, len(op.Value), q.MaxValueBytes)
+		}
+
+		old, err := kv.Get(op.Key)
+		switch {
+		case errors.Is(err, store.ErrKeyNotFound):
+			grown.Keys++
+			grown.Bytes += int64(len(op.Key) + len(op.Value))
+		case err == nil:
+			grown.Bytes += int64(len(op.Value) - len(old))
+		default:
+			return pending, status.Errorf(codes.Internal, "failed to check quota: %v", err)
+		}
+	}
+
+	if q.MaxKeys == 0 && q.MaxBytes == 0 {
+		return grown, nil
+	}
+
+	reporter, ok := i.store.(store.UsageReporter)
+	if !ok {
+		return grown, nil
+	}
+	usage := reporter.NamespaceUsage(namespace)
+
+	if q.MaxKeys > 0 && grown.Keys > 0 && usage.Keys+grown.Keys > q.MaxKeys {
+		return pending, quotaError(namespace, "max_keys", 0,
+			"namespace is at its quota of %d keys", q.MaxKeys)
+	}
+	if q.MaxBytes > 0 && grown.Bytes > 0 && usage.Bytes+grown.Bytes > q.MaxBytes {
+		return pending, quotaError(namespace, "max_bytes", 0,
+			"namespace is at its quota of %d bytes", q.MaxBytes)
+	}
+
+	return grown, nil
+}
+
+// quotaError is the ResourceExhausted status of a request over quota. It
+// tells clients to retry after wait, when positive.
+func quotaError(namespace, quota string, wait time.Duration, format string, args ...any) error {
+	st := status.Newf(codes.ResourceExhausted, format, args...)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   quotaReason,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"namespace": namespace, "quota": quota},
+	}}
+	if wait > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(wait)})
+	}
+
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
+
+func (i *Server) Usage(ctx context.Context, req *pb.UsageRequest) (*pb.UsageResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: Usage", "namespace", req.Namespace)
+
+	usage, err := i.namespaces.Usage(req.Namespace)
+	if err != nil {
+		if errors.Is(err, store.ErrNoUsage) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, namespaceError(err)
+	}
+
+	q := i.quota(req.Namespace)
+
+	slog.InfoContext(ctx, "Successfully retrieved usage", "namespace", req.Namespace, "keys", usage.Keys, "bytes", usage.Bytes)
+
+	return &pb.UsageResponse{
+		Namespace:     req.Namespace,
+		KeyCount:      usage.Keys,
+		Bytes:         usage.Bytes,
+		MaxKeys:       q.MaxKeys,
+		MaxBytes:      q.MaxBytes,
+		MaxValueBytes: q.MaxValueBytes,
+		OpsPerSecond:  q.OpsPerSecond,
+	}, nil
+}
+
+// tokenBucket allows rate operations a second, in bursts of up to a
+// second's worth.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	burst := math.Max(rate, 1)
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// take spends n tokens, or spends none and returns how long until there
+// are enough. More than a burst's worth goes through once the bucket is
+// full and leaves it in debt, so large batches are not turned down forever
+// but still count in full.
+func (b *tokenBucket) take(n float64, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+
+	need := math.Min(n, b.burst)
+	if b.tokens >= need {
+		b.tokens -= n
+		return 0
+	}
+
+	return time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "GRPC-KV-Store-System/schemas/grpc"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+// Authorizer enforces an RBAC policy on the KV store's RPCs. The principal
+// of a request is the common name of the client certificate it came with.
+// Trusted proxies, such as the REST API, make requests for others, so
+// theirs is the principal they name in the x-kv-principal metadata
+// instead. Other services, such as reflection, are not checked.
+type Authorizer struct {
+	enforcer *rbac.Enforcer
+	// trustedProxies are the common names of the certificates whose
+	// x-kv-principal is taken on trust.
+	trustedProxies []string
+}
+
+func StartAuthorizer(enforcer *rbac.Enforcer, trustedProxies []string) *Authorizer {
+	return &Authorizer{enforcer: enforcer, trustedProxies: trustedProxies}
+}
+
+func (a *Authorizer) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = context.WithValue(ctx, principalKey{}, a.principal(ctx))
+	if err := a.authorize(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// StreamInterceptor checks every message a stream receives, so each item
+// of a bulk load is checked on its own.
+func (a *Authorizer) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := context.WithValue(ss.Context(), principalKey{}, a.principal(ss.Context()))
+	return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx, authorizer: a, method: info.FullMethod})
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx        context.Context
+	authorizer *Authorizer
+	method     string
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.authorizer.authorize(s.Context(), s.method, m)
+}
+
+func (a *Authorizer) authorize(ctx context.Context, fullMethod string, req any) error {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if service != pb.KeyValueStore_ServiceDesc.ServiceName {
+		return nil
+	}
+
+	principal, _ := PrincipalFrom(ctx)
+	if err := a.enforcer.Authorize(principal, accessesOf(method, req)...); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return nil
+}
+
+// principal returns who a request is made for: the principal a trusted
+// proxy names, or else the caller's own certificate. Requests without a
+// client certificate have the unnamed principal.
+func (a *Authorizer) principal(ctx context.Context) string {
+	identity := peerIdentity(ctx)
+	if identity == "" || !slices.Contains(a.trustedProxies, identity) {
+		return identity
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if names := md.Get(rbac.PrincipalHeader); len(names) > 0 {
+			return names[0]
+		}
+	}
+	return ""
+}
+
+// peerIdentity returns the common name of the caller's client certificate.
+// The server only takes certificates it verified against its CA bundle, so
+// any it has are trusted.
+func peerIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return ""
+	}
+	return info.State.PeerCertificates[0].Subject.CommonName
+}
+
+type principalKey struct{}
+
+// PrincipalFrom returns the principal the Authorizer found ctx's request is
+// made for, and whether it checked the request at all.
+func PrincipalFrom(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(principalKey{}).(string)
+	return principal, ok
+}
+
+// accessesOf returns what a request of method reads and writes in its
+// namespace. Requests not on keys need the admin verb.
+func accessesOf(method string, req any) []rbac.Access {
+	var namespace string
+	if r, ok := req.(interface{ GetNamespace() string }); ok {
+		namespace = r.GetNamespace()
+	}
+
+	switch r := req.(type) {
+	case *pb.GetRequest:
+		return []rbac.Access{rbac.OnKey(namespace, rbac.Read, r.Key)}
+	case *pb.TTLRequest:
+		return []rbac.Access{rbac.OnKey(namespace, rbac.Read, r.Key)}
+	case *pb.SetRequest:
+		return []rbac.Access{rbac.OnKey(namespace, rbac.Write, r.Key)}
+	case *pb.PersistRequest:
+		return []rbac.Access{rbac.OnKey(namespace, rbac.Write, r.Key)}
+	case *pb.CompareAndSwapRequest:
+		return []rbac.Access{rbac.OnKey(namespace, rbac.Write, r.Key)}
+	case *pb.CounterRequest:
+		return []rbac.Access{rbac.OnKey(namespace, rbac.Write, r.Key)}
+	case *pb.DeleteRequest:
+		return []rbac.Access{rbac.OnKey(namespace, rbac.Delete, r.Key)}
+	case *pb.TxnRequest:
+		var accesses []rbac.Access
+		for _, c := range r.Compare {
+			accesses = append(accesses, rbac.OnKey(namespace, rbac.Read, c.Key))
+		}
+		for _, op := range slices.Concat(r.Success, r.Failure) {
+			accesses = append(accesses, rbac.OnKey(namespace, txnVerbs[op.Type], op.Key))
+		}
+		return accesses
+	case *pb.BatchSetRequest:
+		accesses := make([]rbac.Access, 0, len(r.Items))
+		for _, item := range r.Items {
+			accesses = append(accesses, rbac.OnKey(namespace, rbac.Write, item.Key))
+		}
+		return accesses
+	case *pb.BatchKeysRequest:
+		verb := rbac.Read
+		if method == "BatchDelete" {
+			verb = rbac.Delete
+		}
+
+		accesses := make([]rbac.Access, 0, len(r.Keys))
+		for _, key := range r.Keys {
+			accesses = append(accesses, rbac.OnKey(namespace, verb, key))
+		}
+		return accesses
+	case *pb.ScanRequest:
+		return []rbac.Access{rbac.OnPrefix(namespace, rbac.Read, scanPrefix(r))}
+	case *pb.WatchRequest:
+		if r.Prefix {
+			return []rbac.Access{rbac.OnPrefix(namespace, rbac.Read, r.Key)}
+		}
+		return []rbac.Access{rbac.OnKey(namespace, rbac.Read, r.Key)}
+	default:
+		return []rbac.Access{{Namespace: namespace, Verb: rbac.Admin}}
+	}
+}
+
+var txnVerbs = map[pb.TxnOp_Type]rbac.Verb{
+	pb.TxnOp_GET:    rbac.Read,
+	pb.TxnOp_PUT:    rbac.Write,
+	pb.TxnOp_DELETE: rbac.Delete,
+}
+
+// scanPrefix returns a prefix every key a scan can return starts with: its
+// prefix, when it has one, or what the bounds of its range have in common.
+func scanPrefix(req *pb.ScanRequest) string {
+	if req.Prefix != "" || req.End == "" {
+		// Without an end a range runs to the last key, so only a prefix
+		// narrows it.
+		return req.Prefix
+	}
+
+	n := 0
+	for n < len(req.Start) && n < len(req.End) && req.Start[n] == req.End[n] {
+		n++
+	}
+	return req.Start[:n]
+}
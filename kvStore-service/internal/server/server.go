@@ -2,58 +2,135 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
-	"log"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"GRPC-KV-Store-System/kvStore-service/internal/store"
 	pb "GRPC-KV-Store-System/schemas/grpc"
+	"GRPC-KV-Store-System/schemas/logging"
+)
+
+const (
+	defaultScanLimit = 100
+	maxScanLimit     = 1000
+
+	// scanStreamBatch is how many keys ScanStream reads from the store at a
+	// time, so a long stream never holds the store lock for long.
+	scanStreamBatch = 256
+
+	// maxTxnOps caps the compares plus ops in a single Txn, since the whole
+	// transaction runs under the store's write lock.
+	maxTxnOps = 128
 )
 
 type Server struct {
 	pb.UnimplementedKeyValueStoreServer
-	store store.Store
+	store      store.Store
+	namespaces *store.Namespaces
+
+	// quotas caps what each namespace may hold and how fast, with a rate
+	// limiter for every namespace that has one.
+	quotaMu  sync.Mutex
+	quotas   Quotas
+	limiters map[string]*tokenBucket
+
+	// shutdown is closed to end every open Watch.
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
 }
 
 func StartServer(i store.Store) *Server {
 	return &Server{
-		store: i,
+		store:      i,
+		namespaces: store.CreateNamespaces(i),
+		limiters:   make(map[string]*tokenBucket),
+		shutdown:   make(chan struct{}),
 	}
 }
 
+// Shutdown ends every open Watch, so a graceful stop does not wait on
+// streams that never finish by themselves.
+func (i *Server) Shutdown() {
+	i.shutdownOnce.Do(func() {
+		close(i.shutdown)
+	})
+}
+
 func (i *Server) Set(ctx context.Context, req *pb.SetRequest) (*pb.SetResponse, error) {
-	log.Printf("Processing Request: Set key=%s, value=%s", req.Key, req.Value)
+	slog.InfoContext(ctx, "Processing Request: Set", logging.Key("key", req.Key), logging.Value("value", requestValue(req.Value, req.ValueBytes)), "ttl_seconds", req.TtlSeconds)
 
 	if req.Key == "" {
 		return nil, status.Error(codes.InvalidArgument, "key cannot be empty")
 	}
 
-	err := i.store.Set(req.Key, req.Value)
+	if req.TtlSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl_seconds cannot be negative")
+	}
 
+	kv, err := i.keyspace(ctx, req.Namespace)
 	if err != nil {
-		if errors.Is(err, store.ErrEmptyKey) {
+		return nil, err
+	}
+
+	value := requestValue(req.Value, req.ValueBytes)
+
+	if err := i.admit(req.Namespace, kv, 1, []store.TxnOp{{Type: store.TxnPut, Key: req.Key, Value: value}}); err != nil {
+		return nil, err
+	}
+
+	var version int64
+	if req.TtlSeconds > 0 {
+		version, err = kv.SetWithTTL(req.Key, value, time.Duration(req.TtlSeconds)*time.Second)
+	} else {
+		version, err = kv.Set(req.Key, value)
+	}
+
+	if err != nil {
+		if errors.Is(err, store.ErrEmptyKey) || errors.Is(err, store.ErrInvalidTTL) {
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
+		if errors.Is(err, store.ErrNotLeader) {
+			return nil, notLeaderError(err)
+		}
+		if errors.Is(err, store.ErrMemoryLimit) {
+			return nil, status.Error(codes.ResourceExhausted, "memory limit reached, no keys could be evicted")
+		}
 		return nil, status.Errorf(codes.Internal, "failed to store value: %v", err)
 	}
 
-	log.Printf("Successfully stored key=%s, value=%s", req.Key, req.Value)
+	slog.InfoContext(ctx, "Successfully stored key", logging.Key("key", req.Key), "version", version)
 
 	return &pb.SetResponse{
 		Message: "Value Stored Successfully",
+		Version: version,
 	}, nil
 }
 
 func (i *Server) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse, error) {
-	log.Printf("Processing Request: Get key=%s", req.Key)
+	slog.InfoContext(ctx, "Processing Request: Get", logging.Key("key", req.Key))
 
 	if req.Key == "" {
 		return nil, status.Error(codes.InvalidArgument, "key cannot be empty")
 	}
 
-	value, err := i.store.Get(req.Key)
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.admit(req.Namespace, kv, 1, nil); err != nil {
+		return nil, err
+	}
+
+	value, version, err := kv.GetWithVersion(req.Key)
 
 	if err != nil {
 		if errors.Is(err, store.ErrEmptyKey) {
@@ -67,21 +144,412 @@ func (i *Server) Get(ctx context.Context, req *pb.GetRequest) (*pb.GetResponse,
 		return nil, status.Errorf(codes.Internal, "failed to retrieve value: %v", err)
 	}
 
-	log.Printf("Successfully retrieved key=%s", req.Key)
+	slog.InfoContext(ctx, "Successfully retrieved key", logging.Key("key", req.Key))
+
+	resp := &pb.GetResponse{Version: version}
+	resp.Value, resp.ValueBytes = responseValue(value)
+
+	return resp, nil
+}
+
+func (i *Server) CompareAndSwap(ctx context.Context, req *pb.CompareAndSwapRequest) (*pb.CompareAndSwapResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: CompareAndSwap", logging.Key("key", req.Key), logging.Value("value", requestValue(req.Value, req.ValueBytes)))
+
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "key cannot be empty")
+	}
+
+	if req.TtlSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl_seconds cannot be negative")
+	}
+
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var cond store.Condition
+	switch expected := req.Expected.(type) {
+	case *pb.CompareAndSwapRequest_ExpectedVersion:
+		cond.Version = expected.ExpectedVersion
+	case *pb.CompareAndSwapRequest_ExpectedValue:
+		cond.Value = &expected.ExpectedValue
+	case *pb.CompareAndSwapRequest_ExpectedValueBytes:
+		expectedValue := string(expected.ExpectedValueBytes)
+		cond.Value = &expectedValue
+	default:
+		return nil, status.Error(codes.InvalidArgument, "expected_version or expected_value is required")
+	}
+
+	value := requestValue(req.Value, req.ValueBytes)
+
+	if err := i.admit(req.Namespace, kv, 1, []store.TxnOp{{Type: store.TxnPut, Key: req.Key, Value: value}}); err != nil {
+		return nil, err
+	}
+
+	version, err := kv.CompareAndSwap(req.Key, cond, value, time.Duration(req.TtlSeconds)*time.Second)
+	if err != nil {
+		if errors.Is(err, store.ErrEmptyKey) || errors.Is(err, store.ErrInvalidTTL) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, store.ErrVersionMismatch) {
+			return nil, status.Error(codes.FailedPrecondition, "key does not match the expected version or value")
+		}
+
+		if errors.Is(err, store.ErrNotLeader) {
+			return nil, notLeaderError(err)
+		}
+
+		if errors.Is(err, store.ErrMemoryLimit) {
+			return nil, status.Error(codes.ResourceExhausted, "memory limit reached, no keys could be evicted")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to store value: %v", err)
+	}
+
+	slog.InfoContext(ctx, "Successfully swapped key", logging.Key("key", req.Key), "version", version)
+
+	return &pb.CompareAndSwapResponse{
+		Version: version,
+	}, nil
+}
+
+func (i *Server) Increment(ctx context.Context, req *pb.CounterRequest) (*pb.CounterResponse, error) {
+	delta := int64(1)
+	if req.Delta != nil {
+		delta = *req.Delta
+	}
+
+	return i.count(ctx, "Increment", req, delta)
+}
+
+func (i *Server) Decrement(ctx context.Context, req *pb.CounterRequest) (*pb.CounterResponse, error) {
+	delta := int64(1)
+	if req.Delta != nil {
+		delta = *req.Delta
+	}
+
+	if delta == math.MinInt64 {
+		return nil, status.Error(codes.InvalidArgument, "delta is out of range")
+	}
+
+	return i.count(ctx, "Decrement", req, -delta)
+}
+
+// count adds delta to the counter req names.
+func (i *Server) count(ctx context.Context, op string, req *pb.CounterRequest, delta int64) (*pb.CounterResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: "+op, logging.Key("key", req.Key), "delta", delta)
+
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "key cannot be empty")
+	}
+
+	if req.Min != nil && req.Max != nil && *req.Min > *req.Max {
+		return nil, status.Error(codes.InvalidArgument, "min cannot be greater than max")
+	}
+
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// A counter holds a few bytes at most, so only its key is counted.
+	if err := i.admit(req.Namespace, kv, 1, []store.TxnOp{{Type: store.TxnPut, Key: req.Key}}); err != nil {
+		return nil, err
+	}
 
-	return &pb.GetResponse{
-		Value: value,
+	value, version, err := kv.Increment(req.Key, delta, store.CounterOptions{
+		Initial: req.InitialValue,
+		Min:     req.Min,
+		Max:     req.Max,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrEmptyKey) || errors.Is(err, store.ErrNotInteger) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, store.ErrOutOfRange) {
+			return nil, status.Error(codes.OutOfRange, err.Error())
+		}
+
+		if errors.Is(err, store.ErrNotLeader) {
+			return nil, notLeaderError(err)
+		}
+
+		if errors.Is(err, store.ErrMemoryLimit) {
+			return nil, status.Error(codes.ResourceExhausted, "memory limit reached, no keys could be evicted")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to update counter: %v", err)
+	}
+
+	slog.InfoContext(ctx, "Successfully updated counter", logging.Key("key", req.Key), "version", version)
+
+	return &pb.CounterResponse{
+		Value:   value,
+		Version: version,
 	}, nil
 }
 
+func (i *Server) Txn(ctx context.Context, req *pb.TxnRequest) (*pb.TxnResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: Txn", "compares", len(req.Compare), "success", len(req.Success), "failure", len(req.Failure))
+
+	if len(req.Compare)+len(req.Success)+len(req.Failure) > maxTxnOps {
+		return nil, status.Errorf(codes.InvalidArgument, "a transaction cannot have more than %d compares and ops", maxTxnOps)
+	}
+
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	txn := store.Txn{
+		Compare: make([]store.Compare, 0, len(req.Compare)),
+		Success: toTxnOps(req.Success),
+		Failure: toTxnOps(req.Failure),
+	}
+	for _, c := range req.Compare {
+		// The proto enums are numbered like the store's; the store rejects
+		// anything out of range.
+		txn.Compare = append(txn.Compare, store.Compare{
+			Key:     c.Key,
+			Target:  store.CompareTarget(c.Target),
+			Result:  store.CompareResult(c.Result),
+			Value:   requestValue(c.Value, c.ValueBytes),
+			Version: c.Version,
+			Exists:  c.Exists,
+		})
+	}
+
+	// Either branch may run, so both must fit the namespace's quotas.
+	if err := i.admit(req.Namespace, kv, max(len(txn.Success), len(txn.Failure), 1), txn.Success); err != nil {
+		return nil, err
+	}
+	if _, err := i.growth(req.Namespace, kv, txn.Failure, store.Usage{}); err != nil {
+		return nil, err
+	}
+
+	result, err := kv.Txn(txn)
+	if err != nil {
+		if errors.Is(err, store.ErrEmptyKey) || errors.Is(err, store.ErrInvalidTTL) ||
+			errors.Is(err, store.ErrInvalidCompare) || errors.Is(err, store.ErrInvalidTxnOp) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		if errors.Is(err, store.ErrNotLeader) {
+			return nil, notLeaderError(err)
+		}
+		if errors.Is(err, store.ErrMemoryLimit) {
+			return nil, status.Error(codes.ResourceExhausted, "memory limit reached, no keys could be evicted")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to run transaction: %v", err)
+	}
+
+	resp := &pb.TxnResponse{
+		Succeeded: result.Succeeded,
+		Results:   make([]*pb.TxnOpResult, 0, len(result.Results)),
+		Revision:  result.Revision,
+	}
+	for _, r := range result.Results {
+		opResult := &pb.TxnOpResult{
+			Key:     r.Key,
+			Version: r.Version,
+			Found:   r.Found,
+		}
+		opResult.Value, opResult.ValueBytes = responseValue(r.Value)
+		resp.Results = append(resp.Results, opResult)
+	}
+
+	slog.InfoContext(ctx, "Successfully ran transaction", "succeeded", result.Succeeded, "revision", result.Revision)
+
+	return resp, nil
+}
+
+func (i *Server) Scan(ctx context.Context, req *pb.ScanRequest) (*pb.ScanResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: Scan", logging.Key("start", req.Start), logging.Key("end", req.End), logging.Key("prefix", req.Prefix), "limit", req.Limit)
+
+	opts, err := scanOptions(req)
+	if err != nil {
+		return nil, err
+	}
+
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.admit(req.Namespace, kv, 1, nil); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case opts.Limit == 0:
+		opts.Limit = defaultScanLimit
+	case opts.Limit > maxScanLimit:
+		opts.Limit = maxScanLimit
+	}
+
+	items, more, err := kv.Scan(opts)
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidLimit) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to scan keys: %v", err)
+	}
+
+	resp := &pb.ScanResponse{
+		Items: make([]*pb.KeyValue, 0, len(items)),
+	}
+	for _, item := range items {
+		resp.Items = append(resp.Items, toKeyValue(item))
+	}
+
+	if more {
+		resp.NextPageToken = encodePageToken(items[len(items)-1].Key)
+	}
+
+	slog.InfoContext(ctx, "Successfully scanned keys", "keys", len(items))
+
+	return resp, nil
+}
+
+func (i *Server) ScanStream(req *pb.ScanRequest, stream pb.KeyValueStore_ScanStreamServer) error {
+	slog.InfoContext(stream.Context(), "Processing Request: ScanStream", logging.Key("start", req.Start), logging.Key("end", req.End), logging.Key("prefix", req.Prefix), "limit", req.Limit)
+
+	opts, err := scanOptions(req)
+	if err != nil {
+		return err
+	}
+
+	kv, err := i.keyspace(stream.Context(), req.Namespace)
+	if err != nil {
+		return err
+	}
+
+	limit := opts.Limit
+	sent := 0
+
+	for {
+		opts.Limit = scanStreamBatch
+		if limit > 0 {
+			opts.Limit = min(limit-sent, scanStreamBatch)
+		}
+
+		if err := i.throttle(stream.Context(), req.Namespace, 1); err != nil {
+			return err
+		}
+
+		items, more, err := kv.Scan(opts)
+		if err != nil {
+			if errors.Is(err, store.ErrInvalidLimit) {
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+			return status.Errorf(codes.Internal, "failed to scan keys: %v", err)
+		}
+
+		for _, item := range items {
+			if err := stream.Send(toKeyValue(item)); err != nil {
+				return err
+			}
+		}
+		sent += len(items)
+
+		if !more || (limit > 0 && sent >= limit) {
+			break
+		}
+
+		opts.Start = items[len(items)-1].Key + "\x00"
+	}
+
+	slog.InfoContext(stream.Context(), "Successfully streamed keys", "keys", sent)
+
+	return nil
+}
+
+func (i *Server) Watch(req *pb.WatchRequest, stream pb.KeyValueStore_WatchServer) error {
+	slog.InfoContext(stream.Context(), "Processing Request: Watch", logging.Key("key", req.Key), "prefix", req.Prefix, "start_revision", req.StartRevision)
+
+	if req.Key == "" && !req.Prefix {
+		return status.Error(codes.InvalidArgument, "key cannot be empty")
+	}
+
+	if req.StartRevision < 0 {
+		return status.Error(codes.InvalidArgument, "start_revision cannot be negative")
+	}
+
+	kv, err := i.keyspace(stream.Context(), req.Namespace)
+	if err != nil {
+		return err
+	}
+
+	if err := i.admit(req.Namespace, kv, 1, nil); err != nil {
+		return err
+	}
+
+	watch, err := kv.Watch(store.WatchOptions{
+		Key:           req.Key,
+		Prefix:        req.Prefix,
+		StartRevision: req.StartRevision,
+	})
+	if err != nil {
+		return watchError(err)
+	}
+	defer watch.Close()
+
+	// Sending the headers tells the client the watch is in place.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	slog.InfoContext(stream.Context(), "Successfully started watch", logging.Key("key", req.Key))
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-i.shutdown:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case ev, ok := <-watch.Events():
+			if !ok {
+				return watchError(watch.Err())
+			}
+
+			eventType := pb.WatchEvent_PUT
+			if ev.Type == store.EventDelete {
+				eventType = pb.WatchEvent_DELETE
+			}
+
+			event := &pb.WatchEvent{
+				Type:     eventType,
+				Key:      ev.Key,
+				Revision: ev.Revision,
+			}
+			event.Value, event.ValueBytes = responseValue(ev.Value)
+
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (i *Server) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
-	log.Printf("Processing Request: Delete key=%s", req.Key)
+	slog.InfoContext(ctx, "Processing Request: Delete", logging.Key("key", req.Key))
 
 	if req.Key == "" {
 		return nil, status.Error(codes.InvalidArgument, "key cannot be empty")
 	}
 
-	err := i.store.Delete(req.Key)
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.admit(req.Namespace, kv, 1, nil); err != nil {
+		return nil, err
+	}
+
+	err = kv.Delete(req.Key)
 	if err != nil {
 		if errors.Is(err, store.ErrEmptyKey) {
 			return nil, status.Error(codes.InvalidArgument, err.Error())
@@ -91,12 +559,213 @@ func (i *Server) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteR
 			return nil, status.Error(codes.NotFound, "key not found")
 		}
 
+		if errors.Is(err, store.ErrNotLeader) {
+			return nil, notLeaderError(err)
+		}
+
 		return nil, status.Errorf(codes.Internal, "failed to delete key: %v", err)
 	}
 
-	log.Printf("Successfully deleted key=%s", req.Key)
+	slog.InfoContext(ctx, "Successfully deleted key", logging.Key("key", req.Key))
 
 	return &pb.DeleteResponse{
 		Message: "key deleted successfully",
 	}, nil
 }
+
+func (i *Server) TTL(ctx context.Context, req *pb.TTLRequest) (*pb.TTLResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: TTL", logging.Key("key", req.Key))
+
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "key cannot be empty")
+	}
+
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.admit(req.Namespace, kv, 1, nil); err != nil {
+		return nil, err
+	}
+
+	ttl, err := kv.TTL(req.Key)
+	if err != nil {
+		if errors.Is(err, store.ErrEmptyKey) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return nil, status.Error(codes.NotFound, "key not found")
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to retrieve ttl: %v", err)
+	}
+
+	ttlSeconds := int64(-1)
+	if ttl != store.NoExpiry {
+		ttlSeconds = int64(math.Ceil(ttl.Seconds()))
+	}
+
+	slog.InfoContext(ctx, "Successfully retrieved ttl", logging.Key("key", req.Key))
+
+	return &pb.TTLResponse{
+		TtlSeconds: ttlSeconds,
+	}, nil
+}
+
+func (i *Server) Persist(ctx context.Context, req *pb.PersistRequest) (*pb.PersistResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: Persist", logging.Key("key", req.Key))
+
+	if req.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "key cannot be empty")
+	}
+
+	kv, err := i.keyspace(ctx, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.admit(req.Namespace, kv, 1, nil); err != nil {
+		return nil, err
+	}
+
+	err = kv.Persist(req.Key)
+	if err != nil {
+		if errors.Is(err, store.ErrEmptyKey) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return nil, status.Error(codes.NotFound, "key not found")
+		}
+
+		if errors.Is(err, store.ErrNotLeader) {
+			return nil, notLeaderError(err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to persist key: %v", err)
+	}
+
+	slog.InfoContext(ctx, "Successfully persisted key", logging.Key("key", req.Key))
+
+	return &pb.PersistResponse{
+		Message: "key expiry removed successfully",
+	}, nil
+}
+
+func (i *Server) Snapshot(ctx context.Context, req *pb.SnapshotRequest) (*pb.SnapshotResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: Snapshot")
+
+	snapshotter, ok := i.store.(store.Snapshotter)
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "snapshots require a persistent store (--data-dir)")
+	}
+
+	info, err := snapshotter.Snapshot()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to write snapshot: %v", err)
+	}
+
+	slog.InfoContext(ctx, "Successfully wrote snapshot", "path", info.Path, "keys", info.Keys, "size", info.Size)
+
+	return &pb.SnapshotResponse{
+		Path:      info.Path,
+		SizeBytes: info.Size,
+		KeyCount:  int64(info.Keys),
+	}, nil
+}
+
+func (i *Server) Stats(ctx context.Context, req *pb.StatsRequest) (*pb.StatsResponse, error) {
+	slog.InfoContext(ctx, "Processing Request: Stats")
+
+	reporter, ok := i.store.(store.MemoryReporter)
+	if !ok {
+		return nil, status.Error(codes.FailedPrecondition, "store does not report memory stats")
+	}
+
+	stats := reporter.MemoryStats()
+
+	slog.InfoContext(ctx, "Successfully retrieved stats", "keys", stats.Keys, "used_bytes", stats.UsedBytes, "evictions", stats.Evictions)
+
+	return &pb.StatsResponse{
+		KeyCount:       int64(stats.Keys),
+		UsedBytes:      stats.UsedBytes,
+		MaxBytes:       stats.MaxBytes,
+		EvictionPolicy: string(stats.Policy),
+		Evictions:      stats.Evictions,
+		RejectedWrites: stats.RejectedWrites,
+	}, nil
+}
+
+func watchError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrEmptyKey):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, store.ErrCompacted):
+		return status.Error(codes.OutOfRange, "start revision has been compacted, or the watch fell too far behind")
+	case errors.Is(err, store.ErrClosed):
+		return status.Error(codes.Unavailable, "store is closed")
+	}
+	return status.Errorf(codes.Internal, "watch failed: %v", err)
+}
+
+func toTxnOps(ops []*pb.TxnOp) []store.TxnOp {
+	converted := make([]store.TxnOp, 0, len(ops))
+	for _, op := range ops {
+		converted = append(converted, store.TxnOp{
+			Type:  store.TxnOpType(op.Type),
+			Key:   op.Key,
+			Value: requestValue(op.Value, op.ValueBytes),
+			TTL:   time.Duration(op.TtlSeconds) * time.Second,
+		})
+	}
+	return converted
+}
+
+func scanOptions(req *pb.ScanRequest) (store.ScanOptions, error) {
+	if req.Limit < 0 {
+		return store.ScanOptions{}, status.Error(codes.InvalidArgument, "limit cannot be negative")
+	}
+
+	opts := store.ScanOptions{
+		Start:  req.Start,
+		End:    req.End,
+		Prefix: req.Prefix,
+		Limit:  int(req.Limit),
+	}
+
+	if req.PageToken != "" {
+		resume, err := decodePageToken(req.PageToken)
+		if err != nil {
+			return store.ScanOptions{}, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		opts.Start = max(opts.Start, resume)
+	}
+
+	return opts, nil
+}
+
+// Page tokens carry the key a scan resumes from: the smallest key sorting
+// after the last one returned.
+func encodePageToken(lastKey string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastKey + "\x00"))
+}
+
+func decodePageToken(token string) (string, error) {
+	resume, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", err
+	}
+	return string(resume), nil
+}
+
+func toKeyValue(item store.KeyValue) *pb.KeyValue {
+	kv := &pb.KeyValue{
+		Key:     item.Key,
+		Version: item.Version,
+	}
+	kv.Value, kv.ValueBytes = responseValue(item.Value)
+
+	return kv
+}
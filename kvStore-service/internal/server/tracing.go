@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	"GRPC-KV-Store-System/schemas/logging"
+)
+
+var tracer = otel.Tracer("GRPC-KV-Store-System/kvStore-service/internal/server")
+
+// tracedStore records a span for every call into the store, so the time
+// spent waiting on its locks shows apart from the rest of the request.
+// Missing keys are not errors on the span. Keys are redacted as they are in
+// the log.
+type tracedStore struct {
+	store.Store
+	ctx context.Context
+}
+
+// traced returns kv recording spans under the span of ctx, or kv itself
+// when ctx is not being traced.
+func traced(ctx context.Context, kv store.Store) store.Store {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return kv
+	}
+	return &tracedStore{Store: kv, ctx: ctx}
+}
+
+func (t *tracedStore) span(op, key string) trace.Span {
+	_, span := tracer.Start(t.ctx, "store."+op, trace.WithAttributes(attribute.String("kv.key", logging.RedactedKey(key))))
+	return span
+}
+
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *tracedStore) Set(key, value string) (int64, error) {
+	span := t.span("Set", key)
+	version, err := t.Store.Set(key, value)
+	end(span, err)
+	return version, err
+}
+
+func (t *tracedStore) SetWithTTL(key, value string, ttl time.Duration) (int64, error) {
+	span := t.span("SetWithTTL", key)
+	version, err := t.Store.SetWithTTL(key, value, ttl)
+	end(span, err)
+	return version, err
+}
+
+func (t *tracedStore) Get(key string) (string, error) {
+	span := t.span("Get", key)
+	value, err := t.Store.Get(key)
+	end(span, err)
+	return value, err
+}
+
+func (t *tracedStore) GetWithVersion(key string) (string, int64, error) {
+	span := t.span("GetWithVersion", key)
+	value, version, err := t.Store.GetWithVersion(key)
+	end(span, err)
+	return value, version, err
+}
+
+func (t *tracedStore) CompareAndSwap(key string, cond store.Condition, value string, ttl time.Duration) (int64, error) {
+	span := t.span("CompareAndSwap", key)
+	version, err := t.Store.CompareAndSwap(key, cond, value, ttl)
+	end(span, err)
+	return version, err
+}
+
+func (t *tracedStore) Increment(key string, delta int64, opts store.CounterOptions) (int64, int64, error) {
+	span := t.span("Increment", key)
+	value, version, err := t.Store.Increment(key, delta, opts)
+	end(span, err)
+	return value, version, err
+}
+
+func (t *tracedStore) Scan(opts store.ScanOptions) ([]store.KeyValue, bool, error) {
+	_, span := tracer.Start(t.ctx, "store.Scan", trace.WithAttributes(attribute.String("kv.prefix", logging.RedactedKey(opts.Prefix))))
+	kvs, more, err := t.Store.Scan(opts)
+	span.SetAttributes(attribute.Int("kv.keys", len(kvs)))
+	end(span, err)
+	return kvs, more, err
+}
+
+func (t *tracedStore) Txn(txn store.Txn) (store.TxnResult, error) {
+	_, span := tracer.Start(t.ctx, "store.Txn", trace.WithAttributes(
+		attribute.Int("kv.compares", len(txn.Compare)),
+		attribute.Int("kv.ops", len(txn.Success)+len(txn.Failure)),
+	))
+	result, err := t.Store.Txn(txn)
+	end(span, err)
+	return result, err
+}
+
+func (t *tracedStore) Delete(key string) error {
+	span := t.span("Delete", key)
+	err := t.Store.Delete(key)
+	end(span, err)
+	return err
+}
+
+func (t *tracedStore) TTL(key string) (time.Duration, error) {
+	span := t.span("TTL", key)
+	ttl, err := t.Store.TTL(key)
+	end(span, err)
+	return ttl, err
+}
+
+func (t *tracedStore) Persist(key string) error {
+	span := t.span("Persist", key)
+	err := t.Store.Persist(key)
+	end(span, err)
+	return err
+}
@@ -0,0 +1,22 @@
+package server
+
+import "unicode/utf8"
+
+// requestValue returns the value a request carries, preferring its bytes
+// field when set.
+func requestValue(value string, valueBytes []byte) string {
+	if len(valueBytes) > 0 {
+		return string(valueBytes)
+	}
+	return value
+}
+
+// responseValue splits a stored value into the string and bytes fields of
+// a response. Proto strings must be valid UTF-8, so any other value is sent
+// as bytes.
+func responseValue(value string) (string, []byte) {
+	if utf8.ValidString(value) {
+		return value, nil
+	}
+	return "", []byte(value)
+}
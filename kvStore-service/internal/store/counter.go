@@ -0,0 +1,74 @@
+package store
+
+import (
+	"math"
+	"strconv"
+)
+
+// CounterOptions shape an Increment.
+type CounterOptions struct {
+	// Initial is the value a missing key holds before delta is added.
+	Initial int64
+	// Min and Max, when set, bound the new value. An Increment that would
+	// leave them fails with ErrOutOfRange and leaves the key unchanged.
+	Min *int64
+	Max *int64
+}
+
+// add returns current plus delta, checking it against the bounds.
+func (o CounterOptions) add(current, delta int64) (int64, error) {
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, ErrOutOfRange
+	}
+
+	value := current + delta
+	if (o.Min != nil && value < *o.Min) || (o.Max != nil && value > *o.Max) {
+		return 0, ErrOutOfRange
+	}
+
+	return value, nil
+}
+
+// counterValue parses the integer a counter holds.
+func counterValue(e entry, exists bool, opts CounterOptions) (int64, error) {
+	if !exists {
+		return opts.Initial, nil
+	}
+
+	current, err := strconv.ParseInt(e.value, 10, 64)
+	if err != nil {
+		return 0, ErrNotInteger
+	}
+
+	return current, nil
+}
+
+func (i *InMemoryStore) Increment(key string, delta int64, opts CounterOptions) (int64, int64, error) {
+	if key == "" {
+		return 0, 0, ErrEmptyKey
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := i.clock()
+	current, exists := i.live(key, now)
+
+	counter, err := counterValue(current, exists, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	value, err := opts.add(counter, delta)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Counting does not reset the key's expiry.
+	version, err := i.commit(mutation{op: opPut, key: key, value: strconv.FormatInt(value, 10), expiresAt: current.expiresAt})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return value, version, nil
+}
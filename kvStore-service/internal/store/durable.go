@@ -0,0 +1,178 @@
+package store
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+type DurableOptions struct {
+	Fsync FsyncPolicy
+	// SweepInterval enables the background expiry sweeper when positive.
+	SweepInterval time.Duration
+	// SnapshotInterval enables periodic snapshots when positive.
+	SnapshotInterval time.Duration
+	// Memory bounds the keys kept in memory. Keys evicted to stay within
+	// it are deleted from the log too.
+	Memory MemoryLimit
+}
+
+// DurableStore is an InMemoryStore whose writes are appended to a
+// write-ahead log in dir before they are applied. Snapshots of the keyspace
+// let the log be truncated; opening the store loads the latest snapshot and
+// replays the log written after it.
+type DurableStore struct {
+	*InMemoryStore
+	dir string
+	wal *wal
+
+	// snapshotMu serialises snapshots so two never race on the same files.
+	snapshotMu sync.Mutex
+
+	stopSnapshots chan struct{}
+	snapshotsDone chan struct{}
+}
+
+func OpenDurableStore(dir string, opts DurableOptions) (*DurableStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	mem := newInMemoryStore()
+	mem.setMemoryLimit(opts.Memory)
+
+	// Data written before keys had versions is stamped with fresh
+	// revisions as it is replayed.
+	replay := func(m mutation) {
+		if m.version == 0 {
+			m.version = mem.rev + 1
+		}
+		mem.apply(m)
+	}
+
+	snapshot, err := loadSnapshot(dir, replay)
+	if err != nil {
+		return nil, err
+	}
+
+	mem.rev = max(mem.rev, snapshot.rev)
+
+	w, err := openWAL(dir, snapshot.walSeq, opts.Fsync, replay)
+	if err != nil {
+		return nil, err
+	}
+
+	// History from before the restart is not kept, so watches can only
+	// start after the recovered revision.
+	mem.events = newEventLog(eventLogSize, mem.rev)
+
+	mem.journal = w.append
+
+	if opts.SweepInterval > 0 {
+		mem.startSweeper(opts.SweepInterval, mem.removeExpired)
+	}
+
+	slog.Info("Recovered keys", "keys", len(mem.data), "dir", dir)
+
+	d := &DurableStore{
+		InMemoryStore: mem,
+		dir:           dir,
+		wal:           w,
+	}
+
+	if opts.SnapshotInterval > 0 {
+		d.stopSnapshots = make(chan struct{})
+		d.snapshotsDone = make(chan struct{})
+
+		go d.snapshotPeriodically(opts.SnapshotInterval)
+	}
+
+	return d, nil
+}
+
+// Snapshot writes the current keyspace to disk and drops the log segments
+// it makes redundant. Writes are only blocked while the keyspace is copied.
+func (d *DurableStore) Snapshot() (SnapshotInfo, error) {
+	d.snapshotMu.Lock()
+	defer d.snapshotMu.Unlock()
+
+	// Holding the read lock keeps writers, and so log appends, out while
+	// the copy is taken and the log moves to a new segment.
+	d.mu.RLock()
+	rev := d.rev
+	now := time.Now()
+	entries := make(map[string]entry, len(d.data))
+	for key, e := range d.data {
+		if !e.expired(now) {
+			entries[key] = e
+		}
+	}
+	walSeq, err := d.wal.rotate()
+	d.mu.RUnlock()
+
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+
+	info, err := writeSnapshot(d.dir, walSeq, rev, entries)
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+
+	if err := d.wal.removeSegmentsBefore(walSeq); err != nil {
+		return SnapshotInfo{}, err
+	}
+
+	return info, nil
+}
+
+func (d *DurableStore) snapshotPeriodically(interval time.Duration) {
+	defer close(d.snapshotsDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopSnapshots:
+			return
+		case <-ticker.C:
+			info, err := d.Snapshot()
+			if err != nil {
+				slog.Error("Failed to write snapshot", "error", err)
+				continue
+			}
+
+			slog.Info("Wrote snapshot", "path", info.Path, "keys", info.Keys, "size", info.Size)
+		}
+	}
+}
+
+// Err returns why the store no longer takes writes, or nil while it does.
+// Once the write-ahead log has failed every later write is rejected, so
+// the store has to be restarted to recover.
+func (d *DurableStore) Err() error {
+	return d.wal.err()
+}
+
+func (d *DurableStore) Close() error {
+	if d.stopSnapshots != nil {
+		close(d.stopSnapshots)
+		<-d.snapshotsDone
+	}
+
+	d.InMemoryStore.Close()
+
+	return d.wal.close()
+}
+
+// Ensure DurableStore implements Store, Snapshotter, MemoryReporter and
+// UsageReporter
+var (
+	_ Store          = (*DurableStore)(nil)
+	_ Snapshotter    = (*DurableStore)(nil)
+	_ MemoryReporter = (*DurableStore)(nil)
+	_ UsageReporter  = (*DurableStore)(nil)
+)
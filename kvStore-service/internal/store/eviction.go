@@ -0,0 +1,447 @@
+package store
+
+import (
+	"container/heap"
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entryOverhead is roughly what the map, the index and the eviction
+// bookkeeping spend on a key on top of its key and value bytes.
+const entryOverhead = 160
+
+// entrySize is the approximate memory a key holding value takes up.
+func entrySize(key, value string) int64 {
+	return int64(len(key)+len(value)) + entryOverhead
+}
+
+// EvictionPolicy picks the keys removed to make room for a write once a
+// store reaches its memory limit.
+type EvictionPolicy string
+
+const (
+	// NoEviction fails writes that would go over the limit with
+	// ErrMemoryLimit.
+	NoEviction EvictionPolicy = "noeviction"
+	// AllKeysLRU evicts the least recently read or written keys.
+	AllKeysLRU EvictionPolicy = "allkeys-lru"
+	// AllKeysLFU evicts the least frequently read or written keys.
+	AllKeysLFU EvictionPolicy = "allkeys-lfu"
+	// VolatileTTL evicts the keys closest to expiring. Keys without a TTL
+	// are never evicted, so writes fail with ErrMemoryLimit once only they
+	// are left.
+	VolatileTTL EvictionPolicy = "volatile-ttl"
+)
+
+func ParseEvictionPolicy(s string) (EvictionPolicy, error) {
+	switch policy := EvictionPolicy(strings.ToLower(s)); policy {
+	case NoEviction, AllKeysLRU, AllKeysLFU, VolatileTTL:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("invalid eviction policy %q, expected noeviction, allkeys-lru, allkeys-lfu or volatile-ttl", s)
+}
+
+// MemoryLimit bounds the approximate memory a store's keys take up.
+type MemoryLimit struct {
+	// MaxBytes is the budget. Zero means no limit.
+	MaxBytes int64
+	Policy   EvictionPolicy
+}
+
+// MemoryStats reports how much of its budget a store uses and what it has
+// evicted to stay within it.
+type MemoryStats struct {
+	Keys      int
+	UsedBytes int64
+	MaxBytes  int64
+	Policy    EvictionPolicy
+	// Evictions counts the keys removed to make room for writes.
+	Evictions int64
+	// RejectedWrites counts the writes failed with ErrMemoryLimit.
+	RejectedWrites int64
+	// Expirations counts the keys removed because their TTL ran out.
+	Expirations int64
+}
+
+// MemoryReporter is implemented by stores that account for the memory
+// their keys take up.
+type MemoryReporter interface {
+	MemoryStats() MemoryStats
+}
+
+// evictor tracks keys for an eviction policy. added and removed are called
+// under the store's write lock, accessed under its read lock, so evictors
+// guard themselves.
+type evictor interface {
+	// added is called when key is written.
+	added(key string, e entry)
+	// accessed is called when key is read.
+	accessed(key string)
+	removed(key string)
+	// victim stops tracking the next key to evict and returns it, or
+	// reports false if no key can be evicted.
+	victim() (string, bool)
+}
+
+func newEvictor(policy EvictionPolicy) evictor {
+	switch policy {
+	case AllKeysLRU:
+		return &lruEvictor{elems: make(map[string]*list.Element), order: list.New()}
+	case AllKeysLFU:
+		return &lfuEvictor{items: make(map[string]*lfuItem)}
+	case VolatileTTL:
+		return &ttlEvictor{items: make(map[string]*ttlItem)}
+	}
+
+	return nil
+}
+
+// lruEvictor keeps keys in the order they were last used, most recent at
+// the front.
+type lruEvictor struct {
+	mu    sync.Mutex
+	elems map[string]*list.Element
+	order *list.List
+}
+
+func (l *lruEvictor) added(key string, _ entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.elems[key]; ok {
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.elems[key] = l.order.PushFront(key)
+}
+
+func (l *lruEvictor) accessed(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The key may have been removed since it was read.
+	if elem, ok := l.elems[key]; ok {
+		l.order.MoveToFront(elem)
+	}
+}
+
+func (l *lruEvictor) removed(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.elems[key]; ok {
+		l.order.Remove(elem)
+		delete(l.elems, key)
+	}
+}
+
+func (l *lruEvictor) victim() (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem := l.order.Back()
+	if elem == nil {
+		return "", false
+	}
+
+	key := l.order.Remove(elem).(string)
+	delete(l.elems, key)
+
+	return key, true
+}
+
+type lfuItem struct {
+	key   string
+	uses  int64
+	tick  int64 // last use, breaking ties between equally used keys
+	index int
+}
+
+// lfuEvictor keeps keys in a heap ordered by how often they were used,
+// evicting the least recently used of the least used keys first.
+type lfuEvictor struct {
+	mu    sync.Mutex
+	items map[string]*lfuItem
+	heap  lfuHeap
+	tick  int64
+}
+
+func (l *lfuEvictor) added(key string, _ entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.use(key) {
+		l.tick++
+		item := &lfuItem{key: key, uses: 1, tick: l.tick}
+		l.items[key] = item
+		heap.Push(&l.heap, item)
+	}
+}
+
+func (l *lfuEvictor) accessed(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.use(key)
+}
+
+// use counts a use of key if it is tracked. Callers must hold mu.
+func (l *lfuEvictor) use(key string) bool {
+	item, ok := l.items[key]
+	if !ok {
+		return false
+	}
+
+	l.tick++
+	item.uses++
+	item.tick = l.tick
+	heap.Fix(&l.heap, item.index)
+
+	return true
+}
+
+func (l *lfuEvictor) removed(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if item, ok := l.items[key]; ok {
+		heap.Remove(&l.heap, item.index)
+		delete(l.items, key)
+	}
+}
+
+func (l *lfuEvictor) victim() (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.heap) == 0 {
+		return "", false
+	}
+
+	item := heap.Pop(&l.heap).(*lfuItem)
+	delete(l.items, item.key)
+
+	return item.key, true
+}
+
+type lfuHeap []*lfuItem
+
+func (h lfuHeap) Len() int { return len(h) }
+
+func (h lfuHeap) Less(a, b int) bool {
+	if h[a].uses != h[b].uses {
+		return h[a].uses < h[b].uses
+	}
+	return h[a].tick < h[b].tick
+}
+
+func (h lfuHeap) Swap(a, b int) {
+	h[a], h[b] = h[b], h[a]
+	h[a].index = a
+	h[b].index = b
+}
+
+func (h *lfuHeap) Push(x any) {
+	item := x.(*lfuItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *lfuHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+type ttlItem struct {
+	key       string
+	expiresAt time.Time
+	index     int
+}
+
+// ttlEvictor keeps the keys that have a TTL in a heap ordered by when they
+// expire. Reads do not change the order.
+type ttlEvictor struct {
+	mu    sync.Mutex
+	items map[string]*ttlItem
+	heap  ttlHeap
+}
+
+func (t *ttlEvictor) added(key string, e entry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := t.items[key]
+	switch {
+	case e.expiresAt.IsZero():
+		if ok {
+			heap.Remove(&t.heap, item.index)
+			delete(t.items, key)
+		}
+	case ok:
+		item.expiresAt = e.expiresAt
+		heap.Fix(&t.heap, item.index)
+	default:
+		item = &ttlItem{key: key, expiresAt: e.expiresAt}
+		t.items[key] = item
+		heap.Push(&t.heap, item)
+	}
+}
+
+func (t *ttlEvictor) accessed(string) {}
+
+func (t *ttlEvictor) removed(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if item, ok := t.items[key]; ok {
+		heap.Remove(&t.heap, item.index)
+		delete(t.items, key)
+	}
+}
+
+func (t *ttlEvictor) victim() (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.heap) == 0 {
+		return "", false
+	}
+
+	item := heap.Pop(&t.heap).(*ttlItem)
+	delete(t.items, item.key)
+
+	return item.key, true
+}
+
+type ttlHeap []*ttlItem
+
+func (h ttlHeap) Len() int { return len(h) }
+
+func (h ttlHeap) Less(a, b int) bool { return h[a].expiresAt.Before(h[b].expiresAt) }
+
+func (h ttlHeap) Swap(a, b int) {
+	h[a], h[b] = h[b], h[a]
+	h[a].index = a
+	h[b].index = b
+}
+
+func (h *ttlHeap) Push(x any) {
+	item := x.(*ttlItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *ttlHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+// reserve makes room for ms under the memory limit, returning the deletes
+// of the keys evicted for it. Writes that do not grow the keyspace always
+// go through, even over the limit. Callers must hold the write lock.
+func (i *InMemoryStore) reserve(ms []mutation) ([]mutation, error) {
+	if i.limit.MaxBytes <= 0 {
+		return nil, nil
+	}
+
+	// A key written more than once by the same commit only counts once.
+	sizes := make(map[string]int64, len(ms))
+	var growth int64
+	for _, m := range ms {
+		current, seen := sizes[m.key]
+		if !seen {
+			if e, exists := i.data[m.key]; exists {
+				current = entrySize(m.key, e.value)
+			}
+		}
+
+		var size int64
+		if m.op == opPut {
+			size = entrySize(m.key, m.value)
+		}
+
+		growth += size - current
+		sizes[m.key] = size
+	}
+
+	need := i.used + growth - i.limit.MaxBytes
+	if growth <= 0 || need <= 0 {
+		return nil, nil
+	}
+
+	if i.evictor == nil || growth > i.limit.MaxBytes {
+		i.rejectedWrites++
+		return nil, ErrMemoryLimit
+	}
+
+	var evicted []mutation
+	var skipped []string
+	for need > 0 {
+		key, ok := i.evictor.victim()
+		if !ok {
+			break
+		}
+
+		// Keys being written are not evicted to make room for themselves.
+		if _, writing := sizes[key]; writing {
+			skipped = append(skipped, key)
+			continue
+		}
+
+		need -= entrySize(key, i.data[key].value)
+		evicted = append(evicted, mutation{op: opDelete, key: key})
+	}
+
+	for _, key := range skipped {
+		i.evictor.added(key, i.data[key])
+	}
+
+	if need > 0 {
+		i.unevict(evicted)
+		i.rejectedWrites++
+		return nil, ErrMemoryLimit
+	}
+
+	return evicted, nil
+}
+
+// unevict tracks the keys of evicted again after the commit they were
+// evicted for failed. Callers must hold the write lock.
+func (i *InMemoryStore) unevict(evicted []mutation) {
+	for _, m := range evicted {
+		i.evictor.added(m.key, i.data[m.key])
+	}
+}
+
+// touch records a read of key for the eviction policy.
+func (i *InMemoryStore) touch(key string) {
+	if i.evictor != nil {
+		i.evictor.accessed(key)
+	}
+}
+
+func (i *InMemoryStore) MemoryStats() MemoryStats {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return MemoryStats{
+		Keys:           len(i.data),
+		UsedBytes:      i.used,
+		MaxBytes:       i.limit.MaxBytes,
+		Policy:         i.limit.Policy,
+		Evictions:      i.evictions,
+		RejectedWrites: i.rejectedWrites,
+		Expirations:    i.expirations,
+	}
+}
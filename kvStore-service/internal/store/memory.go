@@ -1,46 +1,243 @@
 package store
 
 import (
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/google/btree"
+)
+
+type entry struct {
+	value     string
+	version   int64
+	expiresAt time.Time // zero means the entry never expires
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+type opType byte
+
+const (
+	opPut    opType = 1
+	opDelete opType = 2
 )
 
+// mutation is the resulting state of a single key after a write. Replaying
+// the same mutations in order always rebuilds the same keyspace.
+type mutation struct {
+	op        opType
+	key       string
+	value     string
+	version   int64
+	expiresAt time.Time
+}
+
 type InMemoryStore struct {
 	mu   sync.RWMutex
-	data map[string]string
+	data map[string]entry
+
+	// index keeps the keys of data in order for range scans.
+	index *btree.BTreeG[string]
+
+	// rev is the revision of the latest write. Every write is stamped with
+	// the next revision, which becomes the version of the keys it touched.
+	rev int64
+
+	// expiring holds the keys that have a TTL so the sweeper does not
+	// have to walk the whole keyspace.
+	expiring map[string]struct{}
+
+	// events keeps recent writes for watches.
+	events *eventLog
+
+	// seq, when set, stamps and logs writes in place of rev and events.
+	// It is shared by the stripes of a StripedStore.
+	seq *sequencer
+
+	// clock is the time writes are made at. Reads and the sweeper use the
+	// wall clock.
+	clock func() time.Time
+
+	// journal, when set, is handed every mutation before it is applied.
+	// If it fails the mutation is not applied.
+	journal func([]mutation) error
+
+	// used is the approximate memory the keys in data take up. Once it
+	// would pass limit, writes evict keys picked by evictor, or fail when
+	// there is none.
+	used           int64
+	limit          MemoryLimit
+	evictor        evictor
+	evictions      int64
+	rejectedWrites int64
+	// expirations counts the keys removed because their TTL ran out.
+	expirations int64
+	// keepExpired leaves expired keys for writes to remove rather than
+	// reads, which only hide them, so replicas remove keys only where
+	// their log says.
+	keepExpired bool
+
+	// usage is what the keys of each namespace hold, for quotas.
+	usage map[string]Usage
+
+	stopSweeper chan struct{}
+	sweeperDone chan struct{}
+	closeOnce   sync.Once
 }
 
 func CreateStore() Store {
+	return newInMemoryStore()
+}
+
+// CreateExpiringStore returns an InMemoryStore that, on top of expiring keys
+// lazily when they are read, removes expired keys in the background every
+// sweepInterval. Close stops the sweeper.
+func CreateExpiringStore(sweepInterval time.Duration) Store {
+	i := newInMemoryStore()
+	i.startSweeper(sweepInterval, i.removeExpired)
+
+	return i
+}
+
+// CreateBoundedStore returns an expiring InMemoryStore whose keys are kept
+// within limit.
+func CreateBoundedStore(sweepInterval time.Duration, limit MemoryLimit) Store {
+	i := newInMemoryStore()
+	i.setMemoryLimit(limit)
+	i.startSweeper(sweepInterval, i.removeExpired)
+
+	return i
+}
+
+func newInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
-		data: make(map[string]string),
+		data:     make(map[string]entry),
+		index:    btree.NewOrderedG[string](32),
+		expiring: make(map[string]struct{}),
+		usage:    make(map[string]Usage),
+		events:   newEventLog(eventLogSize, 0),
+		clock:    time.Now,
 	}
 }
 
-func (i *InMemoryStore) Set(key, value string) error {
+func (i *InMemoryStore) Set(key, value string) (int64, error) {
 	if key == "" {
-		return ErrEmptyKey
+		return 0, ErrEmptyKey
 	}
 
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	i.data[key] = value
-	return nil
+	return i.commit(mutation{op: opPut, key: key, value: value})
+}
+
+func (i *InMemoryStore) SetWithTTL(key, value string, ttl time.Duration) (int64, error) {
+	if key == "" {
+		return 0, ErrEmptyKey
+	}
+
+	if ttl <= 0 {
+		return 0, ErrInvalidTTL
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.commit(mutation{op: opPut, key: key, value: value, expiresAt: i.clock().Add(ttl)})
 }
 
 func (i *InMemoryStore) Get(key string) (string, error) {
+	value, _, err := i.GetWithVersion(key)
+	return value, err
+}
+
+func (i *InMemoryStore) GetWithVersion(key string) (string, int64, error) {
 	if key == "" {
-		return "", ErrEmptyKey
+		return "", 0, ErrEmptyKey
 	}
 
+	e, err := i.lookup(key)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return e.value, e.version, nil
+}
+
+func (i *InMemoryStore) CompareAndSwap(key string, cond Condition, value string, ttl time.Duration) (int64, error) {
+	if key == "" {
+		return 0, ErrEmptyKey
+	}
+
+	if ttl < 0 {
+		return 0, ErrInvalidTTL
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	current, exists := i.live(key, i.clock())
+	if !cond.holds(current, exists) {
+		return 0, ErrVersionMismatch
+	}
+
+	m := mutation{op: opPut, key: key, value: value}
+	if ttl > 0 {
+		m.expiresAt = i.clock().Add(ttl)
+	}
+
+	return i.commit(m)
+}
+
+func (i *InMemoryStore) Scan(opts ScanOptions) ([]KeyValue, bool, error) {
+	if opts.Limit < 0 {
+		return nil, false, ErrInvalidLimit
+	}
+
+	start := max(opts.Start, opts.Prefix)
+	now := time.Now()
+
 	i.mu.RLock()
 	defer i.mu.RUnlock()
 
-	value, exists := i.data[key]
-	if !exists {
-		return "", ErrKeyNotFound
+	var items []KeyValue
+	more := false
+
+	i.index.AscendGreaterOrEqual(start, func(key string) bool {
+		if opts.End != "" && key >= opts.End {
+			return false
+		}
+
+		if !strings.HasPrefix(key, opts.Prefix) {
+			return false
+		}
+
+		e := i.data[key]
+		if e.expired(now) {
+			return true
+		}
+
+		if opts.Limit > 0 && len(items) == opts.Limit {
+			more = true
+			return false
+		}
+
+		items = append(items, KeyValue{Key: key, Value: e.value, Version: e.version})
+		return true
+	})
+
+	return items, more, nil
+}
+
+func (i *InMemoryStore) Watch(opts WatchOptions) (*Watch, error) {
+	if opts.Key == "" && !opts.Prefix {
+		return nil, ErrEmptyKey
 	}
 
-	return value, nil
+	return i.events.watch(opts)
 }
 
 func (i *InMemoryStore) Delete(key string) error {
@@ -51,10 +248,293 @@ func (i *InMemoryStore) Delete(key string) error {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	if _, exists := i.data[key]; !exists {
+	if _, exists := i.live(key, i.clock()); !exists {
 		return ErrKeyNotFound
 	}
 
-	delete(i.data, key)
+	_, err := i.commit(mutation{op: opDelete, key: key})
+	return err
+}
+
+func (i *InMemoryStore) TTL(key string) (time.Duration, error) {
+	if key == "" {
+		return 0, ErrEmptyKey
+	}
+
+	e, err := i.lookup(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if e.expiresAt.IsZero() {
+		return NoExpiry, nil
+	}
+
+	return time.Until(e.expiresAt), nil
+}
+
+func (i *InMemoryStore) Persist(key string) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	e, exists := i.live(key, i.clock())
+	if !exists {
+		return ErrKeyNotFound
+	}
+
+	// Clearing the expiry does not change the value, so the key keeps
+	// its version.
+	_, err := i.commit(mutation{op: opPut, key: key, value: e.value, version: e.version})
+	return err
+}
+
+func (i *InMemoryStore) Close() error {
+	i.closeOnce.Do(func() {
+		if i.stopSweeper != nil {
+			close(i.stopSweeper)
+			<-i.sweeperDone
+		}
+
+		i.events.close()
+	})
+
 	return nil
 }
+
+// commit stamps ms that have no version yet with the next revision, journals
+// them, applies them and hands them to watches, returning the revision. Keys
+// evicted to make room for ms are deleted at the same revision. Callers must
+// hold the write lock.
+func (i *InMemoryStore) commit(ms ...mutation) (int64, error) {
+	evicted, err := i.reserve(ms)
+	if err != nil {
+		return 0, err
+	}
+	if len(evicted) > 0 {
+		ms = append(evicted, ms...)
+	}
+
+	if i.seq != nil {
+		rev := i.seq.commit(ms)
+		for _, m := range ms {
+			i.apply(m)
+		}
+		return rev, nil
+	}
+
+	prev := i.rev
+	rev := prev + 1
+	for n := range ms {
+		if ms[n].version == 0 {
+			ms[n].version = rev
+		}
+	}
+
+	if i.journal != nil {
+		if err := i.journal(ms); err != nil {
+			i.unevict(evicted)
+			return 0, err
+		}
+	}
+
+	for _, m := range ms {
+		i.apply(m)
+	}
+
+	i.evictions += int64(len(evicted))
+
+	i.events.append(prev, ms)
+
+	return rev, nil
+}
+
+// apply performs m without journaling it. Callers must hold the write lock.
+func (i *InMemoryStore) apply(m mutation) {
+	switch m.op {
+	case opPut:
+		i.put(m.key, entry{value: m.value, version: m.version, expiresAt: m.expiresAt})
+	case opDelete:
+		i.remove(m.key)
+	}
+
+	i.rev = max(i.rev, m.version)
+}
+
+// lookup returns the live entry for key. An entry found to be expired is
+// removed, unless the store keeps expired keys, before ErrKeyNotFound is
+// returned.
+func (i *InMemoryStore) lookup(key string) (entry, error) {
+	now := time.Now()
+
+	i.mu.RLock()
+	e, exists := i.data[key]
+	i.mu.RUnlock()
+
+	if !exists {
+		return entry{}, ErrKeyNotFound
+	}
+
+	if !e.expired(now) {
+		i.touch(key)
+		return e, nil
+	}
+
+	if i.keepExpired {
+		return entry{}, ErrKeyNotFound
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// The key may have been overwritten between the two locks.
+	if e, exists = i.live(key, now); exists {
+		i.touch(key)
+		return e, nil
+	}
+
+	return entry{}, ErrKeyNotFound
+}
+
+// live returns the entry for key if it exists and has not expired, removing
+// it when it has. Callers must hold the write lock.
+func (i *InMemoryStore) live(key string, now time.Time) (entry, bool) {
+	e, exists := i.data[key]
+	if !exists {
+		return entry{}, false
+	}
+
+	if e.expired(now) {
+		i.remove(key)
+		i.expirations++
+		return entry{}, false
+	}
+
+	return e, true
+}
+
+// put stores e under key. Callers must hold the write lock.
+func (i *InMemoryStore) put(key string, e entry) {
+	if old, exists := i.data[key]; exists {
+		i.used -= entrySize(key, old.value)
+		i.account(key, old.value, -1)
+	} else {
+		i.index.ReplaceOrInsert(key)
+	}
+
+	i.data[key] = e
+	i.used += entrySize(key, e.value)
+	i.account(key, e.value, 1)
+
+	// Namespaces are never evicted, only the keys in them.
+	if i.evictor != nil && !strings.HasPrefix(key, namespaceMarker) {
+		i.evictor.added(key, e)
+	}
+
+	if e.expiresAt.IsZero() {
+		delete(i.expiring, key)
+	} else {
+		i.expiring[key] = struct{}{}
+	}
+}
+
+// remove deletes key. Callers must hold the write lock.
+func (i *InMemoryStore) remove(key string) {
+	if e, exists := i.data[key]; exists {
+		i.index.Delete(key)
+		i.used -= entrySize(key, e.value)
+		i.account(key, e.value, -1)
+
+		if i.evictor != nil {
+			i.evictor.removed(key)
+		}
+	}
+
+	delete(i.data, key)
+	delete(i.expiring, key)
+}
+
+// setMemoryLimit must be called before any key is stored, so the evictor
+// sees every key.
+func (i *InMemoryStore) setMemoryLimit(limit MemoryLimit) {
+	if limit.Policy == "" {
+		limit.Policy = NoEviction
+	}
+
+	i.limit = limit
+	if limit.MaxBytes > 0 {
+		i.evictor = newEvictor(limit.Policy)
+	}
+}
+
+// startSweeper calls sweep every interval until Close.
+func (i *InMemoryStore) startSweeper(interval time.Duration, sweep func()) {
+	i.stopSweeper = make(chan struct{})
+	i.sweeperDone = make(chan struct{})
+
+	go i.sweep(interval, sweep)
+}
+
+func (i *InMemoryStore) sweep(interval time.Duration, sweep func()) {
+	defer close(i.sweeperDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-i.stopSweeper:
+			return
+		case <-ticker.C:
+			sweep()
+		}
+	}
+}
+
+func (i *InMemoryStore) removeExpired() {
+	now := time.Now()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for key := range i.expiring {
+		if i.data[key].expired(now) {
+			i.remove(key)
+			i.expirations++
+		}
+	}
+}
+
+// expiredKeys returns up to limit keys that have expired by now.
+func (i *InMemoryStore) expiredKeys(now time.Time, limit int) []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var keys []string
+	for key := range i.expiring {
+		if len(keys) == limit {
+			break
+		}
+		if i.data[key].expired(now) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// expire removes those of keys that have expired by the time writes are
+// made at.
+func (i *InMemoryStore) expire(keys []string) {
+	now := i.clock()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, key := range keys {
+		i.live(key, now)
+	}
+}
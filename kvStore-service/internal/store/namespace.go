@@ -0,0 +1,416 @@
+package store
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidNamespace  = errors.New("namespace must be 1 to 64 letters, digits, '_', '-' or '.'")
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	ErrNamespaceExists   = errors.New("namespace already exists")
+	ErrReservedKey       = errors.New("keys starting with 0xfe or 0xff are reserved for namespaces")
+	ErrNoUsage           = errors.New("store does not account for namespace usage")
+)
+
+const (
+	// namespaceMarker starts the key recording that a namespace exists,
+	// followed by its name.
+	namespaceMarker = "\xfe"
+	// namespaceData starts the keys stored in a namespace, followed by its
+	// name, a '/' and the key. Neither byte can start a valid UTF-8 key, so
+	// only clients of the default namespace sending raw bytes could clash.
+	namespaceData = "\xff"
+
+	// dropChunk is how many keys DropNamespace deletes at a time.
+	dropChunk = 500
+)
+
+var namespacePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+func validateNamespace(name string) error {
+	if !namespacePattern.MatchString(name) {
+		return ErrInvalidNamespace
+	}
+	return nil
+}
+
+func isReserved(key string) bool {
+	return key >= namespaceMarker
+}
+
+// Usage is what a namespace holds: its keys, and the bytes of those keys
+// and their values, without the store's own bookkeeping.
+type Usage struct {
+	Keys  int64
+	Bytes int64
+}
+
+// UsageReporter is implemented by stores that account for the keys of each
+// namespace.
+type UsageReporter interface {
+	NamespaceUsage(name string) Usage
+}
+
+// namespaceOf returns the namespace a stored key belongs to and the length
+// of the key within it. Namespace markers belong to none.
+func namespaceOf(key string) (string, int, bool) {
+	if !isReserved(key) {
+		return "", len(key), true
+	}
+
+	if rest, ok := strings.CutPrefix(key, namespaceData); ok {
+		if name, k, ok := strings.Cut(rest, "/"); ok {
+			return name, len(k), true
+		}
+	}
+
+	return "", 0, false
+}
+
+// account adds a key holding value to the usage of its namespace, or takes
+// it away when sign is -1. Callers must hold the write lock.
+func (i *InMemoryStore) account(key, value string, sign int64) {
+	name, keyLen, ok := namespaceOf(key)
+	if !ok {
+		return
+	}
+
+	u := i.usage[name]
+	u.Keys += sign
+	u.Bytes += sign * int64(keyLen+len(value))
+
+	if u.Keys == 0 {
+		delete(i.usage, name)
+		return
+	}
+	i.usage[name] = u
+}
+
+func (i *InMemoryStore) NamespaceUsage(name string) Usage {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.usage[name]
+}
+
+// Namespaces splits a store into isolated keyspaces. The default namespace,
+// named "", holds the store's keys as they are; every other namespace keeps
+// its keys under a prefix of the underlying store, so they are persisted,
+// replicated and counted against the memory limit like any other key.
+type Namespaces struct {
+	store Store
+}
+
+func CreateNamespaces(s Store) *Namespaces {
+	return &Namespaces{store: s}
+}
+
+// Create adds an empty namespace, failing with ErrNamespaceExists if it is
+// already there.
+func (n *Namespaces) Create(name string) error {
+	if err := validateNamespace(name); err != nil {
+		return err
+	}
+
+	_, err := n.store.CompareAndSwap(namespaceMarker+name, Condition{}, "", 0)
+	if errors.Is(err, ErrVersionMismatch) {
+		return ErrNamespaceExists
+	}
+	return err
+}
+
+// List returns the names of every namespace other than the default one,
+// in order.
+func (n *Namespaces) List() ([]string, error) {
+	var names []string
+	opts := ScanOptions{Prefix: namespaceMarker, Limit: dropChunk}
+	for {
+		items, more, err := n.store.Scan(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			names = append(names, strings.TrimPrefix(item.Key, namespaceMarker))
+		}
+
+		if !more {
+			return names, nil
+		}
+		opts.Start = items[len(items)-1].Key + "\x00"
+	}
+}
+
+// Drop removes a namespace and every key in it, returning how many keys
+// were deleted. The namespace is gone before its keys are, so new requests
+// for it fail at once, but a write already in flight when it is dropped
+// may outlive it until the namespace is created and dropped again.
+func (n *Namespaces) Drop(name string) (int, error) {
+	if err := validateNamespace(name); err != nil {
+		return 0, err
+	}
+
+	if err := n.store.Delete(namespaceMarker + name); err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return 0, ErrNamespaceNotFound
+		}
+		return 0, err
+	}
+
+	// Deleting in chunks keeps each transaction, and the time it holds
+	// the store lock, small.
+	dropped := 0
+	opts := ScanOptions{Prefix: namespaceData + name + "/", Limit: dropChunk}
+	for {
+		items, _, err := n.store.Scan(opts)
+		if err != nil {
+			return dropped, err
+		}
+		if len(items) == 0 {
+			return dropped, nil
+		}
+
+		ops := make([]TxnOp, 0, len(items))
+		for _, item := range items {
+			ops = append(ops, TxnOp{Type: TxnDelete, Key: item.Key})
+		}
+
+		result, err := n.store.Txn(Txn{Success: ops})
+		if err != nil {
+			return dropped, err
+		}
+
+		for _, r := range result.Results {
+			if r.Found {
+				dropped++
+			}
+		}
+	}
+}
+
+// Get returns the keyspace of a namespace, failing with
+// ErrNamespaceNotFound if it has not been created. Closing it does not
+// close the underlying store.
+func (n *Namespaces) Get(name string) (Store, error) {
+	if name == "" {
+		return n.Default(), nil
+	}
+
+	if err := validateNamespace(name); err != nil {
+		return nil, err
+	}
+
+	if _, err := n.store.Get(namespaceMarker + name); err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil, ErrNamespaceNotFound
+		}
+		return nil, err
+	}
+
+	return &namespace{store: n.store, prefix: namespaceData + name + "/"}, nil
+}
+
+// Usage returns what a namespace holds. Expired keys count until they are
+// swept.
+func (n *Namespaces) Usage(name string) (Usage, error) {
+	if _, err := n.Get(name); err != nil {
+		return Usage{}, err
+	}
+
+	reporter, ok := n.store.(UsageReporter)
+	if !ok {
+		return Usage{}, ErrNoUsage
+	}
+
+	return reporter.NamespaceUsage(name), nil
+}
+
+// Default returns the keyspace of the default namespace, which hides the
+// keys of the other namespaces.
+func (n *Namespaces) Default() Store {
+	return &namespace{store: n.store}
+}
+
+// namespace is the keyspace of one namespace. Its keys are stored with
+// prefix in front of them; the default namespace has no prefix and may not
+// use the keys the others are stored under.
+type namespace struct {
+	store  Store
+	prefix string
+}
+
+func (ns *namespace) key(key string) (string, error) {
+	if key == "" {
+		return "", ErrEmptyKey
+	}
+
+	if ns.prefix == "" && isReserved(key) {
+		return "", ErrReservedKey
+	}
+
+	return ns.prefix + key, nil
+}
+
+func (ns *namespace) Set(key, value string) (int64, error) {
+	stored, err := ns.key(key)
+	if err != nil {
+		return 0, err
+	}
+	return ns.store.Set(stored, value)
+}
+
+func (ns *namespace) SetWithTTL(key, value string, ttl time.Duration) (int64, error) {
+	stored, err := ns.key(key)
+	if err != nil {
+		return 0, err
+	}
+	return ns.store.SetWithTTL(stored, value, ttl)
+}
+
+func (ns *namespace) Get(key string) (string, error) {
+	stored, err := ns.key(key)
+	if err != nil {
+		return "", err
+	}
+	return ns.store.Get(stored)
+}
+
+func (ns *namespace) GetWithVersion(key string) (string, int64, error) {
+	stored, err := ns.key(key)
+	if err != nil {
+		return "", 0, err
+	}
+	return ns.store.GetWithVersion(stored)
+}
+
+func (ns *namespace) CompareAndSwap(key string, cond Condition, value string, ttl time.Duration) (int64, error) {
+	stored, err := ns.key(key)
+	if err != nil {
+		return 0, err
+	}
+	return ns.store.CompareAndSwap(stored, cond, value, ttl)
+}
+
+func (ns *namespace) Increment(key string, delta int64, opts CounterOptions) (int64, int64, error) {
+	stored, err := ns.key(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ns.store.Increment(stored, delta, opts)
+}
+
+func (ns *namespace) Delete(key string) error {
+	stored, err := ns.key(key)
+	if err != nil {
+		return err
+	}
+	return ns.store.Delete(stored)
+}
+
+func (ns *namespace) TTL(key string) (time.Duration, error) {
+	stored, err := ns.key(key)
+	if err != nil {
+		return 0, err
+	}
+	return ns.store.TTL(stored)
+}
+
+func (ns *namespace) Persist(key string) error {
+	stored, err := ns.key(key)
+	if err != nil {
+		return err
+	}
+	return ns.store.Persist(stored)
+}
+
+func (ns *namespace) Scan(opts ScanOptions) ([]KeyValue, bool, error) {
+	if ns.prefix == "" {
+		// Stop before the keys of the other namespaces.
+		if opts.End == "" || opts.End > namespaceMarker {
+			opts.End = namespaceMarker
+		}
+		return ns.store.Scan(opts)
+	}
+
+	if opts.Start != "" {
+		opts.Start = ns.prefix + opts.Start
+	}
+	if opts.End != "" {
+		opts.End = ns.prefix + opts.End
+	}
+	opts.Prefix = ns.prefix + opts.Prefix
+
+	items, more, err := ns.store.Scan(opts)
+	for n := range items {
+		items[n].Key = strings.TrimPrefix(items[n].Key, ns.prefix)
+	}
+
+	return items, more, err
+}
+
+func (ns *namespace) Txn(txn Txn) (TxnResult, error) {
+	stored := Txn{
+		Compare: make([]Compare, len(txn.Compare)),
+		Success: make([]TxnOp, len(txn.Success)),
+		Failure: make([]TxnOp, len(txn.Failure)),
+	}
+
+	var err error
+	for n, c := range txn.Compare {
+		if c.Key, err = ns.key(c.Key); err != nil {
+			return TxnResult{}, err
+		}
+		stored.Compare[n] = c
+	}
+	for n, op := range txn.Success {
+		if op.Key, err = ns.key(op.Key); err != nil {
+			return TxnResult{}, err
+		}
+		stored.Success[n] = op
+	}
+	for n, op := range txn.Failure {
+		if op.Key, err = ns.key(op.Key); err != nil {
+			return TxnResult{}, err
+		}
+		stored.Failure[n] = op
+	}
+
+	result, err := ns.store.Txn(stored)
+	for n := range result.Results {
+		result.Results[n].Key = strings.TrimPrefix(result.Results[n].Key, ns.prefix)
+	}
+
+	return result, err
+}
+
+func (ns *namespace) Watch(opts WatchOptions) (*Watch, error) {
+	if opts.Key == "" && !opts.Prefix {
+		return nil, ErrEmptyKey
+	}
+
+	if ns.prefix == "" && isReserved(opts.Key) {
+		return nil, ErrReservedKey
+	}
+	opts.Key = ns.prefix + opts.Key
+
+	w, err := ns.store.Watch(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.filter(func(ev Event) (Event, bool) {
+		if ns.prefix == "" {
+			return ev, !isReserved(ev.Key)
+		}
+		ev.Key = strings.TrimPrefix(ev.Key, ns.prefix)
+		return ev, true
+	}), nil
+}
+
+// Close does nothing, since the underlying store is shared.
+func (ns *namespace) Close() error {
+	return nil
+}
@@ -0,0 +1,515 @@
+package store
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+
+	"GRPC-KV-Store-System/schemas/logging"
+)
+
+const (
+	defaultApplyTimeout   = 5 * time.Second
+	raftSnapshotsRetained = 2
+
+	// maxExpiredPerCommand bounds the keys a single expiry command
+	// removes, so a sweep of many keys does not make one huge log entry.
+	maxExpiredPerCommand = 1000
+)
+
+// NotLeaderError is returned for writes sent to a node that is not the
+// leader. Leader is the ID of the current leader, or empty while there is
+// none.
+type NotLeaderError struct {
+	Leader string
+}
+
+func (e *NotLeaderError) Error() string {
+	if e.Leader == "" {
+		return "node is not the leader and no leader is known"
+	}
+	return fmt.Sprintf("node is not the leader, the leader is %s", e.Leader)
+}
+
+func (e *NotLeaderError) Is(target error) bool {
+	return target == ErrNotLeader
+}
+
+type ReplicationOptions struct {
+	// ID names this node in the cluster. Other nodes forward writes to it
+	// at this address, so it should be the node's advertised gRPC address.
+	ID string
+	// Transport carries raft traffic between nodes. The store closes it.
+	Transport raft.Transport
+	// Peers bootstraps a new cluster of these nodes, this one included,
+	// when the node has no raft state yet. Without peers the node
+	// bootstraps a cluster of its own.
+	Peers []raft.Server
+	// Dir keeps the raft log and snapshots. Empty keeps them in memory.
+	Dir string
+	// ApplyTimeout bounds how long a write waits to be committed.
+	ApplyTimeout time.Duration
+	// SweepInterval enables the background expiry sweeper when positive.
+	// Only the leader's sweeps remove keys.
+	SweepInterval time.Duration
+	// Config tunes raft. raft.DefaultConfig is used when nil.
+	Config *raft.Config
+}
+
+// ReplicatedStore is an InMemoryStore kept in step across a cluster by a
+// raft log. Writes are sent to the leader, which appends them to the log;
+// every node then runs them against its own copy in log order. They run
+// at the time the leader proposed them, so all nodes assign the same
+// versions and expiry times. Reads are served from the local copy and may
+// lag the leader on followers.
+//
+// Expired keys are hidden from reads by each node against its own clock,
+// but only removed by writes and by the expirations the leader appends to
+// the log, so every node removes them at the same point of it and runs
+// writes against the same keys.
+type ReplicatedStore struct {
+	*InMemoryStore
+	id   string
+	raft *raft.Raft
+
+	transport raft.Transport
+	logs      *raftboltdb.BoltStore
+	timeout   time.Duration
+
+	// applying is the time of the command being applied. Only the FSM
+	// writes to the store, so only it reads this.
+	applying time.Time
+}
+
+func OpenReplicatedStore(opts ReplicationOptions) (*ReplicatedStore, error) {
+	if opts.ID == "" {
+		return nil, errors.New("replication needs a node ID")
+	}
+
+	conf := raft.DefaultConfig()
+	if opts.Config != nil {
+		c := *opts.Config
+		conf = &c
+	}
+	conf.LocalID = raft.ServerID(opts.ID)
+
+	r := &ReplicatedStore{
+		InMemoryStore: newInMemoryStore(),
+		id:            opts.ID,
+		transport:     opts.Transport,
+		timeout:       cmp.Or(opts.ApplyTimeout, defaultApplyTimeout),
+	}
+	r.clock = func() time.Time { return r.applying }
+	r.keepExpired = true
+
+	var logs raft.LogStore
+	var stable raft.StableStore
+	var snaps raft.SnapshotStore
+
+	if opts.Dir == "" {
+		mem := raft.NewInmemStore()
+		logs, stable = mem, mem
+		snaps = raft.NewInmemSnapshotStore()
+	} else {
+		if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create raft directory: %w", err)
+		}
+
+		bolt, err := raftboltdb.NewBoltStore(filepath.Join(opts.Dir, "raft.db"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open raft log: %w", err)
+		}
+		r.logs = bolt
+		logs, stable = bolt, bolt
+
+		snaps, err = raft.NewFileSnapshotStore(opts.Dir, raftSnapshotsRetained, os.Stderr)
+		if err != nil {
+			bolt.Close()
+			return nil, fmt.Errorf("failed to open raft snapshots: %w", err)
+		}
+	}
+
+	existing, err := raft.HasExistingState(logs, stable, snaps)
+	if err != nil {
+		r.closeLogs()
+		return nil, fmt.Errorf("failed to read raft state: %w", err)
+	}
+
+	node, err := raft.NewRaft(conf, &replicatedFSM{r}, logs, stable, snaps, opts.Transport)
+	if err != nil {
+		r.closeLogs()
+		return nil, fmt.Errorf("failed to start raft: %w", err)
+	}
+	r.raft = node
+
+	if !existing {
+		peers := opts.Peers
+		if len(peers) == 0 {
+			peers = []raft.Server{{ID: conf.LocalID, Address: opts.Transport.LocalAddr()}}
+		}
+
+		err := node.BootstrapCluster(raft.Configuration{Servers: peers}).Error()
+		if err != nil && !errors.Is(err, raft.ErrCantBootstrap) {
+			r.Close()
+			return nil, fmt.Errorf("failed to bootstrap cluster: %w", err)
+		}
+	}
+
+	if opts.SweepInterval > 0 {
+		r.startSweeper(opts.SweepInterval, r.expireKeys)
+	}
+
+	return r, nil
+}
+
+// Leader returns the ID of the current leader, or empty while there is none.
+func (r *ReplicatedStore) Leader() string {
+	_, id := r.raft.LeaderWithID()
+	return string(id)
+}
+
+// IsLeader reports whether this node currently accepts writes.
+func (r *ReplicatedStore) IsLeader() bool {
+	return r.raft.State() == raft.Leader
+}
+
+func (r *ReplicatedStore) Set(key, value string) (int64, error) {
+	if key == "" {
+		return 0, ErrEmptyKey
+	}
+
+	result, err := r.propose(command{Op: cmdSet, Key: key, Value: value})
+	return result.Rev, err
+}
+
+func (r *ReplicatedStore) SetWithTTL(key, value string, ttl time.Duration) (int64, error) {
+	if key == "" {
+		return 0, ErrEmptyKey
+	}
+
+	if ttl <= 0 {
+		return 0, ErrInvalidTTL
+	}
+
+	result, err := r.propose(command{Op: cmdSet, Key: key, Value: value, TTL: ttl})
+	return result.Rev, err
+}
+
+func (r *ReplicatedStore) CompareAndSwap(key string, cond Condition, value string, ttl time.Duration) (int64, error) {
+	if key == "" {
+		return 0, ErrEmptyKey
+	}
+
+	if ttl < 0 {
+		return 0, ErrInvalidTTL
+	}
+
+	result, err := r.propose(command{Op: cmdCompareAndSwap, Key: key, Value: value, TTL: ttl, Cond: cond})
+	return result.Rev, err
+}
+
+func (r *ReplicatedStore) Increment(key string, delta int64, opts CounterOptions) (int64, int64, error) {
+	if key == "" {
+		return 0, 0, ErrEmptyKey
+	}
+
+	result, err := r.propose(command{Op: cmdIncrement, Key: key, Delta: delta, Counter: opts})
+	return result.Value, result.Rev, err
+}
+
+// Txn runs on the leader even when it only reads, so its compares see
+// every committed write.
+func (r *ReplicatedStore) Txn(txn Txn) (TxnResult, error) {
+	if err := txn.validate(); err != nil {
+		return TxnResult{}, err
+	}
+
+	result, err := r.propose(command{Op: cmdTxn, Txn: txn})
+	return result.Txn, err
+}
+
+func (r *ReplicatedStore) Delete(key string) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+
+	_, err := r.propose(command{Op: cmdDelete, Key: key})
+	return err
+}
+
+func (r *ReplicatedStore) Persist(key string) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+
+	_, err := r.propose(command{Op: cmdPersist, Key: key})
+	return err
+}
+
+// expireKeys has the leader append the removal of the keys that have
+// expired to the log.
+func (r *ReplicatedStore) expireKeys() {
+	if !r.IsLeader() {
+		return
+	}
+
+	for {
+		keys := r.expiredKeys(time.Now(), maxExpiredPerCommand)
+		if len(keys) == 0 {
+			return
+		}
+
+		if _, err := r.propose(command{Op: cmdExpire, Keys: keys}); err != nil {
+			slog.Warn("Failed to expire keys", "keys", len(keys), "error", err)
+			return
+		}
+
+		if len(keys) < maxExpiredPerCommand {
+			return
+		}
+	}
+}
+
+// Snapshot has raft snapshot the keyspace and compact its log.
+func (r *ReplicatedStore) Snapshot() (SnapshotInfo, error) {
+	future := r.raft.Snapshot()
+	if err := future.Error(); err != nil {
+		return SnapshotInfo{}, err
+	}
+
+	meta, rc, err := future.Open()
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	defer rc.Close()
+
+	keys := 0
+	if _, err := readSnapshot(rc, func(mutation) { keys++ }); err != nil {
+		return SnapshotInfo{}, err
+	}
+
+	return SnapshotInfo{Path: meta.ID, Size: meta.Size, Keys: keys}, nil
+}
+
+func (r *ReplicatedStore) Close() error {
+	err := r.raft.Shutdown().Error()
+
+	if closer, ok := r.transport.(raft.WithClose); ok {
+		closer.Close()
+	}
+
+	r.closeLogs()
+	r.InMemoryStore.Close()
+
+	return err
+}
+
+func (r *ReplicatedStore) closeLogs() {
+	if r.logs != nil {
+		r.logs.Close()
+	}
+}
+
+// propose appends cmd to the raft log and returns its result once this
+// node has applied it.
+func (r *ReplicatedStore) propose(cmd command) (commandResult, error) {
+	if !r.IsLeader() {
+		return commandResult{}, &NotLeaderError{Leader: r.Leader()}
+	}
+
+	cmd.At = time.Now()
+	data, err := encodeCommand(cmd)
+	if err != nil {
+		return commandResult{}, err
+	}
+
+	future := r.raft.Apply(data, r.timeout)
+	if err := future.Error(); err != nil {
+		// Only these guarantee the write was never appended, so it is
+		// safe to retry on the leader. Losing leadership afterwards
+		// leaves the outcome unknown.
+		if errors.Is(err, raft.ErrNotLeader) || errors.Is(err, raft.ErrLeadershipTransferInProgress) {
+			return commandResult{}, &NotLeaderError{Leader: r.Leader()}
+		}
+		return commandResult{}, fmt.Errorf("failed to replicate write: %w", err)
+	}
+
+	result := future.Response().(commandResult)
+	return result, result.Err
+}
+
+type commandOp int
+
+const (
+	cmdSet commandOp = iota + 1
+	cmdCompareAndSwap
+	cmdTxn
+	cmdDelete
+	cmdPersist
+	cmdIncrement
+	// cmdExpire removes those of Keys that have expired by At.
+	cmdExpire
+)
+
+// command is a write as it is stored in the raft log.
+type command struct {
+	Op      commandOp
+	Key     string
+	Value   string
+	TTL     time.Duration
+	Cond    Condition
+	Txn     Txn
+	Delta   int64
+	Counter CounterOptions
+	Keys    []string
+	// At is the leader's clock when the write was proposed.
+	At time.Time
+}
+
+// encodeCommand encodes cmd with gob, which keeps values that are not
+// valid UTF-8 intact.
+func encodeCommand(cmd command) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cmd); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeCommand(data []byte) (command, error) {
+	var cmd command
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&cmd)
+	return cmd, err
+}
+
+type commandResult struct {
+	Rev   int64
+	Txn   TxnResult
+	Value int64
+	Err   error
+}
+
+// replicatedFSM applies committed commands to the store.
+type replicatedFSM struct {
+	r *ReplicatedStore
+}
+
+func (f *replicatedFSM) Apply(entry *raft.Log) any {
+	cmd, err := decodeCommand(entry.Data)
+	if err != nil {
+		// Every node would fail on the same entry, and skipping it could
+		// shift the versions of everything after it.
+		logging.Fatal("Failed to decode raft log entry", "index", entry.Index, "error", err)
+	}
+
+	f.r.applying = cmd.At
+	i := f.r.InMemoryStore
+
+	var result commandResult
+	switch cmd.Op {
+	case cmdSet:
+		if cmd.TTL > 0 {
+			result.Rev, result.Err = i.SetWithTTL(cmd.Key, cmd.Value, cmd.TTL)
+		} else {
+			result.Rev, result.Err = i.Set(cmd.Key, cmd.Value)
+		}
+	case cmdCompareAndSwap:
+		result.Rev, result.Err = i.CompareAndSwap(cmd.Key, cmd.Cond, cmd.Value, cmd.TTL)
+	case cmdTxn:
+		result.Txn, result.Err = i.Txn(cmd.Txn)
+	case cmdDelete:
+		result.Err = i.Delete(cmd.Key)
+	case cmdPersist:
+		result.Err = i.Persist(cmd.Key)
+	case cmdIncrement:
+		result.Value, result.Rev, result.Err = i.Increment(cmd.Key, cmd.Delta, cmd.Counter)
+	case cmdExpire:
+		i.expire(cmd.Keys)
+	default:
+		logging.Fatal("Unknown command in raft log entry", "op", cmd.Op, "index", entry.Index)
+	}
+
+	return result
+}
+
+func (f *replicatedFSM) Snapshot() (raft.FSMSnapshot, error) {
+	i := f.r.InMemoryStore
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	// Expired keys are kept, as they are until the log removes them.
+	entries := make(map[string]entry, len(i.data))
+	for key, e := range i.data {
+		entries[key] = e
+	}
+
+	return &fsmSnapshot{rev: i.rev, entries: entries}, nil
+}
+
+// Restore replaces the keyspace with a snapshot from the leader. Watches
+// that have not yet seen the snapshot's revision fail with ErrCompacted.
+func (f *replicatedFSM) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	restored := newInMemoryStore()
+	header, err := decodeSnapshot(data, restored.apply)
+	if err != nil {
+		return err
+	}
+
+	i := f.r.InMemoryStore
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.data = restored.data
+	i.index = restored.index
+	i.expiring = restored.expiring
+	i.used = restored.used
+	i.usage = restored.usage
+	i.rev = max(restored.rev, header.rev)
+	i.events.reset(i.rev)
+
+	return nil
+}
+
+type fsmSnapshot struct {
+	rev     int64
+	entries map[string]entry
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := encodeSnapshot(sink, 0, s.rev, s.entries); err != nil {
+		sink.Cancel()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
+
+// Ensure ReplicatedStore implements Store, Snapshotter, MemoryReporter and
+// UsageReporter
+var (
+	_ Store          = (*ReplicatedStore)(nil)
+	_ Snapshotter    = (*ReplicatedStore)(nil)
+	_ MemoryReporter = (*ReplicatedStore)(nil)
+	_ UsageReporter  = (*ReplicatedStore)(nil)
+	_ raft.FSM       = (*replicatedFSM)(nil)
+)
@@ -0,0 +1,283 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A snapshot is the whole keyspace at one point in time:
+//
+//	header: magic "KVSN" | version uint32 | walSeq uint64 | rev uint64 | count uint64
+//	entries: count * (key | value | expiresAt | version)
+//	trailer: crc32c uint32 of everything before it
+//
+// Entries use the same encoding as write-ahead log put mutations. walSeq is
+// the first log segment whose writes are not part of the snapshot and rev
+// the store revision when it was taken.
+//
+// Version 1 snapshots predate key versions and have neither rev nor entry
+// versions.
+const (
+	snapshotFileName   = "snapshot.db"
+	snapshotTempName   = "snapshot.db.tmp"
+	snapshotMagic      = "KVSN"
+	snapshotVersion    = 2
+	snapshotHeaderSize = 32
+)
+
+var ErrCorruptSnapshot = errors.New("snapshot is corrupt")
+
+type SnapshotInfo struct {
+	Path string
+	Size int64
+	Keys int
+}
+
+// Snapshotter is implemented by stores that can write a point-in-time
+// snapshot of their keyspace on demand.
+type Snapshotter interface {
+	Snapshot() (SnapshotInfo, error)
+}
+
+// writeSnapshot writes entries to a temporary file in dir and renames it
+// over the previous snapshot once it is safely on disk.
+func writeSnapshot(dir string, walSeq uint64, rev int64, entries map[string]entry) (SnapshotInfo, error) {
+	tmpPath := filepath.Join(dir, snapshotTempName)
+	path := filepath.Join(dir, snapshotFileName)
+
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	if err := encodeSnapshot(file, walSeq, rev, entries); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("failed to sync snapshot: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return SnapshotInfo{}, fmt.Errorf("failed to stat snapshot: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("failed to install snapshot: %w", err)
+	}
+
+	if err := syncDir(dir); err != nil {
+		return SnapshotInfo{}, err
+	}
+
+	return SnapshotInfo{
+		Path: path,
+		Size: info.Size(),
+		Keys: len(entries),
+	}, nil
+}
+
+// encodeSnapshot writes entries, and the trailer that checks them, to w.
+func encodeSnapshot(w io.Writer, walSeq uint64, rev int64, entries map[string]entry) error {
+	crc := crc32.New(crcTable)
+	bw := bufio.NewWriter(io.MultiWriter(w, crc))
+
+	header := make([]byte, snapshotHeaderSize)
+	copy(header, snapshotMagic)
+	binary.LittleEndian.PutUint32(header[4:8], snapshotVersion)
+	binary.LittleEndian.PutUint64(header[8:16], walSeq)
+	binary.LittleEndian.PutUint64(header[16:24], uint64(rev))
+	binary.LittleEndian.PutUint64(header[24:32], uint64(len(entries)))
+	bw.Write(header)
+
+	var buf []byte
+	for key, e := range entries {
+		buf = appendString(buf[:0], key)
+		buf = appendString(buf, e.value)
+		buf = binary.AppendVarint(buf, unixNano(e.expiresAt))
+		buf = binary.AppendUvarint(buf, uint64(e.version))
+		bw.Write(buf)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := w.Write(binary.LittleEndian.AppendUint32(nil, crc.Sum32()))
+	return err
+}
+
+type snapshotHeader struct {
+	walSeq uint64
+	rev    int64
+}
+
+// loadSnapshot hands every entry of the snapshot in dir that has not expired
+// to apply and returns its header, which names the first log segment to
+// replay on top of it. A missing snapshot is not an error; the whole log is
+// replayed instead.
+func loadSnapshot(dir string, apply func(mutation)) (snapshotHeader, error) {
+	file, err := os.Open(filepath.Join(dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshotHeader{}, nil
+	}
+	if err != nil {
+		return snapshotHeader{}, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return snapshotHeader{}, fmt.Errorf("failed to stat snapshot: %w", err)
+	}
+
+	if info.Size() < 8+4 {
+		return snapshotHeader{}, fmt.Errorf("%w: file too short", ErrCorruptSnapshot)
+	}
+
+	// Check the trailer before applying anything so a damaged snapshot
+	// never half-loads.
+	crc := crc32.New(crcTable)
+	if _, err := io.CopyN(crc, file, info.Size()-4); err != nil {
+		return snapshotHeader{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	trailer := make([]byte, 4)
+	if _, err := io.ReadFull(file, trailer); err != nil {
+		return snapshotHeader{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	if binary.LittleEndian.Uint32(trailer) != crc.Sum32() {
+		return snapshotHeader{}, fmt.Errorf("%w: checksum mismatch", ErrCorruptSnapshot)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return snapshotHeader{}, err
+	}
+
+	now := time.Now()
+	return readSnapshot(io.LimitReader(file, info.Size()-4), func(m mutation) {
+		if !(entry{expiresAt: m.expiresAt}).expired(now) {
+			apply(m)
+		}
+	})
+}
+
+// decodeSnapshot is loadSnapshot for a snapshot already in memory. Expired
+// entries are handed to apply too, as replicas only remove keys where
+// their log says.
+func decodeSnapshot(data []byte, apply func(mutation)) (snapshotHeader, error) {
+	if len(data) < 8+4 {
+		return snapshotHeader{}, fmt.Errorf("%w: too short", ErrCorruptSnapshot)
+	}
+
+	body, trailer := data[:len(data)-4], data[len(data)-4:]
+	if binary.LittleEndian.Uint32(trailer) != crc32.Checksum(body, crcTable) {
+		return snapshotHeader{}, fmt.Errorf("%w: checksum mismatch", ErrCorruptSnapshot)
+	}
+
+	return readSnapshot(bytes.NewReader(body), apply)
+}
+
+func readSnapshot(r io.Reader, apply func(mutation)) (snapshotHeader, error) {
+	br := bufio.NewReader(r)
+
+	prefix := make([]byte, 8)
+	if _, err := io.ReadFull(br, prefix); err != nil {
+		return snapshotHeader{}, fmt.Errorf("%w: short header", ErrCorruptSnapshot)
+	}
+
+	if string(prefix[:4]) != snapshotMagic {
+		return snapshotHeader{}, fmt.Errorf("%w: bad magic", ErrCorruptSnapshot)
+	}
+
+	version := binary.LittleEndian.Uint32(prefix[4:8])
+	if version < 1 || version > snapshotVersion {
+		return snapshotHeader{}, fmt.Errorf("%w: unsupported version %d", ErrCorruptSnapshot, version)
+	}
+
+	fields := make([]byte, 16)
+	if version >= 2 {
+		fields = make([]byte, 24)
+	}
+
+	if _, err := io.ReadFull(br, fields); err != nil {
+		return snapshotHeader{}, fmt.Errorf("%w: short header", ErrCorruptSnapshot)
+	}
+
+	var header snapshotHeader
+	header.walSeq = binary.LittleEndian.Uint64(fields[0:8])
+	if version >= 2 {
+		header.rev = int64(binary.LittleEndian.Uint64(fields[8:16]))
+	}
+	count := binary.LittleEndian.Uint64(fields[len(fields)-8:])
+
+	for n := uint64(0); n < count; n++ {
+		m, err := readSnapshotEntry(br, version)
+		if err != nil {
+			return snapshotHeader{}, fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+		}
+
+		apply(m)
+	}
+
+	return header, nil
+}
+
+func readSnapshotEntry(r *bufio.Reader, version uint32) (mutation, error) {
+	key, err := readString(r)
+	if err != nil {
+		return mutation{}, err
+	}
+
+	value, err := readString(r)
+	if err != nil {
+		return mutation{}, err
+	}
+
+	expiresAt, err := binary.ReadVarint(r)
+	if err != nil {
+		return mutation{}, err
+	}
+
+	m := mutation{op: opPut, key: key, value: value, expiresAt: fromUnixNano(expiresAt)}
+
+	if version >= 2 {
+		v, err := binary.ReadUvarint(r)
+		if err != nil {
+			return mutation{}, err
+		}
+		m.version = int64(v)
+	}
+
+	return m, nil
+}
+
+func readString(r *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", err
+	}
+
+	if n > maxRecordSize {
+		return "", fmt.Errorf("string length %d exceeds limit", n)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
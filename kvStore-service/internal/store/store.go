@@ -1,14 +1,100 @@
 package store
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrKeyNotFound = errors.New("key not found")
 	ErrEmptyKey    = errors.New("key cannot be empty")
+	ErrInvalidTTL  = errors.New("ttl must be positive")
+
+	ErrVersionMismatch = errors.New("version mismatch")
+	ErrInvalidLimit    = errors.New("limit cannot be negative")
+	ErrInvalidCompare  = errors.New("invalid compare")
+	ErrInvalidTxnOp    = errors.New("invalid txn op")
+	ErrCompacted       = errors.New("requested revision has been compacted")
+	ErrClosed          = errors.New("store is closed")
+	ErrNotLeader       = errors.New("node is not the leader")
+	ErrMemoryLimit     = errors.New("memory limit reached")
+	ErrNotInteger      = errors.New("value is not an integer")
+	ErrOutOfRange      = errors.New("value would be out of range")
 )
 
+// NoExpiry is returned by TTL for keys that never expire.
+const NoExpiry time.Duration = -1
+
+// Condition is what CompareAndSwap expects a key to hold before writing it.
+type Condition struct {
+	// Version the key must be at. Zero means the key must not exist.
+	Version int64
+	// Value, when set, must equal the current value and Version is ignored.
+	Value *string
+}
+
+func (c Condition) holds(current entry, exists bool) bool {
+	if c.Value != nil {
+		return exists && current.value == *c.Value
+	}
+
+	if c.Version == 0 {
+		return !exists
+	}
+
+	return exists && current.version == c.Version
+}
+
+type KeyValue struct {
+	Key     string
+	Value   string
+	Version int64
+}
+
+// ScanOptions selects the keys a Scan returns, in ascending order.
+type ScanOptions struct {
+	// Start is the first key to return, inclusive. Empty starts at the
+	// first key.
+	Start string
+	// End is the key to stop at, exclusive. Empty runs to the last key.
+	End string
+	// Prefix, when set, restricts the scan to keys starting with it.
+	Prefix string
+	// Limit caps the number of keys returned. Zero means no limit.
+	Limit int
+}
+
+// Every write returns the version it gave the key. Versions come from a
+// store-wide revision counter, so they only ever grow, even across a delete
+// and re-create of the same key.
+//
+// Values are arbitrary bytes held in a string, which need not be valid
+// UTF-8. Use string(b) and []byte(v) to store and read binary data.
 type Store interface {
-	Set(key, value string) error
+	Set(key, value string) (int64, error)
+	SetWithTTL(key, value string, ttl time.Duration) (int64, error)
 	Get(key string) (string, error)
+	GetWithVersion(key string) (string, int64, error)
+	// CompareAndSwap writes value, expiring after ttl when positive, only
+	// if cond holds and returns ErrVersionMismatch otherwise.
+	CompareAndSwap(key string, cond Condition, value string, ttl time.Duration) (int64, error)
+	// Increment atomically adds delta to the signed 64-bit integer key
+	// holds, returning the new value and version. It fails with
+	// ErrNotInteger if the value is not a base 10 integer, and with
+	// ErrOutOfRange if the result overflows or leaves the bounds in opts.
+	Increment(key string, delta int64, opts CounterOptions) (int64, int64, error)
+	// Scan returns the keys selected by opts in order, and whether more
+	// keys past the limit remain.
+	Scan(opts ScanOptions) ([]KeyValue, bool, error)
+	// Txn atomically runs txn.Success if every compare holds and
+	// txn.Failure otherwise.
+	Txn(txn Txn) (TxnResult, error)
+	// Watch streams changes to the keys selected by opts until it is
+	// closed. It fails with ErrCompacted if opts.StartRevision is older
+	// than the history the store keeps.
+	Watch(opts WatchOptions) (*Watch, error)
 	Delete(key string) error
+	TTL(key string) (time.Duration, error)
+	Persist(key string) error
+	Close() error
 }
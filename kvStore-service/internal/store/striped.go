@@ -0,0 +1,281 @@
+package store
+
+import (
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/btree"
+)
+
+// sequencer hands out revisions to the stripes of a StripedStore and logs
+// their writes, so watches see revisions in order whichever stripe made
+// them.
+type sequencer struct {
+	mu     sync.Mutex
+	rev    int64
+	events *eventLog
+}
+
+func (q *sequencer) commit(ms []mutation) int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.log(ms)
+}
+
+// log stamps ms that have no version yet with the next revision and hands
+// them to watches. Callers must hold mu.
+func (q *sequencer) log(ms []mutation) int64 {
+	prev := q.rev
+	rev := prev + 1
+	for n := range ms {
+		if ms[n].version == 0 {
+			ms[n].version = rev
+			q.rev = rev
+		}
+	}
+
+	q.events.append(prev, ms)
+
+	return rev
+}
+
+// StripedStore spreads keys across stripes by hash, each an InMemoryStore
+// with its own lock, so reads and writes of keys on different stripes do
+// not wait on each other. Writes still share a revision counter, held only
+// long enough to stamp and log them.
+//
+// A Scan reads one stripe at a time, so unlike an InMemoryStore it does not
+// see the keyspace at a single point in time. Txn locks every stripe its
+// keys are on.
+type StripedStore struct {
+	stripes []*InMemoryStore
+	seq     *sequencer
+
+	stopSweeper chan struct{}
+	sweeperDone chan struct{}
+	closeOnce   sync.Once
+}
+
+// CreateStripedStore returns a StripedStore with the given number of
+// stripes that removes expired keys in the background every sweepInterval.
+// Close stops the sweeper.
+func CreateStripedStore(stripes int, sweepInterval time.Duration) *StripedStore {
+	s := &StripedStore{
+		stripes: make([]*InMemoryStore, max(stripes, 1)),
+		seq:     &sequencer{events: newEventLog(eventLogSize, 0)},
+	}
+
+	for n := range s.stripes {
+		s.stripes[n] = &InMemoryStore{
+			data:     make(map[string]entry),
+			index:    btree.NewOrderedG[string](32),
+			expiring: make(map[string]struct{}),
+			usage:    make(map[string]Usage),
+			clock:    time.Now,
+			seq:      s.seq,
+		}
+	}
+
+	s.stopSweeper = make(chan struct{})
+	s.sweeperDone = make(chan struct{})
+	go s.sweep(sweepInterval)
+
+	return s
+}
+
+func (s *StripedStore) stripeIndex(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(s.stripes)))
+}
+
+func (s *StripedStore) stripe(key string) *InMemoryStore {
+	return s.stripes[s.stripeIndex(key)]
+}
+
+func (s *StripedStore) Set(key, value string) (int64, error) {
+	return s.stripe(key).Set(key, value)
+}
+
+func (s *StripedStore) SetWithTTL(key, value string, ttl time.Duration) (int64, error) {
+	return s.stripe(key).SetWithTTL(key, value, ttl)
+}
+
+func (s *StripedStore) Get(key string) (string, error) {
+	return s.stripe(key).Get(key)
+}
+
+func (s *StripedStore) GetWithVersion(key string) (string, int64, error) {
+	return s.stripe(key).GetWithVersion(key)
+}
+
+func (s *StripedStore) CompareAndSwap(key string, cond Condition, value string, ttl time.Duration) (int64, error) {
+	return s.stripe(key).CompareAndSwap(key, cond, value, ttl)
+}
+
+func (s *StripedStore) Increment(key string, delta int64, opts CounterOptions) (int64, int64, error) {
+	return s.stripe(key).Increment(key, delta, opts)
+}
+
+func (s *StripedStore) Delete(key string) error {
+	return s.stripe(key).Delete(key)
+}
+
+func (s *StripedStore) TTL(key string) (time.Duration, error) {
+	return s.stripe(key).TTL(key)
+}
+
+func (s *StripedStore) Persist(key string) error {
+	return s.stripe(key).Persist(key)
+}
+
+// Scan asks every stripe for a page and merges them in key order.
+func (s *StripedStore) Scan(opts ScanOptions) ([]KeyValue, bool, error) {
+	if opts.Limit < 0 {
+		return nil, false, ErrInvalidLimit
+	}
+
+	var items []KeyValue
+	more := false
+	for _, stripe := range s.stripes {
+		page, stripeMore, err := stripe.Scan(opts)
+		if err != nil {
+			return nil, false, err
+		}
+
+		items = append(items, page...)
+		more = more || stripeMore
+	}
+
+	slices.SortFunc(items, func(a, b KeyValue) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	if opts.Limit > 0 && len(items) > opts.Limit {
+		items = items[:opts.Limit]
+		more = true
+	}
+
+	return items, more, nil
+}
+
+// Txn write-locks the stripes of every key it names, in stripe order so
+// two transactions never wait on each other, then runs like an
+// InMemoryStore Txn.
+func (s *StripedStore) Txn(txn Txn) (TxnResult, error) {
+	if err := txn.validate(); err != nil {
+		return TxnResult{}, err
+	}
+
+	var locked []int
+	for _, c := range txn.Compare {
+		locked = append(locked, s.stripeIndex(c.Key))
+	}
+	for _, op := range slices.Concat(txn.Success, txn.Failure) {
+		locked = append(locked, s.stripeIndex(op.Key))
+	}
+	slices.Sort(locked)
+	locked = slices.Compact(locked)
+
+	for _, n := range locked {
+		s.stripes[n].mu.Lock()
+		defer s.stripes[n].mu.Unlock()
+	}
+
+	now := time.Now()
+	live := func(key string) (entry, bool) {
+		return s.stripe(key).live(key, now)
+	}
+
+	// The revision is only known under the sequencer, which is held until
+	// the writes are logged.
+	s.seq.mu.Lock()
+	succeeded, results, ms := txn.run(now, s.seq.rev+1, live)
+	if len(ms) > 0 {
+		s.seq.log(ms)
+	}
+	rev := s.seq.rev
+	s.seq.mu.Unlock()
+
+	for _, m := range ms {
+		s.stripe(m.key).apply(m)
+	}
+
+	return TxnResult{
+		Succeeded: succeeded,
+		Results:   results,
+		Revision:  rev,
+	}, nil
+}
+
+func (s *StripedStore) Watch(opts WatchOptions) (*Watch, error) {
+	if opts.Key == "" && !opts.Prefix {
+		return nil, ErrEmptyKey
+	}
+
+	return s.seq.events.watch(opts)
+}
+
+func (s *StripedStore) MemoryStats() MemoryStats {
+	stats := MemoryStats{Policy: NoEviction}
+	for _, stripe := range s.stripes {
+		stripe.mu.RLock()
+		stats.Keys += len(stripe.data)
+		stats.UsedBytes += stripe.used
+		stats.Expirations += stripe.expirations
+		stripe.mu.RUnlock()
+	}
+
+	return stats
+}
+
+func (s *StripedStore) NamespaceUsage(name string) Usage {
+	var usage Usage
+	for _, stripe := range s.stripes {
+		u := stripe.NamespaceUsage(name)
+		usage.Keys += u.Keys
+		usage.Bytes += u.Bytes
+	}
+
+	return usage
+}
+
+func (s *StripedStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stopSweeper)
+		<-s.sweeperDone
+
+		s.seq.events.close()
+	})
+
+	return nil
+}
+
+func (s *StripedStore) sweep(interval time.Duration) {
+	defer close(s.sweeperDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopSweeper:
+			return
+		case <-ticker.C:
+			for _, stripe := range s.stripes {
+				stripe.removeExpired()
+			}
+		}
+	}
+}
+
+// Ensure StripedStore implements Store, MemoryReporter and UsageReporter
+var (
+	_ Store          = (*StripedStore)(nil)
+	_ MemoryReporter = (*StripedStore)(nil)
+	_ UsageReporter  = (*StripedStore)(nil)
+)
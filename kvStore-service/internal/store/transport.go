@@ -0,0 +1,48 @@
+package store
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// OpenTLSTransport carries raft traffic between nodes over mutual TLS. It
+// listens on bindAddr, which peers reach at advertise, and takes only peers
+// with a certificate serverConfig verifies, as any peer can append to the
+// log. Peers are dialed with clientConfig.
+func OpenTLSTransport(bindAddr string, advertise net.Addr, serverConfig, clientConfig *tls.Config, logOutput io.Writer) (*raft.NetworkTransport, error) {
+	if serverConfig.ClientAuth != tls.RequireAnyClientCert && serverConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		return nil, errors.New("raft over TLS needs peers to present a certificate")
+	}
+
+	lis, err := tls.Listen("tcp", bindAddr, serverConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for raft peers: %w", err)
+	}
+
+	stream := &tlsStreamLayer{Listener: lis, advertise: advertise, config: clientConfig}
+	return raft.NewNetworkTransport(stream, 3, 10*time.Second, logOutput), nil
+}
+
+// tlsStreamLayer is the raft.StreamLayer of OpenTLSTransport.
+type tlsStreamLayer struct {
+	net.Listener
+	advertise net.Addr
+	config    *tls.Config
+}
+
+func (l *tlsStreamLayer) Dial(address raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", string(address), l.config)
+}
+
+func (l *tlsStreamLayer) Addr() net.Addr {
+	if l.advertise != nil {
+		return l.advertise
+	}
+	return l.Listener.Addr()
+}
@@ -0,0 +1,250 @@
+package store
+
+import (
+	"cmp"
+	"strings"
+	"time"
+)
+
+type CompareTarget int
+
+const (
+	CompareValue CompareTarget = iota
+	CompareVersion
+	CompareExists
+)
+
+type CompareResult int
+
+const (
+	Equal CompareResult = iota
+	NotEqual
+	Greater
+	Less
+)
+
+// Compare is a condition on one key that a Txn checks before choosing which
+// ops to run. A missing key is at version 0 and fails every value compare.
+type Compare struct {
+	Key    string
+	Target CompareTarget
+	// Result is how the key's current value or version must relate to
+	// Value or Version. Existence compares only support Equal and NotEqual.
+	Result  CompareResult
+	Value   string
+	Version int64
+	Exists  bool
+}
+
+type TxnOpType int
+
+const (
+	TxnGet TxnOpType = iota
+	TxnPut
+	TxnDelete
+)
+
+type TxnOp struct {
+	Type  TxnOpType
+	Key   string
+	Value string
+	// TTL expires a put key after it when positive.
+	TTL time.Duration
+}
+
+// TxnOpResult is what a single op saw or did. Found reports whether the key
+// existed for a get or delete; puts always find their key.
+type TxnOpResult struct {
+	Key     string
+	Value   string
+	Version int64
+	Found   bool
+}
+
+type Txn struct {
+	Compare []Compare
+	Success []TxnOp
+	Failure []TxnOp
+}
+
+type TxnResult struct {
+	// Succeeded reports whether every compare held and so the Success
+	// ops ran rather than the Failure ops.
+	Succeeded bool
+	Results   []TxnOpResult
+	// Revision is the store revision after the transaction.
+	Revision int64
+}
+
+// Txn checks every compare and then runs either the success or the failure
+// ops, all under the write lock. Writes are journaled together and share a
+// single revision, so other readers see either all of them or none.
+func (i *InMemoryStore) Txn(txn Txn) (TxnResult, error) {
+	if err := txn.validate(); err != nil {
+		return TxnResult{}, err
+	}
+
+	now := i.clock()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	live := func(key string) (entry, bool) {
+		return i.live(key, now)
+	}
+
+	succeeded, results, ms := txn.run(now, i.rev+1, live)
+
+	for n, op := range txn.ops(succeeded) {
+		if op.Type == TxnGet && results[n].Found {
+			i.touch(op.Key)
+		}
+	}
+
+	if len(ms) > 0 {
+		if _, err := i.commit(ms...); err != nil {
+			return TxnResult{}, err
+		}
+	}
+
+	return TxnResult{
+		Succeeded: succeeded,
+		Results:   results,
+		Revision:  i.rev,
+	}, nil
+}
+
+// ops returns the ops that run when the compares did or did not hold.
+func (t Txn) ops(succeeded bool) []TxnOp {
+	if succeeded {
+		return t.Success
+	}
+	return t.Failure
+}
+
+// run checks the compares against live, the current entry of each key, and
+// then runs the ops they pick, writing at rev. Ops see the writes made
+// before them in the same transaction. It returns the mutations to commit.
+func (t Txn) run(now time.Time, rev int64, live func(key string) (entry, bool)) (bool, []TxnOpResult, []mutation) {
+	succeeded := true
+	for _, c := range t.Compare {
+		current, exists := live(c.Key)
+		if !c.holds(current, exists) {
+			succeeded = false
+			break
+		}
+	}
+
+	pending := make(map[string]*entry)
+	view := func(key string) (entry, bool) {
+		if e, ok := pending[key]; ok {
+			if e == nil {
+				return entry{}, false
+			}
+			return *e, true
+		}
+		return live(key)
+	}
+
+	ops := t.ops(succeeded)
+	results := make([]TxnOpResult, 0, len(ops))
+	var ms []mutation
+
+	for _, op := range ops {
+		switch op.Type {
+		case TxnGet:
+			e, exists := view(op.Key)
+			results = append(results, TxnOpResult{Key: op.Key, Value: e.value, Version: e.version, Found: exists})
+
+		case TxnPut:
+			e := entry{value: op.Value, version: rev}
+			if op.TTL > 0 {
+				e.expiresAt = now.Add(op.TTL)
+			}
+			pending[op.Key] = &e
+
+			ms = append(ms, mutation{op: opPut, key: op.Key, value: e.value, expiresAt: e.expiresAt})
+			results = append(results, TxnOpResult{Key: op.Key, Value: e.value, Version: rev, Found: true})
+
+		case TxnDelete:
+			_, exists := view(op.Key)
+			if exists {
+				pending[op.Key] = nil
+				ms = append(ms, mutation{op: opDelete, key: op.Key})
+			}
+			results = append(results, TxnOpResult{Key: op.Key, Found: exists})
+		}
+	}
+
+	return succeeded, results, ms
+}
+
+func (t Txn) validate() error {
+	for _, c := range t.Compare {
+		if c.Key == "" {
+			return ErrEmptyKey
+		}
+
+		switch c.Target {
+		case CompareValue, CompareVersion:
+			if c.Result < Equal || c.Result > Less {
+				return ErrInvalidCompare
+			}
+		case CompareExists:
+			if c.Result != Equal && c.Result != NotEqual {
+				return ErrInvalidCompare
+			}
+		default:
+			return ErrInvalidCompare
+		}
+	}
+
+	for _, ops := range [][]TxnOp{t.Success, t.Failure} {
+		for _, op := range ops {
+			if op.Key == "" {
+				return ErrEmptyKey
+			}
+
+			if op.Type < TxnGet || op.Type > TxnDelete {
+				return ErrInvalidTxnOp
+			}
+
+			if op.TTL < 0 {
+				return ErrInvalidTTL
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c Compare) holds(current entry, exists bool) bool {
+	var order int
+	switch c.Target {
+	case CompareValue:
+		if !exists {
+			return false
+		}
+		order = strings.Compare(current.value, c.Value)
+	case CompareVersion:
+		// current is the zero entry, at version 0, when the key is missing.
+		order = cmp.Compare(current.version, c.Version)
+	case CompareExists:
+		if exists != c.Exists {
+			order = 1
+		}
+	}
+
+	switch c.Result {
+	case Equal:
+		return order == 0
+	case NotEqual:
+		return order != 0
+	case Greater:
+		return order > 0
+	case Less:
+		return order < 0
+	}
+
+	return false
+}
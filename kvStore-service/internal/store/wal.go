@@ -0,0 +1,716 @@
+package store
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// The write-ahead log is a sequence of numbered segment files. Each segment
+// is a small header followed by records:
+//
+//	header: magic "KVWL" | version uint32
+//	record: length uint32 | crc32c(payload) uint32 | payload
+//	payload: count uvarint | count * mutation
+//	put mutation: op byte | key | value | expiresAt | version
+//	delete mutation: op byte | key | version
+//
+// Strings are uvarint length prefixed, expiresAt is a varint of unix
+// nanoseconds, zero for keys without a TTL, and version a uvarint. All
+// fixed-width integers are little endian. One record holds every mutation
+// of one write so that a write is either replayed completely or not at all.
+//
+// Version 1 segments predate key versions and carry none.
+const (
+	walMagic         = "KVWL"
+	walVersion       = 2
+	walHeaderSize    = 8
+	recordHeaderSize = 8
+	maxRecordSize    = 64 << 20
+)
+
+var ErrCorruptLog = errors.New("write-ahead log is corrupt")
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type FsyncMode int
+
+const (
+	// FsyncAlways syncs the log before every write is acknowledged.
+	FsyncAlways FsyncMode = iota
+	// FsyncInterval syncs the log in the background every Interval.
+	FsyncInterval
+	// FsyncNever leaves flushing to the operating system.
+	FsyncNever
+)
+
+type FsyncPolicy struct {
+	Mode     FsyncMode
+	Interval time.Duration
+}
+
+// ParseFsyncPolicy accepts "always", "never" or an interval such as "100ms".
+func ParseFsyncPolicy(s string) (FsyncPolicy, error) {
+	switch s {
+	case "always":
+		return FsyncPolicy{Mode: FsyncAlways}, nil
+	case "never":
+		return FsyncPolicy{Mode: FsyncNever}, nil
+	}
+
+	interval, err := time.ParseDuration(s)
+	if err != nil || interval <= 0 {
+		return FsyncPolicy{}, fmt.Errorf("invalid fsync policy %q: want always, never or an interval such as 100ms", s)
+	}
+
+	return FsyncPolicy{Mode: FsyncInterval, Interval: interval}, nil
+}
+
+type wal struct {
+	dir    string
+	mu     sync.Mutex
+	file   *os.File
+	seq    uint64
+	size   int64
+	policy FsyncPolicy
+	dirty  bool
+
+	// failed is set once the log can no longer be trusted, after which
+	// every append is refused.
+	failed error
+
+	stopSyncer chan struct{}
+	syncerDone chan struct{}
+}
+
+// legacyLogName is the single log file data directories had before the log
+// was split into segments.
+const legacyLogName = "wal.log"
+
+func segmentName(seq uint64) string {
+	return fmt.Sprintf("wal-%016d.log", seq)
+}
+
+// openWAL replays every segment in dir numbered firstSeq or higher, handing
+// each recovered mutation to replay in order, and opens the last segment for
+// appending. Older segments are already covered by a snapshot and are
+// removed. A torn record at the tail of a segment, left behind by a crash in
+// the middle of a write, is truncated away, and a last segment torn before
+// its header was written is started again.
+func openWAL(dir string, firstSeq uint64, policy FsyncPolicy, replay func(mutation)) (*wal, error) {
+	w := &wal{
+		dir:    dir,
+		policy: policy,
+	}
+
+	if err := migrateLegacyLog(dir, firstSeq); err != nil {
+		return nil, err
+	}
+
+	if err := w.removeSegmentsBefore(firstSeq); err != nil {
+		return nil, err
+	}
+
+	seqs, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seqs) == 0 {
+		if err := w.createSegment(firstSeq); err != nil {
+			return nil, err
+		}
+	}
+
+	for n, seq := range seqs {
+		path := filepath.Join(dir, segmentName(seq))
+
+		if n == len(seqs)-1 {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to stat write-ahead log: %w", err)
+			}
+
+			// A crash in createSegment leaves the last segment without
+			// its full header, and so without any record to lose.
+			if info.Size() < walHeaderSize {
+				slog.Warn("Recreating segment torn before its header was written", "file", path)
+
+				if err := w.createSegment(seq); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		file, err := os.OpenFile(path, os.O_RDWR, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open write-ahead log: %w", err)
+		}
+
+		size, version, err := replaySegment(file, replay)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		if n < len(seqs)-1 {
+			file.Close()
+			continue
+		}
+
+		if version != walVersion {
+			// Never mix record formats within one segment.
+			file.Close()
+			if err := w.createSegment(seq + 1); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		w.file, w.seq, w.size = file, seq, size
+	}
+
+	if policy.Mode == FsyncInterval {
+		w.stopSyncer = make(chan struct{})
+		w.syncerDone = make(chan struct{})
+
+		go w.syncPeriodically(policy.Interval)
+	}
+
+	return w, nil
+}
+
+// migrateLegacyLog turns a wal.log left from before segments into the first
+// segment, whose format it already has as version 1. When segments or a
+// snapshot sit beside it, it is unclear what the log still covers, so
+// opening fails rather than dropping or replaying it out of order.
+func migrateLegacyLog(dir string, firstSeq uint64) error {
+	legacy := filepath.Join(dir, legacyLogName)
+	if _, err := os.Stat(legacy); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to stat write-ahead log: %w", err)
+	}
+
+	seqs, err := listSegments(dir)
+	if err != nil {
+		return err
+	}
+
+	if len(seqs) > 0 || firstSeq != 0 {
+		return fmt.Errorf("%s is left from before the write-ahead log had segments, but segments or a snapshot already exist; move it out of %s to start", legacyLogName, dir)
+	}
+
+	if err := os.Rename(legacy, filepath.Join(dir, segmentName(firstSeq))); err != nil {
+		return fmt.Errorf("failed to migrate write-ahead log: %w", err)
+	}
+
+	if err := syncDir(dir); err != nil {
+		return err
+	}
+
+	slog.Info("Migrated write-ahead log to segments", "file", legacy)
+	return nil
+}
+
+func listSegments(dir string) ([]uint64, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	if err != nil {
+		return nil, err
+	}
+
+	var seqs []uint64
+	for _, name := range names {
+		var seq uint64
+		if _, err := fmt.Sscanf(filepath.Base(name), "wal-%d.log", &seq); err == nil {
+			seqs = append(seqs, seq)
+		}
+	}
+
+	slices.Sort(seqs)
+	return seqs, nil
+}
+
+// replaySegment reads every record of file and leaves it positioned for
+// appending, returning its size and format version.
+func replaySegment(file *os.File, replay func(mutation)) (int64, uint32, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stat write-ahead log: %w", err)
+	}
+
+	r := bufio.NewReader(file)
+
+	header := make([]byte, walHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, 0, fmt.Errorf("%w: short header in %s", ErrCorruptLog, file.Name())
+	}
+
+	if string(header[:4]) != walMagic {
+		return 0, 0, fmt.Errorf("%w: bad magic in %s", ErrCorruptLog, file.Name())
+	}
+
+	version := binary.LittleEndian.Uint32(header[4:])
+	if version < 1 || version > walVersion {
+		return 0, 0, fmt.Errorf("%w: unsupported version %d in %s", ErrCorruptLog, version, file.Name())
+	}
+
+	offset := int64(walHeaderSize)
+	for {
+		ms, n, err := readRecord(r, info.Size()-offset, version)
+		if err == io.EOF {
+			break
+		}
+
+		if errors.Is(err, errTornRecord) {
+			slog.Warn("Truncating torn record", "offset", offset, "file", file.Name())
+
+			if err := file.Truncate(offset); err != nil {
+				return 0, 0, fmt.Errorf("failed to truncate write-ahead log: %w", err)
+			}
+
+			if err := file.Sync(); err != nil {
+				return 0, 0, fmt.Errorf("failed to sync write-ahead log: %w", err)
+			}
+
+			break
+		}
+
+		if err != nil {
+			return 0, 0, fmt.Errorf("%w at offset %d of %s: %v", ErrCorruptLog, offset, file.Name(), err)
+		}
+
+		for _, m := range ms {
+			replay(m)
+		}
+
+		offset += n
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+
+	return offset, version, nil
+}
+
+// createSegment starts segment seq and makes it the one appended to.
+func (w *wal) createSegment(seq uint64) error {
+	file, err := os.OpenFile(filepath.Join(w.dir, segmentName(seq)), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create write-ahead log: %w", err)
+	}
+
+	header := make([]byte, walHeaderSize)
+	copy(header, walMagic)
+	binary.LittleEndian.PutUint32(header[4:], walVersion)
+
+	if _, err := file.Write(header); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write write-ahead log header: %w", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync write-ahead log: %w", err)
+	}
+
+	if err := syncDir(w.dir); err != nil {
+		file.Close()
+		return err
+	}
+
+	w.file, w.seq, w.size = file, seq, walHeaderSize
+	return nil
+}
+
+// rotate syncs and closes the current segment and starts the next one,
+// returning its number. Everything appended before rotate returns lives in
+// segments numbered lower than that.
+func (w *wal) rotate() (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.failed != nil {
+		return 0, w.failed
+	}
+
+	if err := w.file.Sync(); err != nil {
+		w.failed = fmt.Errorf("write-ahead log unusable after failed sync: %w", err)
+		return 0, w.failed
+	}
+
+	previous := w.file
+	if err := w.createSegment(w.seq + 1); err != nil {
+		return 0, err
+	}
+
+	w.dirty = false
+	previous.Close()
+
+	return w.seq, nil
+}
+
+// removeSegmentsBefore deletes the segments numbered lower than seq.
+func (w *wal) removeSegmentsBefore(seq uint64) error {
+	seqs, err := listSegments(w.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range seqs {
+		if s >= seq {
+			break
+		}
+
+		if err := os.Remove(filepath.Join(w.dir, segmentName(s))); err != nil {
+			return fmt.Errorf("failed to remove write-ahead log segment: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// append writes ms as a single record and, under FsyncAlways, syncs it.
+func (w *wal) append(ms []mutation) error {
+	record := encodeRecord(ms)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.failed != nil {
+		return w.failed
+	}
+
+	if _, err := w.file.Write(record); err != nil {
+		// Drop whatever part of the record made it out so the next
+		// record does not land behind garbage.
+		if terr := w.file.Truncate(w.size); terr != nil {
+			w.failed = fmt.Errorf("write-ahead log unusable after failed write: %w", terr)
+		} else {
+			w.file.Seek(w.size, io.SeekStart)
+		}
+
+		return fmt.Errorf("failed to append to write-ahead log: %w", err)
+	}
+
+	w.size += int64(len(record))
+
+	if w.policy.Mode == FsyncAlways {
+		if err := w.file.Sync(); err != nil {
+			// After a failed fsync the kernel may have dropped the dirty
+			// pages, so nothing written from here on can be trusted.
+			w.failed = fmt.Errorf("write-ahead log unusable after failed sync: %w", err)
+			return w.failed
+		}
+	} else {
+		w.dirty = true
+	}
+
+	return nil
+}
+
+func (w *wal) sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.dirty || w.failed != nil {
+		return w.failed
+	}
+
+	if err := w.file.Sync(); err != nil {
+		w.failed = fmt.Errorf("write-ahead log unusable after failed sync: %w", err)
+		return w.failed
+	}
+
+	w.dirty = false
+	return nil
+}
+
+// err returns why the log no longer takes appends, or nil while it does.
+func (w *wal) err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.failed
+}
+
+func (w *wal) syncPeriodically(interval time.Duration) {
+	defer close(w.syncerDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopSyncer:
+			return
+		case <-ticker.C:
+			if err := w.sync(); err != nil {
+				slog.Error("Failed to sync write-ahead log", "error", err)
+			}
+		}
+	}
+}
+
+func (w *wal) close() error {
+	if w.stopSyncer != nil {
+		close(w.stopSyncer)
+		<-w.syncerDone
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.failed == nil {
+		if err := w.file.Sync(); err != nil {
+			w.file.Close()
+			return fmt.Errorf("failed to sync write-ahead log: %w", err)
+		}
+	}
+
+	return w.file.Close()
+}
+
+var errTornRecord = errors.New("torn record")
+
+// readRecord reads the next record from r. remaining is the number of bytes
+// left in the file and is used to tell a record torn by a crash, which can
+// only be the last one, from corruption in the middle of the log.
+func readRecord(r *bufio.Reader, remaining int64, version uint32) ([]mutation, int64, error) {
+	if remaining == 0 {
+		return nil, 0, io.EOF
+	}
+
+	if remaining < recordHeaderSize {
+		return nil, 0, errTornRecord
+	}
+
+	header := make([]byte, recordHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, errTornRecord
+	}
+
+	length := int64(binary.LittleEndian.Uint32(header[0:4]))
+	checksum := binary.LittleEndian.Uint32(header[4:8])
+	end := recordHeaderSize + length
+
+	if length == 0 {
+		// Space the filesystem allocated but the crash never let us fill.
+		if allZero(r) {
+			return nil, 0, errTornRecord
+		}
+		return nil, 0, errors.New("empty record")
+	}
+
+	if end > remaining || length > maxRecordSize {
+		if end >= remaining {
+			return nil, 0, errTornRecord
+		}
+		return nil, 0, fmt.Errorf("record length %d exceeds limit", length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, errTornRecord
+	}
+
+	if crc32.Checksum(payload, crcTable) != checksum {
+		if end == remaining {
+			return nil, 0, errTornRecord
+		}
+		return nil, 0, errors.New("checksum mismatch")
+	}
+
+	ms, err := decodeMutations(payload, version)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return ms, end, nil
+}
+
+func encodeRecord(ms []mutation) []byte {
+	payload := binary.AppendUvarint(nil, uint64(len(ms)))
+	for _, m := range ms {
+		payload = append(payload, byte(m.op))
+		payload = appendString(payload, m.key)
+
+		if m.op == opPut {
+			payload = appendString(payload, m.value)
+			payload = binary.AppendVarint(payload, unixNano(m.expiresAt))
+		}
+
+		payload = binary.AppendUvarint(payload, uint64(m.version))
+	}
+
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(payload))
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.Checksum(payload, crcTable))
+
+	return append(record, payload...)
+}
+
+func decodeMutations(payload []byte, version uint32) ([]mutation, error) {
+	d := decoder{buf: payload}
+
+	count := d.uvarint()
+	if d.err == nil && count > uint64(len(payload)) {
+		return nil, errors.New("bad mutation count")
+	}
+
+	ms := make([]mutation, 0, count)
+	for n := uint64(0); n < count && d.err == nil; n++ {
+		m := mutation{op: opType(d.byte())}
+		m.key = d.string()
+
+		switch m.op {
+		case opPut:
+			m.value = d.string()
+			m.expiresAt = fromUnixNano(d.varint())
+		case opDelete:
+		default:
+			return nil, fmt.Errorf("unknown op %d", m.op)
+		}
+
+		if version >= 2 {
+			m.version = int64(d.uvarint())
+		}
+
+		ms = append(ms, m)
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return ms, nil
+}
+
+type decoder struct {
+	buf []byte
+	err error
+}
+
+var errShortBuffer = errors.New("unexpected end of data")
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+
+	if len(d.buf) == 0 {
+		d.err = errShortBuffer
+		return 0
+	}
+
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = errShortBuffer
+		return 0
+	}
+
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = errShortBuffer
+		return 0
+	}
+
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+
+	if n > uint64(len(d.buf)) {
+		d.err = errShortBuffer
+		return ""
+	}
+
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+func allZero(r io.Reader) bool {
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			if b != 0 {
+				return false
+			}
+		}
+
+		if err != nil {
+			return err == io.EOF
+		}
+	}
+}
+
+// syncDir makes a file creation or rename in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory %s: %w", dir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,320 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// eventLogSize is how many recent events are kept for watches to
+	// replay from, and for slow watches to catch up from.
+	eventLogSize = 10000
+
+	// watchBuffer is how many events a watch queues for its consumer.
+	watchBuffer = 64
+	// watchBatch is roughly how many events a watch reads from the log at
+	// a time. Events of one revision are never split across reads.
+	watchBatch = 256
+)
+
+type EventType int
+
+const (
+	EventPut EventType = iota
+	EventDelete
+)
+
+// Event is a single key changing at a revision. Every key a Txn writes gets
+// its own event, all at the Txn's revision. Keys removed because they
+// expired do not produce events.
+type Event struct {
+	Type     EventType
+	Key      string
+	Value    string
+	Revision int64
+}
+
+type WatchOptions struct {
+	Key string
+	// Prefix watches every key starting with Key rather than Key alone.
+	Prefix bool
+	// StartRevision replays the events from this revision on. Zero only
+	// reports events written after the watch starts.
+	StartRevision int64
+}
+
+func (o WatchOptions) matches(key string) bool {
+	if o.Prefix {
+		return strings.HasPrefix(key, o.Key)
+	}
+	return key == o.Key
+}
+
+// Watch delivers the events selected by its WatchOptions in revision order.
+// Writers never wait on a watch: it reads from the store's event log at its
+// own pace, and fails with ErrCompacted if it falls so far behind that the
+// events it still needs have left the log.
+type Watch struct {
+	events chan Event
+	notify chan struct{}
+	stop   chan struct{}
+	once   sync.Once
+	err    error
+}
+
+// Events is closed when the watch ends. Err then tells why.
+func (w *Watch) Events() <-chan Event {
+	return w.events
+}
+
+// Err returns the error that ended the watch, or nil if it was closed.
+// It must only be called once Events is closed.
+func (w *Watch) Err() error {
+	return w.err
+}
+
+func (w *Watch) Close() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+}
+
+// filter returns a watch delivering the events of w that keep reports
+// true for, as keep rewrites them. Closing it closes w.
+func (w *Watch) filter(keep func(Event) (Event, bool)) *Watch {
+	filtered := &Watch{
+		events: make(chan Event, watchBuffer),
+		stop:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(filtered.events)
+		defer w.Close()
+
+		for {
+			select {
+			case ev, ok := <-w.Events():
+				if !ok {
+					filtered.err = w.Err()
+					return
+				}
+
+				if ev, ok = keep(ev); !ok {
+					continue
+				}
+
+				select {
+				case filtered.events <- ev:
+				case <-filtered.stop:
+					return
+				}
+			case <-filtered.stop:
+				return
+			}
+		}
+	}()
+
+	return filtered
+}
+
+func (w *Watch) run(log *eventLog, opts WatchOptions, next int64) {
+	defer close(w.events)
+	defer log.unsubscribe(w)
+
+	for {
+		batch, err := log.since(next, watchBatch)
+		if err != nil {
+			w.err = err
+			return
+		}
+
+		if len(batch) == 0 {
+			select {
+			case <-w.notify:
+				continue
+			case <-w.stop:
+				return
+			}
+		}
+
+		for _, ev := range batch {
+			if !opts.matches(ev.Key) {
+				continue
+			}
+
+			select {
+			case w.events <- ev:
+			case <-w.stop:
+				return
+			}
+		}
+
+		next = batch[len(batch)-1].Revision + 1
+	}
+}
+
+// eventLog is a bounded ring of the most recent events.
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event
+	head   int // index of the oldest event
+	count  int
+
+	// rev is the revision of the newest event, or the store revision the
+	// log started at.
+	rev int64
+	// compacted is the newest revision whose events are, at least in
+	// part, no longer in the log.
+	compacted int64
+
+	watches map[*Watch]struct{}
+	closed  bool
+}
+
+// newEventLog returns an empty log for a store at rev. Watches can only
+// start after rev.
+func newEventLog(size int, rev int64) *eventLog {
+	return &eventLog{
+		events:    make([]Event, size),
+		rev:       rev,
+		compacted: rev,
+		watches:   make(map[*Watch]struct{}),
+	}
+}
+
+// append records the mutations of a commit that were given revisions after
+// prev, and wakes every watch.
+func (l *eventLog) append(prev int64, ms []mutation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, m := range ms {
+		if m.version <= prev {
+			// A rewrite that kept the key's version, like Persist.
+			continue
+		}
+
+		ev := Event{Type: EventPut, Key: m.key, Value: m.value, Revision: m.version}
+		if m.op == opDelete {
+			ev = Event{Type: EventDelete, Key: m.key, Revision: m.version}
+		}
+
+		if l.count == len(l.events) {
+			l.compacted = l.events[l.head].Revision
+			l.head = (l.head + 1) % len(l.events)
+			l.count--
+		}
+
+		l.events[(l.head+l.count)%len(l.events)] = ev
+		l.count++
+		l.rev = max(l.rev, ev.Revision)
+	}
+
+	for w := range l.watches {
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (l *eventLog) watch(opts WatchOptions) (*Watch, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil, ErrClosed
+	}
+
+	next := opts.StartRevision
+	if next == 0 {
+		next = l.rev + 1
+	} else if next <= l.compacted {
+		return nil, ErrCompacted
+	}
+
+	w := &Watch{
+		events: make(chan Event, watchBuffer),
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+	l.watches[w] = struct{}{}
+
+	go w.run(l, opts, next)
+
+	return w, nil
+}
+
+// since returns the events from revision next on, about limit of them.
+func (l *eventLog) since(next int64, limit int) ([]Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil, ErrClosed
+	}
+
+	if next <= l.compacted {
+		return nil, ErrCompacted
+	}
+
+	k := sort.Search(l.count, func(k int) bool {
+		return l.at(k).Revision >= next
+	})
+
+	var batch []Event
+	for ; k < l.count; k++ {
+		ev := l.at(k)
+		if len(batch) >= limit && ev.Revision != batch[len(batch)-1].Revision {
+			break
+		}
+		batch = append(batch, ev)
+	}
+
+	return batch, nil
+}
+
+func (l *eventLog) at(k int) Event {
+	return l.events[(l.head+k)%len(l.events)]
+}
+
+func (l *eventLog) unsubscribe(w *Watch) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.watches, w)
+}
+
+// reset empties the log for a store whose keyspace was replaced wholesale
+// at rev. Watches that have not yet seen rev fail with ErrCompacted.
+func (l *eventLog) reset(rev int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.head = 0
+	l.count = 0
+	l.rev = rev
+	l.compacted = rev
+
+	for w := range l.watches {
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// close ends every watch with ErrClosed.
+func (l *eventLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closed = true
+	for w := range l.watches {
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -1,59 +1,519 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/hashicorp/raft"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
+	"GRPC-KV-Store-System/kvStore-service/internal/resp"
 	"GRPC-KV-Store-System/kvStore-service/internal/server"
 	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	"GRPC-KV-Store-System/schemas/certs"
 	pb "GRPC-KV-Store-System/schemas/grpc"
+	"GRPC-KV-Store-System/schemas/logging"
+	"GRPC-KV-Store-System/schemas/rbac"
+	"GRPC-KV-Store-System/schemas/tracing"
 )
 
 var (
-	port = flag.Int("port", 50051, "The server port")
+	port             = flag.Int("port", 50051, "The server port")
+	respAddr         = flag.String("resp-addr", "", "Address to serve the Redis protocol (RESP) on, such as :6379. Disabled when empty, and unavailable with --rbac-policy or --quotas")
+	respReadTimeout  = flag.Duration("resp-read-timeout", 5*time.Minute, "Disconnect Redis clients that take longer to send a command, idle ones included. 0 for no timeout")
+	metricsAddr      = flag.String("metrics-addr", ":9090", "Address to serve Prometheus metrics on over HTTP, at /metrics. Disabled when empty")
+	sweepInterval    = flag.Duration("sweep-interval", time.Second, "How often expired keys are removed in the background")
+	dataDir          = flag.String("data-dir", "", "Directory for the write-ahead log and snapshots. Keys are kept in memory only when empty")
+	fsync            = flag.String("fsync", "always", "When to fsync the write-ahead log: always, never or an interval such as 100ms")
+	snapshotInterval = flag.Duration("snapshot-interval", 5*time.Minute, "How often to snapshot the keyspace and truncate the write-ahead log, 0 to disable")
+	maxMemory        = flag.String("max-memory", "0", "Approximate memory the keys may take up, such as 512mb or 2gb. 0 means no limit")
+	evictionPolicy   = flag.String("eviction-policy", "noeviction", "What to do when --max-memory is reached: noeviction, allkeys-lru, allkeys-lfu or volatile-ttl")
+	quotasFile       = flag.String("quotas", "", "JSON file of per-namespace quotas on keys, bytes, value size and operations per second. No quotas when empty")
+	stripes          = flag.Int("stripes", 0, "Spread in-memory keys across this many separately locked stripes, for write-heavy loads. 0 keeps a single lock")
+	rbacPolicy       = flag.String("rbac-policy", "", "JSON file of roles on key prefixes and the principals bound to them, reloaded on SIGHUP. No access control when empty")
+	rbacDryRun       = flag.Bool("rbac-dry-run", false, "Log why requests would be denied by --rbac-policy instead of denying them")
+	rbacProxies      = flag.String("rbac-trusted-proxies", "", "Comma-separated common names of client certificates, such as the api-service's, trusted to name the principal they make requests for. Include the other nodes' when writes are forwarded")
+
+	tlsCert              = flag.String("tls-cert", "", "PEM certificate to serve gRPC over TLS with, reloaded when the file changes. Plaintext when empty")
+	tlsKey               = flag.String("tls-key", "", "PEM key of --tls-cert")
+	tlsCA                = flag.String("tls-ca", "", "PEM bundle of the CAs client certificates, and the certificates of leaders writes are forwarded to, are verified with")
+	tlsRequireClientCert = flag.Bool("tls-require-client-cert", false, "Turn down clients without a certificate signed by one of --tls-ca's CAs (mutual TLS)")
+
+	raftAddr      = flag.String("raft-addr", "", "Address to bind the raft transport to. Setting it replicates the store across a cluster, with --data-dir holding the raft log. With --tls-cert, nodes talk over mutual TLS with that certificate and need --tls-ca")
+	raftAdvertise = flag.String("raft-advertise", "", "Raft address other nodes reach this one on. Defaults to --raft-addr")
+	advertiseAddr = flag.String("advertise-addr", "", "gRPC address other nodes reach this one on, which is also its ID in the cluster")
+	raftPeers     = flag.String("raft-peers", "", "Comma-separated id=raft-address of every node, used to bootstrap a new cluster. Empty bootstraps a single-node cluster")
+	forwardWrites = flag.Bool("forward-writes", true, "Forward writes sent to a follower to the leader instead of failing them with the leader's address")
+
+	traceExporter    = flag.String("trace-exporter", "none", "Where to send OpenTelemetry spans: none, otlp, stdout or file")
+	traceEndpoint    = flag.String("trace-endpoint", "", "OTLP collector host:port. OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4317 when empty")
+	traceInsecure    = flag.Bool("trace-insecure", false, "Send spans to the OTLP collector in plaintext")
+	traceFile        = flag.String("trace-file", "", "File the file exporter appends spans to as JSON")
+	traceSampleRatio = flag.Float64("trace-sample-ratio", 1, "Fraction of new traces to record. Traces started by the api-service follow its decision")
+
+	logLevel          = flag.String("log-level", "info", "Least severe level logged: debug, info, warn or error")
+	logFormat         = flag.String("log-format", "text", "Format of log records: text or json")
+	logValues         = flag.String("log-values", "hash", "How values are logged: hash (a short SHA-256 and the length), omit or plain")
+	logRedactPrefixes = flag.String("log-redact-prefixes", strings.Join(logging.DefaultSensitivePrefixes, ","), "Comma-separated key prefixes, matched ignoring case, of keys logged like values")
+
+	healthCheck = flag.String("health-check", "", "Ask the server at this address, such as localhost:50051, whether it is serving and exit with 1 if not, for container health checks")
 )
 
 func main() {
 	flag.Parse()
 
-	log.Println("Starting gRPC server...")
+	if err := setupLogging(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *healthCheck != "" {
+		if err := checkHealth(*healthCheck); err != nil {
+			logging.Fatal("Unhealthy", "error", err)
+		}
+		slog.Info("Healthy")
+		return
+	}
+
+	// Redis clients reach the store directly, past the authorizer and the
+	// quotas, so serving them would undo both.
+	if *respAddr != "" && (*rbacPolicy != "" || *quotasFile != "") {
+		logging.Fatal("Invalid flags", "error", "--resp-addr cannot be combined with --rbac-policy or --quotas")
+	}
+
+	slog.Info("Starting gRPC server...")
 
 	lis, ListenerErr := net.Listen("tcp", fmt.Sprintf(":%d", *port))
 
 	if ListenerErr != nil {
-		log.Fatalf("Failed to listen: %v", ListenerErr)
+		logging.Fatal("Failed to listen", "error", ListenerErr)
+	}
+
+	kvStore, err := openStore()
+	if err != nil {
+		logging.Fatal("Failed to open store", "error", err)
+	}
+
+	defer kvStore.Close()
+
+	tracerProvider, err := tracing.StartProvider(tracing.Options{
+		ServiceName: "kvstore-service",
+		Exporter:    *traceExporter,
+		Endpoint:    *traceEndpoint,
+		Insecure:    *traceInsecure,
+		File:        *traceFile,
+		SampleRatio: *traceSampleRatio,
+	})
+	if err != nil {
+		logging.Fatal("Failed to set up tracing", "error", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	defer shutdownTracing(tracerProvider)
+
+	// Requests are tagged with their ID first, so every record logged for
+	// them carries it, then measured, so denied and forwarded ones count
+	// too, and authorized before a follower forwards them.
+	metrics := server.StartMetrics(kvStore)
+	unaryInterceptors := []grpc.UnaryServerInterceptor{logging.RequestIDUnaryInterceptor, metrics.UnaryInterceptor}
+	streamInterceptors := []grpc.StreamServerInterceptor{logging.RequestIDStreamInterceptor, metrics.StreamInterceptor}
+	if *rbacPolicy != "" {
+		// Principals are the client certificates callers are verified
+		// with, so without mutual TLS anyone could claim any of them.
+		if !*tlsRequireClientCert {
+			logging.Fatal("Failed to load RBAC policy", "error", "--rbac-policy needs --tls-require-client-cert")
+		}
+
+		enforcer, err := rbac.LoadEnforcer(*rbacPolicy, *rbacDryRun)
+		if err != nil {
+			logging.Fatal("Failed to load RBAC policy", "error", err)
+		}
+		enforcer.ReloadOnSignal(syscall.SIGHUP)
+
+		authorizer := server.StartAuthorizer(enforcer, splitList(*rbacProxies))
+		unaryInterceptors = append(unaryInterceptors, authorizer.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, authorizer.StreamInterceptor)
+	}
+
+	serverCreds, forwardCreds, err := transportCredentials()
+	if err != nil {
+		logging.Fatal("Failed to set up TLS", "error", err)
+	}
+
+	if *raftAddr != "" && *forwardWrites {
+		forwarder := server.StartForwarder(grpc.WithTransportCredentials(forwardCreds), grpc.WithStatsHandler(tracing.ClientHandler()))
+		defer forwarder.Close()
+
+		unaryInterceptors = append(unaryInterceptors, forwarder.UnaryInterceptor)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.Creds(serverCreds),
+		grpc.StatsHandler(tracing.ServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
 
-	kvStore := store.CreateStore()
 	kvServer := server.StartServer(kvStore)
 
+	if *quotasFile != "" {
+		quotas, err := server.LoadQuotas(*quotasFile)
+		if err != nil {
+			logging.Fatal("Failed to load quotas", "error", err)
+		}
+
+		kvServer.SetQuotas(quotas)
+		slog.Info("Enforcing quotas", "file", *quotasFile)
+	}
+
 	pb.RegisterKeyValueStoreServer(grpcServer, kvServer)
+
+	healthServer := server.StartHealth(kvStore, time.Second)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
 	reflection.Register(grpcServer)
 
-	log.Printf("gRPC server is now listening on port %d", *port)
+	slog.Info("gRPC server is now listening", "port", *port)
+
+	var respServer *resp.Server
+	if *respAddr != "" {
+		respLis, err := net.Listen("tcp", *respAddr)
+		if err != nil {
+			logging.Fatal("Failed to listen for RESP", "error", err)
+		}
+
+		// Redis clients have no notion of namespaces, so they get the
+		// default one.
+		respServer = resp.StartServer(store.CreateNamespaces(kvStore).Default())
+		limits := resp.DefaultLimits()
+		limits.ReadTimeout = *respReadTimeout
+		respServer.SetLimits(limits)
+		go func() {
+			if err := respServer.Serve(respLis); err != nil {
+				logging.Fatal("Failed to serve RESP", "error", err)
+			}
+		}()
+
+		slog.Info("RESP server is now listening", "addr", respLis.Addr().String())
+	}
+
+	var metricsServer *http.Server
+	if *metricsAddr != "" {
+		metricsLis, err := net.Listen("tcp", *metricsAddr)
+		if err != nil {
+			logging.Fatal("Failed to listen for metrics", "error", err)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", metrics.Handler())
+		metricsServer = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := metricsServer.Serve(metricsLis); err != nil && err != http.ErrServerClosed {
+				logging.Fatal("Failed to serve metrics", "error", err)
+			}
+		}()
+
+		slog.Info("Metrics are served", "url", "http://"+metricsLis.Addr().String()+"/metrics")
+	}
 
 	go func() {
 		sigint := make(chan os.Signal, 1)
 		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 		<-sigint
 
-		log.Println("Shutting down gRPC server...")
+		slog.Info("Shutting down gRPC server...")
+		if respServer != nil {
+			respServer.Close()
+		}
+		if metricsServer != nil {
+			metricsServer.Close()
+		}
+		healthServer.Shutdown()
+		kvServer.Shutdown()
 		grpcServer.GracefulStop()
 	}()
 
 	ServeErr := grpcServer.Serve(lis)
 
 	if ServeErr != nil {
-		log.Fatalf("Failed to serve: %v", ServeErr)
+		logging.Fatal("Failed to serve", "error", ServeErr)
+	}
+}
+
+// transportCredentials returns the credentials to serve gRPC with, and to
+// forward writes to the leader with, presenting the same certificate.
+func transportCredentials() (credentials.TransportCredentials, credentials.TransportCredentials, error) {
+	if *tlsCert == "" {
+		if *tlsKey != "" || *tlsCA != "" || *tlsRequireClientCert {
+			return nil, nil, errors.New("--tls-key, --tls-ca and --tls-require-client-cert need --tls-cert")
+		}
+
+		slog.Info("No --tls-cert given, serving gRPC in plaintext")
+		return insecure.NewCredentials(), insecure.NewCredentials(), nil
+	}
+
+	opts := certs.Options{
+		CertFile:          *tlsCert,
+		KeyFile:           *tlsKey,
+		CAFile:            *tlsCA,
+		RequireClientCert: *tlsRequireClientCert,
+	}
+
+	serverConfig, err := certs.ServerConfig(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clientConfig, err := certs.ClientConfig(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slog.Info("Serving gRPC over TLS", "cert", *tlsCert, "client_certs_required", *tlsRequireClientCert)
+	return credentials.NewTLS(serverConfig), credentials.NewTLS(clientConfig), nil
+}
+
+func setupLogging() error {
+	return logging.Setup(os.Stderr, logging.Options{
+		Level:  *logLevel,
+		Format: *logFormat,
+		Redaction: logging.Redaction{
+			Values:            *logValues,
+			SensitivePrefixes: splitList(*logRedactPrefixes),
+		},
+	})
+}
+
+// splitList splits a comma-separated flag, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// shutdownTracing sends the spans still buffered before the process exits.
+func shutdownTracing(provider *tracing.Provider) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := provider.Shutdown(ctx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+}
+
+// checkHealth asks the server at addr whether the KeyValueStore service is
+// serving. It stands in for grpc_health_probe in images without one.
+func checkHealth(addr string) error {
+	creds := insecure.NewCredentials()
+	if *tlsCert != "" || *tlsCA != "" {
+		config, err := certs.ClientConfig(certs.Options{CertFile: *tlsCert, KeyFile: *tlsKey, CAFile: *tlsCA})
+		if err != nil {
+			return err
+		}
+		creds = credentials.NewTLS(config)
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
 	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: pb.KeyValueStore_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		return err
+	}
+
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("%s is %s", pb.KeyValueStore_ServiceDesc.ServiceName, resp.Status)
+	}
+
+	return nil
+}
+
+func openStore() (store.Store, error) {
+	maxBytes, err := parseByteSize(*maxMemory)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --max-memory: %w", err)
+	}
+
+	policy, err := store.ParseEvictionPolicy(*evictionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := store.MemoryLimit{MaxBytes: maxBytes, Policy: policy}
+
+	if *stripes > 0 {
+		if *raftAddr != "" || *dataDir != "" || maxBytes > 0 {
+			return nil, errors.New("--stripes cannot be combined with --raft-addr, --data-dir or --max-memory")
+		}
+
+		slog.Info("Striping keys", "stripes", *stripes)
+		return store.CreateStripedStore(*stripes, *sweepInterval), nil
+	}
+
+	if *raftAddr != "" {
+		// Reads are served locally, so nodes would evict different keys.
+		if maxBytes > 0 {
+			return nil, errors.New("--max-memory is not supported with --raft-addr")
+		}
+		return openReplicatedStore()
+	}
+
+	if maxBytes > 0 {
+		slog.Info("Limiting memory", "max_bytes", maxBytes, "eviction_policy", policy)
+	}
+
+	if *dataDir == "" {
+		return store.CreateBoundedStore(*sweepInterval, limit), nil
+	}
+
+	fsyncPolicy, err := store.ParseFsyncPolicy(*fsync)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Persisting keys", "dir", *dataDir, "fsync", *fsync)
+
+	return store.OpenDurableStore(*dataDir, store.DurableOptions{
+		Fsync:            fsyncPolicy,
+		SweepInterval:    *sweepInterval,
+		SnapshotInterval: *snapshotInterval,
+		Memory:           limit,
+	})
+}
+
+func openReplicatedStore() (store.Store, error) {
+	if *advertiseAddr == "" {
+		return nil, errors.New("--advertise-addr is required with --raft-addr")
+	}
+
+	advertise, err := net.ResolveTCPAddr("tcp", cmp.Or(*raftAdvertise, *raftAddr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid raft address: %w", err)
+	}
+
+	peers, err := parsePeers(*raftPeers)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := raftTransport(advertise)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start raft transport: %w", err)
+	}
+
+	slog.Info("Replicating with raft", "id", *advertiseAddr, "raft_addr", advertise.String(), "tls", *tlsCert != "")
+
+	return store.OpenReplicatedStore(store.ReplicationOptions{
+		ID:            *advertiseAddr,
+		Transport:     transport,
+		Peers:         peers,
+		Dir:           *dataDir,
+		SweepInterval: *sweepInterval,
+	})
+}
+
+// raftTransport returns the transport raft traffic goes over. Any node
+// that reaches the raft port can append to the log, so once the gRPC
+// server has a certificate, nodes only take peers with one from --tls-ca.
+func raftTransport(advertise net.Addr) (raft.Transport, error) {
+	if *tlsCert == "" {
+		return raft.NewTCPTransport(*raftAddr, advertise, 3, 10*time.Second, os.Stderr)
+	}
+
+	if *tlsCA == "" {
+		return nil, errors.New("--raft-addr with --tls-cert needs --tls-ca to verify the other nodes with")
+	}
+
+	opts := certs.Options{
+		CertFile:          *tlsCert,
+		KeyFile:           *tlsKey,
+		CAFile:            *tlsCA,
+		RequireClientCert: true,
+	}
+
+	serverConfig, err := certs.ServerConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	clientConfig, err := certs.ClientConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.OpenTLSTransport(*raftAddr, advertise, serverConfig, clientConfig, os.Stderr)
+}
+
+// parsePeers parses a comma-separated list of id=raft-address pairs.
+func parsePeers(s string) ([]raft.Server, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var peers []raft.Server
+	for _, peer := range strings.Split(s, ",") {
+		id, addr, ok := strings.Cut(strings.TrimSpace(peer), "=")
+		if !ok || id == "" || addr == "" {
+			return nil, fmt.Errorf("invalid raft peer %q, expected id=raft-address", peer)
+		}
+
+		peers = append(peers, raft.Server{
+			ID:      raft.ServerID(id),
+			Address: raft.ServerAddress(addr),
+		})
+	}
+
+	return peers, nil
+}
+
+// parseByteSize parses a size in bytes with an optional kb, mb or gb
+// suffix, in powers of 1024.
+func parseByteSize(size string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(size))
+
+	multiplier := int64(1)
+	for suffix, m := range map[string]int64{"kb": 1 << 10, "mb": 1 << 20, "gb": 1 << 30} {
+		if trimmed, ok := strings.CutSuffix(s, suffix); ok {
+			s, multiplier = trimmed, m
+			break
+		}
+	}
+	s = strings.TrimSuffix(s, "b")
+
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("expected a size such as 512mb, got %q", size)
+	}
+	if n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("size %q is too large", size)
+	}
+
+	return n * multiplier, nil
 }
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+func TestBatchRPCs(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	client := dialInProcess(t, server.StartServer(kvStore))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Batch set reports a result per item", func(t *testing.T) {
+		resp, err := client.BatchSet(ctx, &pb.BatchSetRequest{Items: []*pb.SetRequest{
+			{Key: "user:1", Value: "alice"},
+			{Key: "", Value: "nobody"},
+			{Key: "user:2", Value: "bob", TtlSeconds: 60},
+		}})
+		if err != nil {
+			t.Fatalf("BatchSet failed: %v", err)
+		}
+
+		results := resp.Results
+		if len(results) != 3 {
+			t.Fatalf("Expected 3 results, got %d", len(results))
+		}
+
+		if results[0].Code != 0 || results[2].Code != 0 || results[0].Version == 0 || results[0].Version != results[2].Version {
+			t.Errorf("Expected both valid items stored at one version, got %v", results)
+		}
+
+		if codes.Code(results[1].Code) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for the empty key, got %v", results[1])
+		}
+	})
+
+	t.Run("Batch get reports missing keys", func(t *testing.T) {
+		resp, err := client.BatchGet(ctx, &pb.BatchKeysRequest{Keys: []string{"user:2", "missing", "user:1"}})
+		if err != nil {
+			t.Fatalf("BatchGet failed: %v", err)
+		}
+
+		results := resp.Results
+		if results[0].Value != "bob" || results[2].Value != "alice" {
+			t.Errorf("Expected bob and alice, got %v", results)
+		}
+
+		if codes.Code(results[1].Code) != codes.NotFound {
+			t.Errorf("Expected NotFound for the missing key, got %v", results[1])
+		}
+	})
+
+	t.Run("Batch delete reports missing keys", func(t *testing.T) {
+		resp, err := client.BatchDelete(ctx, &pb.BatchKeysRequest{Keys: []string{"user:1", "user:1"}})
+		if err != nil {
+			t.Fatalf("BatchDelete failed: %v", err)
+		}
+
+		if resp.Results[0].Code != 0 || codes.Code(resp.Results[1].Code) != codes.NotFound {
+			t.Errorf("Expected the second delete to find nothing, got %v", resp.Results)
+		}
+	})
+
+	t.Run("Try a batch over the limit", func(t *testing.T) {
+		_, err := client.BatchGet(ctx, &pb.BatchKeysRequest{Keys: make([]string, 1001)})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("Bulk load a stream of items", func(t *testing.T) {
+		stream, err := client.BulkLoad(ctx)
+		if err != nil {
+			t.Fatalf("BulkLoad failed: %v", err)
+		}
+
+		for n := 0; n < 1200; n++ {
+			item := &pb.SetRequest{Key: fmt.Sprintf("bulk:%04d", n), Value: fmt.Sprint(n)}
+			if n == 700 {
+				item.Key = ""
+			}
+
+			if err := stream.Send(item); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+		}
+
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			t.Fatalf("BulkLoad failed: %v", err)
+		}
+
+		if resp.Loaded != 1199 || resp.Failed != 1 || len(resp.Failures) != 1 || resp.Failures[0].Index != 700 {
+			t.Errorf("Unexpected response %v", resp)
+		}
+
+		if value, err := kvStore.Get("bulk:1199"); err != nil || value != "1199" {
+			t.Errorf("Expected '1199', got '%s' (%v)", value, err)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"testing"
+	"time"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+)
+
+// The store benchmarks compare the single-lock InMemoryStore with a
+// StripedStore across read/write mixes. Run them at several core counts
+// with:
+//
+//	go test ./test -run '^$' -bench Store -cpu 1,4,8,16
+
+const benchKeys = 1 << 14
+
+var benchStores = []struct {
+	name string
+	open func() store.Store
+}{
+	{"memory", func() store.Store { return store.CreateExpiringStore(time.Minute) }},
+	{"striped", func() store.Store { return store.CreateStripedStore(4*runtime.GOMAXPROCS(0), time.Minute) }},
+}
+
+func benchmarkMix(b *testing.B, readPercent int) {
+	keys := make([]string, benchKeys)
+	for n := range keys {
+		keys[n] = fmt.Sprintf("key:%05d", n)
+	}
+
+	for _, bs := range benchStores {
+		b.Run(bs.name, func(b *testing.B) {
+			kvStore := bs.open()
+			defer kvStore.Close()
+
+			for _, key := range keys {
+				kvStore.Set(key, "value")
+			}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				r := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+				for pb.Next() {
+					key := keys[r.IntN(benchKeys)]
+					if r.IntN(100) < readPercent {
+						kvStore.Get(key)
+					} else {
+						kvStore.Set(key, "value")
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkStoreReadHeavy(b *testing.B) { benchmarkMix(b, 90) }
+
+func BenchmarkStoreMixed(b *testing.B) { benchmarkMix(b, 50) }
+
+func BenchmarkStoreWriteHeavy(b *testing.B) { benchmarkMix(b, 10) }
+
+func BenchmarkStoreWriteOnly(b *testing.B) { benchmarkMix(b, 0) }
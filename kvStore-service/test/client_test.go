@@ -3,6 +3,7 @@ package test
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 
 	"GRPC-KV-Store-System/kvStore-service/internal/server"
 	"GRPC-KV-Store-System/kvStore-service/internal/store"
@@ -50,6 +52,30 @@ func setupTestServer(t *testing.T) (*grpc.Server, func()) {
 	return grpcServer, cleanup
 }
 
+// dialInProcess serves kvServer over an in-memory listener and returns a
+// client for it. Both are stopped when the test ends.
+func dialInProcess(t *testing.T, kvServer *server.Server, opts ...grpc.ServerOption) pb.KeyValueStoreClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterKeyValueStoreServer(grpcServer, kvServer)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewKeyValueStoreClient(conn)
+}
+
 func TestKVStoreIntegration(t *testing.T) {
 	_, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -178,4 +204,180 @@ func TestKVStoreIntegration(t *testing.T) {
 		}
 		t.Logf("Expected error received: %s", st.Message())
 	})
+
+	t.Run("Set a key with a ttl", func(t *testing.T) {
+		_, err := client.Set(ctx, &pb.SetRequest{
+			Key:        "session",
+			Value:      "token",
+			TtlSeconds: 60,
+		})
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		resp, err := client.TTL(ctx, &pb.TTLRequest{
+			Key: "session",
+		})
+		if err != nil {
+			t.Fatalf("TTL failed: %v", err)
+		}
+		if resp.TtlSeconds <= 0 || resp.TtlSeconds > 60 {
+			t.Errorf("Expected ttl in (0, 60], got %d", resp.TtlSeconds)
+		}
+		t.Logf("TTL response: %d", resp.TtlSeconds)
+	})
+
+	t.Run("Persist a key with a ttl", func(t *testing.T) {
+		_, err := client.Persist(ctx, &pb.PersistRequest{
+			Key: "session",
+		})
+		if err != nil {
+			t.Fatalf("Persist failed: %v", err)
+		}
+
+		resp, err := client.TTL(ctx, &pb.TTLRequest{
+			Key: "session",
+		})
+		if err != nil {
+			t.Fatalf("TTL failed: %v", err)
+		}
+		if resp.TtlSeconds != -1 {
+			t.Errorf("Expected -1, got %d", resp.TtlSeconds)
+		}
+	})
+
+	t.Run("Try setting a negative ttl", func(t *testing.T) {
+		_, err := client.Set(ctx, &pb.SetRequest{
+			Key:        "session",
+			Value:      "token",
+			TtlSeconds: -5,
+		})
+		st, ok := status.FromError(err)
+		if !ok || st.Code() != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("Try retrieving an expired key", func(t *testing.T) {
+		_, err := client.Set(ctx, &pb.SetRequest{
+			Key:        "short-lived",
+			Value:      "value",
+			TtlSeconds: 1,
+		})
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		time.Sleep(1100 * time.Millisecond)
+
+		_, err = client.Get(ctx, &pb.GetRequest{
+			Key: "short-lived",
+		})
+		st, ok := status.FromError(err)
+		if !ok || st.Code() != codes.NotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+
+	t.Run("Compare and swap a key", func(t *testing.T) {
+		setResp, err := client.Set(ctx, &pb.SetRequest{
+			Key:   "config",
+			Value: "v1",
+		})
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		getResp, err := client.Get(ctx, &pb.GetRequest{
+			Key: "config",
+		})
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if getResp.Version != setResp.Version {
+			t.Errorf("Expected version %d, got %d", setResp.Version, getResp.Version)
+		}
+
+		casResp, err := client.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{
+			Key:      "config",
+			Expected: &pb.CompareAndSwapRequest_ExpectedVersion{ExpectedVersion: getResp.Version},
+			Value:    "v2",
+		})
+		if err != nil {
+			t.Fatalf("CompareAndSwap failed: %v", err)
+		}
+		t.Logf("CompareAndSwap response: %d", casResp.Version)
+	})
+
+	t.Run("Compare and swap a stale version", func(t *testing.T) {
+		_, err := client.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{
+			Key:      "config",
+			Expected: &pb.CompareAndSwapRequest_ExpectedValue{ExpectedValue: "v1"},
+			Value:    "v3",
+		})
+		st, ok := status.FromError(err)
+		if !ok || st.Code() != codes.FailedPrecondition {
+			t.Errorf("Expected FailedPrecondition, got %v", err)
+		}
+	})
+
+	t.Run("Scan keys by prefix a page at a time", func(t *testing.T) {
+		for _, key := range []string{"scan:c", "scan:a", "scan:b", "other"} {
+			if _, err := client.Set(ctx, &pb.SetRequest{Key: key, Value: key}); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+		}
+
+		var keys []string
+		token := ""
+		for {
+			resp, err := client.Scan(ctx, &pb.ScanRequest{Prefix: "scan:", Limit: 2, PageToken: token})
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			for _, item := range resp.Items {
+				keys = append(keys, item.Key)
+			}
+			if resp.NextPageToken == "" {
+				break
+			}
+			token = resp.NextPageToken
+		}
+
+		if fmt.Sprint(keys) != "[scan:a scan:b scan:c]" {
+			t.Errorf("Expected [scan:a scan:b scan:c], got %v", keys)
+		}
+		t.Logf("Scanned keys: %v", keys)
+	})
+
+	t.Run("Stream a key range", func(t *testing.T) {
+		stream, err := client.ScanStream(ctx, &pb.ScanRequest{Start: "scan:b", End: "scan:z"})
+		if err != nil {
+			t.Fatalf("ScanStream failed: %v", err)
+		}
+
+		var keys []string
+		for {
+			item, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("ScanStream failed: %v", err)
+			}
+			keys = append(keys, item.Key)
+		}
+
+		if fmt.Sprint(keys) != "[scan:b scan:c]" {
+			t.Errorf("Expected [scan:b scan:c], got %v", keys)
+		}
+	})
+
+	t.Run("Try scanning with a bad page token", func(t *testing.T) {
+		_, err := client.Scan(ctx, &pb.ScanRequest{PageToken: "not a token"})
+		st, ok := status.FromError(err)
+		if !ok || st.Code() != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
 }
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+func TestCounters(t *testing.T) {
+	for _, bs := range benchStores {
+		t.Run(bs.name, func(t *testing.T) {
+			kvStore := bs.open()
+			defer kvStore.Close()
+
+			t.Run("Missing keys start from the initial value", func(t *testing.T) {
+				value, version, err := kvStore.Increment("hits", 5, store.CounterOptions{Initial: 10})
+				if err != nil || value != 15 {
+					t.Fatalf("Expected 15, got %d (%v)", value, err)
+				}
+
+				if stored, storedVersion, _ := kvStore.GetWithVersion("hits"); stored != "15" || storedVersion != version {
+					t.Errorf("Expected '15' at %d, got '%s' at %d", version, stored, storedVersion)
+				}
+
+				if value, _, _ := kvStore.Increment("hits", -20, store.CounterOptions{}); value != -5 {
+					t.Errorf("Expected -5, got %d", value)
+				}
+			})
+
+			t.Run("Concurrent increments are not lost", func(t *testing.T) {
+				var wg sync.WaitGroup
+				for range 8 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for range 100 {
+							kvStore.Increment("concurrent", 1, store.CounterOptions{})
+						}
+					}()
+				}
+				wg.Wait()
+
+				if value, _ := kvStore.Get("concurrent"); value != "800" {
+					t.Errorf("Expected 800, got %s", value)
+				}
+			})
+
+			t.Run("Try incrementing a value that is not an integer", func(t *testing.T) {
+				kvStore.Set("name", "alice")
+
+				if _, _, err := kvStore.Increment("name", 1, store.CounterOptions{}); !errors.Is(err, store.ErrNotInteger) {
+					t.Errorf("Expected ErrNotInteger, got %v", err)
+				}
+			})
+
+			t.Run("Try incrementing past the bounds", func(t *testing.T) {
+				limit := int64(3)
+				for n := range 3 {
+					if _, _, err := kvStore.Increment("bounded", 1, store.CounterOptions{Max: &limit}); err != nil {
+						t.Fatalf("Increment %d failed: %v", n, err)
+					}
+				}
+
+				if _, _, err := kvStore.Increment("bounded", 1, store.CounterOptions{Max: &limit}); !errors.Is(err, store.ErrOutOfRange) {
+					t.Errorf("Expected ErrOutOfRange, got %v", err)
+				}
+
+				kvStore.Set("big", "9223372036854775807")
+				if _, _, err := kvStore.Increment("big", 1, store.CounterOptions{}); !errors.Is(err, store.ErrOutOfRange) {
+					t.Errorf("Expected ErrOutOfRange on overflow, got %v", err)
+				}
+
+				if value, _ := kvStore.Get("bounded"); value != "3" {
+					t.Errorf("Expected a failed increment to leave 3, got %s", value)
+				}
+			})
+
+			t.Run("Counters keep their expiry", func(t *testing.T) {
+				kvStore.SetWithTTL("window", "0", time.Hour)
+				kvStore.Increment("window", 1, store.CounterOptions{})
+
+				if ttl, err := kvStore.TTL("window"); err != nil || ttl <= 0 {
+					t.Errorf("Expected the ttl to be kept, got %v (%v)", ttl, err)
+				}
+			})
+		})
+	}
+}
+
+func TestCounterRPCs(t *testing.T) {
+	ctx := context.Background()
+
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+	kvServer := server.StartServer(kvStore)
+
+	t.Run("Increment by one by default", func(t *testing.T) {
+		resp, err := kvServer.Increment(ctx, &pb.CounterRequest{Key: "hits"})
+		if err != nil || resp.Value != 1 || resp.Version == 0 {
+			t.Errorf("Expected 1, got %v (%v)", resp, err)
+		}
+	})
+
+	t.Run("Decrement by a delta", func(t *testing.T) {
+		delta := int64(3)
+		resp, err := kvServer.Decrement(ctx, &pb.CounterRequest{Key: "hits", Delta: &delta})
+		if err != nil || resp.Value != -2 {
+			t.Errorf("Expected -2, got %v (%v)", resp, err)
+		}
+	})
+
+	t.Run("Try counting past a bound", func(t *testing.T) {
+		floor := int64(-2)
+		_, err := kvServer.Decrement(ctx, &pb.CounterRequest{Key: "hits", Min: &floor})
+		if status.Code(err) != codes.OutOfRange {
+			t.Errorf("Expected OutOfRange, got %v", err)
+		}
+	})
+
+	t.Run("Try counting a value that is not an integer", func(t *testing.T) {
+		kvServer.Set(ctx, &pb.SetRequest{Key: "name", Value: "alice"})
+
+		_, err := kvServer.Increment(ctx, &pb.CounterRequest{Key: "name"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("Try decrementing by the smallest int64", func(t *testing.T) {
+		delta := int64(math.MinInt64)
+		_, err := kvServer.Decrement(ctx, &pb.CounterRequest{Key: "hits", Delta: &delta})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+}
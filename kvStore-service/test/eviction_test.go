@@ -0,0 +1,267 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+var evictionValue = strings.Repeat("v", 100)
+
+// entryBytes is the memory a store accounts for one key:N holding
+// evictionValue.
+func entryBytes(t *testing.T) int64 {
+	t.Helper()
+
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	kvStore.Set("key:0", evictionValue)
+	return kvStore.(store.MemoryReporter).MemoryStats().UsedBytes
+}
+
+// openBoundedStore returns a store with room for keys keys.
+func openBoundedStore(t *testing.T, keys int, policy store.EvictionPolicy) store.Store {
+	t.Helper()
+
+	kvStore := store.CreateBoundedStore(time.Hour, store.MemoryLimit{
+		MaxBytes: int64(keys) * entryBytes(t),
+		Policy:   policy,
+	})
+	t.Cleanup(func() { kvStore.Close() })
+
+	return kvStore
+}
+
+func fill(t *testing.T, kvStore store.Store, keys int) {
+	t.Helper()
+
+	for n := range keys {
+		if _, err := kvStore.Set(fmt.Sprintf("key:%d", n), evictionValue); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+}
+
+func expectKeys(t *testing.T, kvStore store.Store, present []string, evicted []string) {
+	t.Helper()
+
+	for _, key := range present {
+		if _, err := kvStore.Get(key); err != nil {
+			t.Errorf("Expected %s to be kept, got %v", key, err)
+		}
+	}
+
+	for _, key := range evicted {
+		if _, err := kvStore.Get(key); !errors.Is(err, store.ErrKeyNotFound) {
+			t.Errorf("Expected %s to be evicted, got %v", key, err)
+		}
+	}
+}
+
+func memoryStats(kvStore store.Store) store.MemoryStats {
+	return kvStore.(store.MemoryReporter).MemoryStats()
+}
+
+func TestStoreEviction(t *testing.T) {
+	t.Run("Try writing past the limit with noeviction", func(t *testing.T) {
+		kvStore := openBoundedStore(t, 5, store.NoEviction)
+		fill(t, kvStore, 5)
+
+		if _, err := kvStore.Set("key:5", evictionValue); !errors.Is(err, store.ErrMemoryLimit) {
+			t.Fatalf("Expected ErrMemoryLimit, got %v", err)
+		}
+
+		// Writes that do not grow the keyspace still go through.
+		if _, err := kvStore.Set("key:0", strings.Repeat("w", 100)); err != nil {
+			t.Errorf("Expected an overwrite of the same size to succeed, got %v", err)
+		}
+
+		kvStore.Delete("key:1")
+		if _, err := kvStore.Set("key:5", evictionValue); err != nil {
+			t.Errorf("Expected a write to fit after a delete, got %v", err)
+		}
+
+		stats := memoryStats(kvStore)
+		if stats.Keys != 5 || stats.Evictions != 0 || stats.RejectedWrites != 1 || stats.UsedBytes > stats.MaxBytes {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("allkeys-lru evicts the least recently used key", func(t *testing.T) {
+		kvStore := openBoundedStore(t, 5, store.AllKeysLRU)
+		fill(t, kvStore, 5)
+
+		kvStore.Get("key:0")
+		kvStore.Set("key:5", evictionValue)
+		expectKeys(t, kvStore, []string{"key:0", "key:5"}, []string{"key:1"})
+
+		kvStore.Set("key:6", evictionValue)
+		expectKeys(t, kvStore, nil, []string{"key:2"})
+
+		if stats := memoryStats(kvStore); stats.Evictions != 2 || stats.Policy != store.AllKeysLRU {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("allkeys-lfu evicts the least frequently used key", func(t *testing.T) {
+		kvStore := openBoundedStore(t, 5, store.AllKeysLFU)
+		fill(t, kvStore, 5)
+
+		for n := 1; n < 5; n++ {
+			kvStore.Get(fmt.Sprintf("key:%d", n))
+		}
+
+		kvStore.Set("key:5", evictionValue)
+		expectKeys(t, kvStore, []string{"key:1", "key:4"}, []string{"key:0"})
+
+		// key:5 has only been written once, the others were also read.
+		kvStore.Set("key:6", evictionValue)
+		expectKeys(t, kvStore, []string{"key:1", "key:6"}, []string{"key:5"})
+	})
+
+	t.Run("volatile-ttl evicts the key closest to expiring", func(t *testing.T) {
+		kvStore := openBoundedStore(t, 4, store.VolatileTTL)
+		kvStore.Set("key:0", evictionValue)
+		kvStore.SetWithTTL("key:1", evictionValue, time.Hour)
+		kvStore.SetWithTTL("key:2", evictionValue, time.Minute)
+		kvStore.SetWithTTL("key:3", evictionValue, 2*time.Hour)
+
+		kvStore.Set("key:4", evictionValue)
+		expectKeys(t, kvStore, []string{"key:0", "key:1", "key:3", "key:4"}, []string{"key:2"})
+
+		// Persisting a key makes it safe from eviction.
+		kvStore.Persist("key:1")
+		kvStore.Set("key:5", evictionValue)
+		expectKeys(t, kvStore, []string{"key:1"}, []string{"key:3"})
+
+		if _, err := kvStore.Set("key:6", evictionValue); !errors.Is(err, store.ErrMemoryLimit) {
+			t.Errorf("Expected ErrMemoryLimit once only keys without a TTL are left, got %v", err)
+		}
+	})
+
+	t.Run("Keys being written are not evicted for themselves", func(t *testing.T) {
+		kvStore := openBoundedStore(t, 2, store.AllKeysLRU)
+		fill(t, kvStore, 2)
+
+		result, err := kvStore.Txn(store.Txn{Success: []store.TxnOp{
+			{Type: store.TxnPut, Key: "key:0", Value: strings.Repeat("w", 100)},
+			{Type: store.TxnPut, Key: "key:2", Value: evictionValue},
+		}})
+		if err != nil {
+			t.Fatalf("Txn failed: %v", err)
+		}
+
+		expectKeys(t, kvStore, []string{"key:0", "key:2"}, []string{"key:1"})
+
+		// The key evicted for the write is deleted at its revision.
+		if version := result.Results[1].Version; version != result.Revision {
+			t.Errorf("Expected the write at revision %d, got %d", result.Revision, version)
+		}
+	})
+
+	t.Run("Try a value larger than the limit", func(t *testing.T) {
+		kvStore := openBoundedStore(t, 2, store.AllKeysLRU)
+		fill(t, kvStore, 2)
+
+		if _, err := kvStore.Set("huge", strings.Repeat("x", 1000)); !errors.Is(err, store.ErrMemoryLimit) {
+			t.Errorf("Expected ErrMemoryLimit, got %v", err)
+		}
+
+		expectKeys(t, kvStore, []string{"key:0", "key:1"}, nil)
+	})
+
+	t.Run("Watches see evicted keys deleted", func(t *testing.T) {
+		kvStore := openBoundedStore(t, 1, store.AllKeysLRU)
+		kvStore.Set("key:0", evictionValue)
+
+		watch, err := kvStore.Watch(store.WatchOptions{Key: "key:", Prefix: true})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+		defer watch.Close()
+
+		kvStore.Set("key:1", evictionValue)
+
+		evicted, put := nextEvent(t, watch), nextEvent(t, watch)
+		if evicted.Type != store.EventDelete || evicted.Key != "key:0" || put.Key != "key:1" || evicted.Revision != put.Revision {
+			t.Errorf("Expected key:0 deleted with key:1 put, got %+v and %+v", evicted, put)
+		}
+	})
+
+	t.Run("Try an invalid policy", func(t *testing.T) {
+		if _, err := store.ParseEvictionPolicy("random"); err == nil {
+			t.Error("Expected an unknown policy to be rejected")
+		}
+
+		if policy, err := store.ParseEvictionPolicy("AllKeys-LRU"); err != nil || policy != store.AllKeysLRU {
+			t.Errorf("Expected allkeys-lru, got %s (%v)", policy, err)
+		}
+	})
+}
+
+func TestDurableStoreEviction(t *testing.T) {
+	dir := t.TempDir()
+	opts := store.DurableOptions{
+		Fsync:  store.FsyncPolicy{Mode: store.FsyncNever},
+		Memory: store.MemoryLimit{MaxBytes: 3 * entryBytes(t), Policy: store.AllKeysLRU},
+	}
+
+	kvStore, err := store.OpenDurableStore(dir, opts)
+	if err != nil {
+		t.Fatalf("OpenDurableStore failed: %v", err)
+	}
+	fill(t, kvStore, 5)
+	kvStore.Close()
+
+	kvStore, err = store.OpenDurableStore(dir, opts)
+	if err != nil {
+		t.Fatalf("OpenDurableStore failed: %v", err)
+	}
+	defer kvStore.Close()
+
+	expectKeys(t, kvStore, []string{"key:2", "key:3", "key:4"}, []string{"key:0", "key:1"})
+
+	// Recovered keys are tracked, so the oldest is evicted first.
+	kvStore.Set("key:5", evictionValue)
+	expectKeys(t, kvStore, []string{"key:3"}, []string{"key:2"})
+}
+
+func TestEvictionRPCs(t *testing.T) {
+	ctx := context.Background()
+
+	kvStore := openBoundedStore(t, 1, store.NoEviction)
+	kvServer := server.StartServer(kvStore)
+
+	if _, err := kvServer.Set(ctx, &pb.SetRequest{Key: "key:0", Value: evictionValue}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	t.Run("Try a Set past the limit", func(t *testing.T) {
+		_, err := kvServer.Set(ctx, &pb.SetRequest{Key: "key:1", Value: evictionValue})
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("Expected ResourceExhausted, got %v", err)
+		}
+	})
+
+	t.Run("Stats reports usage and rejections", func(t *testing.T) {
+		resp, err := kvServer.Stats(ctx, &pb.StatsRequest{})
+		if err != nil {
+			t.Fatalf("Stats failed: %v", err)
+		}
+
+		if resp.KeyCount != 1 || resp.UsedBytes != resp.MaxBytes || resp.RejectedWrites != 1 || resp.EvictionPolicy != "noeviction" {
+			t.Errorf("Unexpected stats %v", resp)
+		}
+	})
+}
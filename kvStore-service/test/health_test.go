@@ -0,0 +1,144 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// replicatedStore stands in for a store replicated across a cluster,
+// reporting whatever leader it is given, and for a store that can fail.
+type replicatedStore struct {
+	store.Store
+	leader atomic.Value
+	failed atomic.Bool
+}
+
+func (s *replicatedStore) Leader() string {
+	leader, _ := s.leader.Load().(string)
+	return leader
+}
+
+func (s *replicatedStore) Err() error {
+	if s.failed.Load() {
+		return errors.New("write-ahead log unusable after failed sync")
+	}
+	return nil
+}
+
+func TestHealth(t *testing.T) {
+	kvStore := &replicatedStore{Store: store.CreateStore()}
+	kvStore.leader.Store("node-1")
+	defer kvStore.Close()
+
+	healthServer := server.StartHealth(kvStore, 10*time.Millisecond)
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	client := healthpb.NewHealthClient(conn)
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q) failed: %v", service, err)
+		}
+		return resp.Status
+	}
+
+	// eventually waits for the next few updates to report want.
+	eventually := func(service string, want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+
+		for range 100 {
+			if check(service) == want {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Errorf("Expected %q to become %s, got %s", service, want, check(service))
+	}
+
+	kvService := pb.KeyValueStore_ServiceDesc.ServiceName
+
+	t.Run("Serving while a leader is known", func(t *testing.T) {
+		if status := check(""); status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Expected the server to be serving, got %s", status)
+		}
+		if status := check(kvService); status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Expected %s to be serving, got %s", kvService, status)
+		}
+	})
+
+	t.Run("Not serving without a leader", func(t *testing.T) {
+		kvStore.leader.Store("")
+		eventually(kvService, healthpb.HealthCheckResponse_NOT_SERVING)
+
+		if status := check(""); status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Expected the server to stay serving, got %s", status)
+		}
+
+		kvStore.leader.Store("node-2")
+		eventually(kvService, healthpb.HealthCheckResponse_SERVING)
+	})
+
+	t.Run("Not serving once the store has failed", func(t *testing.T) {
+		kvStore.failed.Store(true)
+		eventually(kvService, healthpb.HealthCheckResponse_NOT_SERVING)
+
+		if status := check(""); status != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("Expected the server to stay serving, got %s", status)
+		}
+
+		kvStore.failed.Store(false)
+		eventually(kvService, healthpb.HealthCheckResponse_SERVING)
+	})
+
+	t.Run("Try checking an unknown service", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "no.such.Service"}); err == nil {
+			t.Error("Expected an error")
+		}
+	})
+
+	t.Run("Not serving once shut down", func(t *testing.T) {
+		healthServer.Shutdown()
+
+		if status := check(""); status != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("Expected the server to be not serving, got %s", status)
+		}
+		if status := check(kvService); status != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("Expected %s to be not serving, got %s", kvService, status)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+	"GRPC-KV-Store-System/schemas/logging"
+)
+
+// logBuffer collects log records, which the server writes from its own
+// goroutines.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records returns the JSON records logged with msg.
+func (b *logBuffer) records(t *testing.T, msg string) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected JSON records, got %q: %v", line, err)
+		}
+		if record["msg"] == msg {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// setupLogging logs JSON to a buffer until the test ends.
+func setupLogging(t *testing.T, redaction logging.Redaction) *logBuffer {
+	t.Helper()
+
+	previous := slog.Default()
+	t.Cleanup(func() {
+		logging.Setup(&bytes.Buffer{}, logging.Options{
+			Level:     "info",
+			Redaction: logging.Redaction{SensitivePrefixes: logging.DefaultSensitivePrefixes},
+		})
+		slog.SetDefault(previous)
+	})
+
+	buf := &logBuffer{}
+	if err := logging.Setup(buf, logging.Options{Level: "info", Format: logging.JSON, Redaction: redaction}); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	return buf
+}
+
+func TestLogging(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	client := dialInProcess(t, server.StartServer(kvStore),
+		grpc.ChainUnaryInterceptor(logging.RequestIDUnaryInterceptor),
+		grpc.ChainStreamInterceptor(logging.RequestIDStreamInterceptor))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Records carry the caller's request ID and hash values", func(t *testing.T) {
+		logs := setupLogging(t, logging.Redaction{Values: logging.Hash, SensitivePrefixes: logging.DefaultSensitivePrefixes})
+
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(ctx, logging.RequestIDHeader, "req-123")
+		if _, err := client.Set(ctx, &pb.SetRequest{Key: "user/1", Value: "hunter2"}, grpc.Header(&header)); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		if got := header.Get(logging.RequestIDHeader); len(got) != 1 || got[0] != "req-123" {
+			t.Errorf("Expected the request ID in the response header, got %v", got)
+		}
+
+		records := logs.records(t, "Processing Request: Set")
+		if len(records) != 1 {
+			t.Fatalf("Expected one record, got %d", len(records))
+		}
+		record := records[0]
+
+		if record["request_id"] != "req-123" {
+			t.Errorf("Expected request_id req-123, got %v", record["request_id"])
+		}
+		if record["key"] != "user/1" {
+			t.Errorf("Expected the key as it is, got %v", record["key"])
+		}
+		value, ok := record["value"].(map[string]any)
+		if !ok || value["sha256"] == nil || value["len"] != float64(len("hunter2")) {
+			t.Errorf("Expected a hashed value, got %v", record["value"])
+		}
+		if strings.Contains(logs.buf.String(), "hunter2") {
+			t.Error("Expected the value not to be logged")
+		}
+	})
+
+	t.Run("Sensitive keys are redacted", func(t *testing.T) {
+		logs := setupLogging(t, logging.Redaction{Values: logging.Hash, SensitivePrefixes: []string{"secret"}})
+
+		if _, err := client.Get(ctx, &pb.GetRequest{Key: "Secret/db"}); err == nil {
+			t.Fatal("Expected the key to be missing")
+		}
+
+		records := logs.records(t, "Processing Request: Get")
+		if len(records) != 1 {
+			t.Fatalf("Expected one record, got %d", len(records))
+		}
+		if _, ok := records[0]["key"].(map[string]any); !ok {
+			t.Errorf("Expected a hashed key, got %v", records[0]["key"])
+		}
+	})
+
+	t.Run("Values can be omitted", func(t *testing.T) {
+		logs := setupLogging(t, logging.Redaction{Values: logging.Omit})
+
+		if _, err := client.Set(ctx, &pb.SetRequest{Key: "a", Value: "1"}); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		records := logs.records(t, "Processing Request: Set")
+		if len(records) != 1 {
+			t.Fatalf("Expected one record, got %d", len(records))
+		}
+		if _, ok := records[0]["value"]; ok {
+			t.Errorf("Expected no value, got %v", records[0]["value"])
+		}
+	})
+
+	t.Run("Try an overlong request ID", func(t *testing.T) {
+		logs := setupLogging(t, logging.Redaction{})
+
+		ctx := metadata.AppendToOutgoingContext(ctx, logging.RequestIDHeader, strings.Repeat("x", 200))
+		if _, err := client.Get(ctx, &pb.GetRequest{Key: "a"}); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+
+		records := logs.records(t, "Processing Request: Get")
+		if len(records) != 1 {
+			t.Fatalf("Expected one record, got %d", len(records))
+		}
+		id, _ := records[0]["request_id"].(string)
+		if id == "" || strings.HasPrefix(id, "xxx") {
+			t.Errorf("Expected a new request ID, got %q", id)
+		}
+	})
+
+	t.Run("Try invalid options", func(t *testing.T) {
+		for _, opts := range []logging.Options{
+			{Level: "verbose"},
+			{Level: "info", Format: "xml"},
+			{Level: "info", Redaction: logging.Redaction{Values: "mask"}},
+		} {
+			if err := logging.Setup(&bytes.Buffer{}, opts); err == nil {
+				t.Errorf("Expected an error for %+v", opts)
+			}
+		}
+	})
+}
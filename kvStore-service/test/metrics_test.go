@@ -0,0 +1,87 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+func TestMetrics(t *testing.T) {
+	kvStore := store.CreateBoundedStore(time.Hour, store.MemoryLimit{MaxBytes: 1 << 20, Policy: store.AllKeysLRU})
+	defer kvStore.Close()
+
+	metrics := server.StartMetrics(kvStore)
+	client := dialInProcess(t, server.StartServer(kvStore),
+		grpc.ChainUnaryInterceptor(metrics.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(metrics.StreamInterceptor))
+
+	scrape := func() string {
+		rr := httptest.NewRecorder()
+		metrics.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+		body, _ := io.ReadAll(rr.Body)
+		return string(body)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("RPCs are counted by method and code", func(t *testing.T) {
+		client.Set(ctx, &pb.SetRequest{Key: "a", Value: "1"})
+		client.Set(ctx, &pb.SetRequest{Key: "b", Value: "2"})
+		client.Get(ctx, &pb.GetRequest{Key: "missing"})
+
+		body := scrape()
+		for _, line := range []string{
+			`kvstore_grpc_requests_total{code="OK",method="Set",service="kvstore.KeyValueStore"} 2`,
+			`kvstore_grpc_requests_total{code="NotFound",method="Get",service="kvstore.KeyValueStore"} 1`,
+			`kvstore_grpc_request_duration_seconds_count{code="OK",method="Set",service="kvstore.KeyValueStore"} 2`,
+		} {
+			if !strings.Contains(body, line) {
+				t.Errorf("Expected %s in:\n%s", line, body)
+			}
+		}
+	})
+
+	t.Run("Streams are counted when they end", func(t *testing.T) {
+		stream, err := client.BulkLoad(ctx)
+		if err != nil {
+			t.Fatalf("BulkLoad failed: %v", err)
+		}
+		stream.Send(&pb.SetRequest{Key: "c", Value: "3"})
+		if _, err := stream.CloseAndRecv(); err != nil {
+			t.Fatalf("BulkLoad failed: %v", err)
+		}
+
+		if line := `kvstore_grpc_requests_total{code="OK",method="BulkLoad",service="kvstore.KeyValueStore"} 1`; !strings.Contains(scrape(), line) {
+			t.Errorf("Expected %s", line)
+		}
+	})
+
+	t.Run("Store gauges are read at each scrape", func(t *testing.T) {
+		kvStore.SetWithTTL("short", "lived", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+		kvStore.Get("short")
+
+		body := scrape()
+		for _, line := range []string{
+			"kvstore_keys 3",
+			"kvstore_max_bytes 1.048576e+06",
+			"kvstore_evictions_total 0",
+			"kvstore_expirations_total 1",
+		} {
+			if !strings.Contains(body, line+"\n") {
+				t.Errorf("Expected %s in:\n%s", line, body)
+			}
+		}
+	})
+}
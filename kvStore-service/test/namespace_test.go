@@ -0,0 +1,292 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+func scanKeys(t *testing.T, kv store.Store, opts store.ScanOptions) []string {
+	t.Helper()
+
+	items, _, err := kv.Scan(opts)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
+func TestNamespaces(t *testing.T) {
+	for _, bs := range benchStores {
+		t.Run(bs.name, func(t *testing.T) {
+			kvStore := bs.open()
+			defer kvStore.Close()
+
+			namespaces := store.CreateNamespaces(kvStore)
+			for _, name := range []string{"team-a", "team-b"} {
+				if err := namespaces.Create(name); err != nil {
+					t.Fatalf("Failed to create namespace %s: %v", name, err)
+				}
+			}
+
+			teamA, _ := namespaces.Get("team-a")
+			teamB, _ := namespaces.Get("team-b")
+			defaultNS := namespaces.Default()
+
+			t.Run("Keys are isolated", func(t *testing.T) {
+				teamA.Set("config", "a")
+				teamB.Set("config", "b")
+				defaultNS.Set("config", "default")
+
+				for kv, want := range map[store.Store]string{teamA: "a", teamB: "b", defaultNS: "default"} {
+					if value, err := kv.Get("config"); err != nil || value != want {
+						t.Errorf("Expected '%s', got '%s' (%v)", want, value, err)
+					}
+				}
+
+				teamB.Delete("config")
+				if value, _ := teamA.Get("config"); value != "a" {
+					t.Errorf("Expected deleting from team-b to leave team-a alone, got '%s'", value)
+				}
+			})
+
+			t.Run("Scans stay within the namespace", func(t *testing.T) {
+				teamA.Set("user:1", "alice")
+				teamA.Set("user:2", "bob")
+				teamB.Set("user:3", "carol")
+
+				if keys := scanKeys(t, teamA, store.ScanOptions{Prefix: "user:"}); !slices.Equal(keys, []string{"user:1", "user:2"}) {
+					t.Errorf("Unexpected keys %v", keys)
+				}
+				if keys := scanKeys(t, teamA, store.ScanOptions{Start: "d", End: "user:2"}); !slices.Equal(keys, []string{"user:1"}) {
+					t.Errorf("Unexpected keys %v", keys)
+				}
+				if keys := scanKeys(t, defaultNS, store.ScanOptions{}); !slices.Equal(keys, []string{"config"}) {
+					t.Errorf("Expected the default namespace to hide the others, got %v", keys)
+				}
+			})
+
+			t.Run("Transactions stay within the namespace", func(t *testing.T) {
+				result, err := teamB.Txn(store.Txn{
+					Compare: []store.Compare{{Key: "config", Target: store.CompareExists, Exists: false}},
+					Success: []store.TxnOp{{Type: store.TxnPut, Key: "config", Value: "b2"}},
+				})
+				if err != nil || !result.Succeeded || result.Results[0].Key != "config" {
+					t.Fatalf("Unexpected result %+v (%v)", result, err)
+				}
+
+				if value, _ := teamA.Get("config"); value != "a" {
+					t.Errorf("Expected team-a to keep 'a', got '%s'", value)
+				}
+			})
+
+			t.Run("Watches stay within the namespace", func(t *testing.T) {
+				watch, err := teamA.Watch(store.WatchOptions{Prefix: true})
+				if err != nil {
+					t.Fatalf("Failed to watch: %v", err)
+				}
+				defer watch.Close()
+
+				teamB.Set("noise", "x")
+				defaultNS.Set("noise", "x")
+				teamA.Set("signal", "y")
+
+				if ev := nextEvent(t, watch); ev.Key != "signal" || ev.Value != "y" {
+					t.Errorf("Expected the put of 'signal', got %+v", ev)
+				}
+			})
+
+			t.Run("List and drop namespaces", func(t *testing.T) {
+				names, err := namespaces.List()
+				if err != nil || !slices.Equal(names, []string{"team-a", "team-b"}) {
+					t.Fatalf("Unexpected namespaces %v (%v)", names, err)
+				}
+
+				dropped, err := namespaces.Drop("team-a")
+				if err != nil || dropped != 4 {
+					t.Errorf("Expected 4 keys dropped, got %d (%v)", dropped, err)
+				}
+
+				if _, err := namespaces.Get("team-a"); !errors.Is(err, store.ErrNamespaceNotFound) {
+					t.Errorf("Expected ErrNamespaceNotFound, got %v", err)
+				}
+
+				namespaces.Create("team-a")
+				teamA, _ = namespaces.Get("team-a")
+				if keys := scanKeys(t, teamA, store.ScanOptions{}); len(keys) != 0 {
+					t.Errorf("Expected a recreated namespace to be empty, got %v", keys)
+				}
+			})
+
+			t.Run("Try invalid and duplicate namespaces", func(t *testing.T) {
+				if err := namespaces.Create("team-b"); !errors.Is(err, store.ErrNamespaceExists) {
+					t.Errorf("Expected ErrNamespaceExists, got %v", err)
+				}
+				if err := namespaces.Create("no/slashes"); !errors.Is(err, store.ErrInvalidNamespace) {
+					t.Errorf("Expected ErrInvalidNamespace, got %v", err)
+				}
+				if _, err := namespaces.Drop("missing"); !errors.Is(err, store.ErrNamespaceNotFound) {
+					t.Errorf("Expected ErrNamespaceNotFound, got %v", err)
+				}
+			})
+
+			t.Run("Try writing a reserved key to the default namespace", func(t *testing.T) {
+				if _, err := defaultNS.Set("\xffteam-b/config", "x"); !errors.Is(err, store.ErrReservedKey) {
+					t.Errorf("Expected ErrReservedKey, got %v", err)
+				}
+			})
+		})
+	}
+}
+
+func TestNamespacesAreNotEvicted(t *testing.T) {
+	kvStore := store.CreateBoundedStore(time.Minute, store.MemoryLimit{MaxBytes: 4 << 10, Policy: store.AllKeysLRU})
+	defer kvStore.Close()
+
+	namespaces := store.CreateNamespaces(kvStore)
+	namespaces.Create("tenant")
+	tenant, _ := namespaces.Get("tenant")
+
+	for n := range 100 {
+		if _, err := tenant.Set(string(rune('a'+n%26))+string(rune('a'+n/26)), "0123456789abcdef0123456789abcdef"); err != nil {
+			t.Fatalf("Set %d failed: %v", n, err)
+		}
+	}
+
+	if names, _ := namespaces.List(); !slices.Equal(names, []string{"tenant"}) {
+		t.Errorf("Expected the namespace to survive eviction, got %v", names)
+	}
+}
+
+func TestNamespaceRPCs(t *testing.T) {
+	ctx := context.Background()
+
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+	client := dialInProcess(t, server.StartServer(kvStore))
+
+	t.Run("Create and list namespaces", func(t *testing.T) {
+		for _, name := range []string{"orders", "billing"} {
+			if _, err := client.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: name}); err != nil {
+				t.Fatalf("Failed to create %s: %v", name, err)
+			}
+		}
+
+		resp, err := client.ListNamespaces(ctx, &pb.ListNamespacesRequest{})
+		if err != nil || !slices.Equal(resp.Names, []string{"billing", "orders"}) {
+			t.Errorf("Unexpected namespaces %v (%v)", resp, err)
+		}
+	})
+
+	t.Run("Requests use their namespace", func(t *testing.T) {
+		client.Set(ctx, &pb.SetRequest{Namespace: "orders", Key: "1", Value: "pending"})
+		client.Set(ctx, &pb.SetRequest{Key: "1", Value: "default"})
+
+		resp, err := client.Get(ctx, &pb.GetRequest{Namespace: "orders", Key: "1"})
+		if err != nil || resp.Value != "pending" {
+			t.Errorf("Expected 'pending', got %v (%v)", resp, err)
+		}
+
+		_, err = client.Get(ctx, &pb.GetRequest{Namespace: "billing", Key: "1"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+
+		scan, err := client.Scan(ctx, &pb.ScanRequest{})
+		if err != nil || len(scan.Items) != 1 || scan.Items[0].Value != "default" {
+			t.Errorf("Expected the default namespace to hold one key, got %v (%v)", scan, err)
+		}
+	})
+
+	t.Run("Batches use their namespace", func(t *testing.T) {
+		resp, err := client.BatchSet(ctx, &pb.BatchSetRequest{
+			Namespace: "billing",
+			Items: []*pb.SetRequest{
+				{Key: "invoice:1", Value: "10"},
+				{Key: "invoice:2", Value: "20", Namespace: "orders"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("BatchSet failed: %v", err)
+		}
+
+		if resp.Results[0].Code != 0 || codes.Code(resp.Results[1].Code) != codes.InvalidArgument {
+			t.Errorf("Unexpected results %v", resp.Results)
+		}
+
+		got, err := client.BatchGet(ctx, &pb.BatchKeysRequest{Namespace: "billing", Keys: []string{"invoice:1"}})
+		if err != nil || got.Results[0].Value != "10" {
+			t.Errorf("Expected '10', got %v (%v)", got, err)
+		}
+	})
+
+	t.Run("Bulk loads write each item to its namespace", func(t *testing.T) {
+		stream, err := client.BulkLoad(ctx)
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+
+		stream.Send(&pb.SetRequest{Namespace: "orders", Key: "2", Value: "shipped"})
+		stream.Send(&pb.SetRequest{Namespace: "billing", Key: "invoice:2", Value: "20"})
+		stream.Send(&pb.SetRequest{Namespace: "missing", Key: "x", Value: "x"})
+
+		resp, err := stream.CloseAndRecv()
+		if err != nil || resp.Loaded != 2 || resp.Failed != 1 || codes.Code(resp.Failures[0].Code) != codes.NotFound {
+			t.Fatalf("Unexpected response %v (%v)", resp, err)
+		}
+
+		if got, err := client.Get(ctx, &pb.GetRequest{Namespace: "billing", Key: "invoice:2"}); err != nil || got.Value != "20" {
+			t.Errorf("Expected '20', got %v (%v)", got, err)
+		}
+	})
+
+	t.Run("Drop a namespace with its keys", func(t *testing.T) {
+		resp, err := client.DropNamespace(ctx, &pb.DropNamespaceRequest{Name: "orders"})
+		if err != nil || resp.DeletedKeys != 2 {
+			t.Fatalf("Expected 2 keys deleted, got %v (%v)", resp, err)
+		}
+
+		_, err = client.Get(ctx, &pb.GetRequest{Namespace: "orders", Key: "1"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+
+	t.Run("Try a namespace that does not exist", func(t *testing.T) {
+		_, err := client.Set(ctx, &pb.SetRequest{Namespace: "orders", Key: "1", Value: "x"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+
+		_, err = client.DropNamespace(ctx, &pb.DropNamespaceRequest{Name: "orders"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+
+	t.Run("Try creating an invalid or existing namespace", func(t *testing.T) {
+		_, err := client.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: ""})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+
+		_, err = client.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: "billing"})
+		if status.Code(err) != codes.AlreadyExists {
+			t.Errorf("Expected AlreadyExists, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// quotaExceeded reports which quota err went over, and whether it asked to
+// be retried later.
+func quotaExceeded(t *testing.T, err error) (string, bool) {
+	t.Helper()
+
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted, got %v", err)
+	}
+
+	var quota string
+	var retry bool
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.Reason == "QUOTA_EXCEEDED" {
+				quota = d.Metadata["quota"]
+			}
+		case *errdetails.RetryInfo:
+			retry = d.RetryDelay.AsDuration() > 0
+		}
+	}
+	return quota, retry
+}
+
+func TestNamespaceUsage(t *testing.T) {
+	for _, bs := range benchStores {
+		t.Run(bs.name, func(t *testing.T) {
+			kvStore := bs.open()
+			defer kvStore.Close()
+
+			namespaces := store.CreateNamespaces(kvStore)
+			namespaces.Create("team-a")
+			teamA, _ := namespaces.Get("team-a")
+
+			teamA.Set("k1", "abc")
+			teamA.Set("k2", "abcdef")
+			teamA.Set("k2", "ab")
+			teamA.Set("k3", "x")
+			teamA.Delete("k3")
+			namespaces.Default().Set("key", "value")
+
+			if usage, err := namespaces.Usage("team-a"); err != nil || usage != (store.Usage{Keys: 2, Bytes: 9}) {
+				t.Errorf("Expected 2 keys of 9 bytes, got %+v (%v)", usage, err)
+			}
+			if usage, _ := namespaces.Usage(""); usage != (store.Usage{Keys: 1, Bytes: 8}) {
+				t.Errorf("Expected the default namespace to hold 1 key of 8 bytes, got %+v", usage)
+			}
+
+			namespaces.Drop("team-a")
+			if _, err := namespaces.Usage("team-a"); !errors.Is(err, store.ErrNamespaceNotFound) {
+				t.Errorf("Expected ErrNamespaceNotFound, got %v", err)
+			}
+
+			namespaces.Create("team-a")
+			if usage, _ := namespaces.Usage("team-a"); usage != (store.Usage{}) {
+				t.Errorf("Expected a recreated namespace to be empty, got %+v", usage)
+			}
+		})
+	}
+}
+
+func TestQuotas(t *testing.T) {
+	ctx := context.Background()
+
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	kvServer := server.StartServer(kvStore)
+	kvServer.SetQuotas(server.Quotas{
+		Namespaces: map[string]server.Quota{
+			"small":   {MaxKeys: 2, MaxBytes: 30, MaxValueBytes: 16},
+			"limited": {OpsPerSecond: 2},
+		},
+	})
+	client := dialInProcess(t, kvServer)
+
+	for _, name := range []string{"small", "limited", "other"} {
+		client.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: name})
+	}
+
+	t.Run("Try going over the key quota", func(t *testing.T) {
+		for _, key := range []string{"a", "b"} {
+			if _, err := client.Set(ctx, &pb.SetRequest{Namespace: "small", Key: key, Value: "1"}); err != nil {
+				t.Fatalf("Set %s failed: %v", key, err)
+			}
+		}
+
+		_, err := client.Set(ctx, &pb.SetRequest{Namespace: "small", Key: "c", Value: "1"})
+		if quota, retry := quotaExceeded(t, err); quota != "max_keys" || retry {
+			t.Errorf("Expected max_keys without a retry, got %s (retry=%t)", quota, retry)
+		}
+
+		if _, err := client.Set(ctx, &pb.SetRequest{Namespace: "small", Key: "a", Value: "2"}); err != nil {
+			t.Errorf("Expected overwriting a key to fit, got %v", err)
+		}
+	})
+
+	t.Run("Try going over the value and byte quotas", func(t *testing.T) {
+		_, err := client.Set(ctx, &pb.SetRequest{Namespace: "small", Key: "a", Value: "0123456789abcdefg"})
+		if quota, _ := quotaExceeded(t, err); quota != "max_value_bytes" {
+			t.Errorf("Expected max_value_bytes, got %s", quota)
+		}
+
+		if _, err := client.Set(ctx, &pb.SetRequest{Namespace: "small", Key: "a", Value: "0123456789abcdef"}); err != nil {
+			t.Fatalf("Expected a value at the limit to fit, got %v", err)
+		}
+
+		_, err = client.Set(ctx, &pb.SetRequest{Namespace: "small", Key: "b", Value: "0123456789abcdef"})
+		if quota, _ := quotaExceeded(t, err); quota != "max_bytes" {
+			t.Errorf("Expected max_bytes, got %s", quota)
+		}
+
+		_, err = client.BatchSet(ctx, &pb.BatchSetRequest{
+			Namespace: "small",
+			Items:     []*pb.SetRequest{{Key: "a", Value: "0123456789abcdef"}, {Key: "b", Value: "0123456789abcdef0"}},
+		})
+		if quota, _ := quotaExceeded(t, err); quota != "max_value_bytes" {
+			t.Errorf("Expected max_value_bytes, got %s", quota)
+		}
+	})
+
+	t.Run("Try going over the rate quota", func(t *testing.T) {
+		for range 2 {
+			client.Get(ctx, &pb.GetRequest{Namespace: "limited", Key: "k"})
+		}
+
+		_, err := client.Get(ctx, &pb.GetRequest{Namespace: "limited", Key: "k"})
+		if quota, retry := quotaExceeded(t, err); quota != "ops_per_second" || !retry {
+			t.Errorf("Expected ops_per_second with a retry, got %s (retry=%t)", quota, retry)
+		}
+
+		if _, err := client.Set(ctx, &pb.SetRequest{Namespace: "other", Key: "k", Value: "v"}); err != nil {
+			t.Errorf("Expected other namespaces to be unaffected, got %v", err)
+		}
+	})
+
+	t.Run("Bulk loads fail the items over quota", func(t *testing.T) {
+		client.DropNamespace(ctx, &pb.DropNamespaceRequest{Name: "small"})
+		client.CreateNamespace(ctx, &pb.CreateNamespaceRequest{Name: "small"})
+
+		stream, err := client.BulkLoad(ctx)
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+		for _, key := range []string{"a", "b", "c"} {
+			stream.Send(&pb.SetRequest{Namespace: "small", Key: key, Value: "v"})
+		}
+
+		resp, err := stream.CloseAndRecv()
+		if err != nil || resp.Loaded != 2 || resp.Failed != 1 || codes.Code(resp.Failures[0].Code) != codes.ResourceExhausted {
+			t.Errorf("Unexpected response %v (%v)", resp, err)
+		}
+	})
+
+	t.Run("Read usage", func(t *testing.T) {
+		resp, err := client.Usage(ctx, &pb.UsageRequest{Namespace: "small"})
+		if err != nil {
+			t.Fatalf("Usage failed: %v", err)
+		}
+
+		if resp.KeyCount != 2 || resp.Bytes != 4 || resp.MaxKeys != 2 || resp.MaxBytes != 30 || resp.MaxValueBytes != 16 {
+			t.Errorf("Unexpected usage %v", resp)
+		}
+
+		_, err = client.Usage(ctx, &pb.UsageRequest{Namespace: "missing"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+}
+
+func TestLoadQuotas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotas.json")
+	os.WriteFile(path, []byte(`{
+		"default": {"max_keys": 1000, "ops_per_second": 50},
+		"namespaces": {"": {}, "bulk": {"max_bytes": 1048576}}
+	}`), 0o644)
+
+	quotas, err := server.LoadQuotas(path)
+	if err != nil {
+		t.Fatalf("LoadQuotas failed: %v", err)
+	}
+
+	if q := quotas.For("team-a"); q.MaxKeys != 1000 || q.OpsPerSecond != 50 {
+		t.Errorf("Expected the default quota, got %+v", q)
+	}
+	if q := quotas.For(""); q != (server.Quota{}) {
+		t.Errorf("Expected the default namespace to be unlimited, got %+v", q)
+	}
+	if q := quotas.For("bulk"); q.MaxBytes != 1<<20 || q.MaxKeys != 0 {
+		t.Errorf("Expected the bulk quota, got %+v", q)
+	}
+
+	os.WriteFile(path, []byte(`{"default": {"max_keys": -1}}`), 0o644)
+	if _, err := server.LoadQuotas(path); err == nil {
+		t.Error("Expected negative quotas to be rejected")
+	}
+}
@@ -0,0 +1,539 @@
+package test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	"GRPC-KV-Store-System/schemas/certs"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// clusterNode is one kvStore node of an in-process cluster. Raft traffic
+// goes over in-memory transports; gRPC over loopback, so followers can
+// forward writes to the leader by its ID.
+type clusterNode struct {
+	addr       string
+	store      *store.ReplicatedStore
+	transport  *raft.InmemTransport
+	grpcServer *grpc.Server
+	client     pb.KeyValueStoreClient
+	stopped    bool
+}
+
+type testCluster struct {
+	t     *testing.T
+	nodes []*clusterNode
+}
+
+func raftTestConfig() *raft.Config {
+	conf := raft.DefaultConfig()
+	conf.HeartbeatTimeout = 100 * time.Millisecond
+	conf.ElectionTimeout = 100 * time.Millisecond
+	conf.LeaderLeaseTimeout = 50 * time.Millisecond
+	conf.CommitTimeout = 5 * time.Millisecond
+	conf.TrailingLogs = 10
+	conf.LogOutput = io.Discard
+	conf.LogLevel = "ERROR"
+
+	return conf
+}
+
+func startCluster(t *testing.T, size int) *testCluster {
+	t.Helper()
+
+	c := &testCluster{t: t}
+
+	listeners := make([]net.Listener, size)
+	var peers []raft.Server
+	for n := range size {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		listeners[n] = lis
+
+		addr := lis.Addr().String()
+		_, transport := raft.NewInmemTransport(raft.ServerAddress(addr))
+		c.nodes = append(c.nodes, &clusterNode{addr: addr, transport: transport})
+		peers = append(peers, raft.Server{ID: raft.ServerID(addr), Address: raft.ServerAddress(addr)})
+	}
+
+	for _, a := range c.nodes {
+		for _, b := range c.nodes {
+			if a != b {
+				a.transport.Connect(b.transport.LocalAddr(), b.transport)
+			}
+		}
+	}
+
+	forwarder := server.StartForwarder(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	t.Cleanup(func() { forwarder.Close() })
+
+	for n, node := range c.nodes {
+		kvStore, err := store.OpenReplicatedStore(store.ReplicationOptions{
+			ID:            node.addr,
+			Transport:     node.transport,
+			Peers:         peers,
+			Config:        raftTestConfig(),
+			SweepInterval: 50 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatalf("Failed to open node %s: %v", node.addr, err)
+		}
+		node.store = kvStore
+
+		node.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(forwarder.UnaryInterceptor))
+		pb.RegisterKeyValueStoreServer(node.grpcServer, server.StartServer(kvStore))
+		go node.grpcServer.Serve(listeners[n])
+
+		conn, err := grpc.NewClient(node.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		node.client = pb.NewKeyValueStoreClient(conn)
+	}
+
+	t.Cleanup(func() {
+		for _, node := range c.nodes {
+			c.stop(node)
+		}
+	})
+
+	return c
+}
+
+// stop shuts node down and cuts it off from the rest of the cluster.
+func (c *testCluster) stop(node *clusterNode) {
+	if node.stopped {
+		return
+	}
+	node.stopped = true
+
+	for _, other := range c.nodes {
+		other.transport.Disconnect(node.transport.LocalAddr())
+	}
+
+	node.grpcServer.Stop()
+	node.store.Close()
+}
+
+// leader waits for the running nodes to agree on a leader that accepts
+// writes.
+func (c *testCluster) leader() *clusterNode {
+	c.t.Helper()
+
+	var leader *clusterNode
+	c.waitFor("a leader", func() bool {
+		leader = nil
+		for _, node := range c.nodes {
+			if node.stopped {
+				continue
+			}
+			if node.store.IsLeader() {
+				leader = node
+			}
+		}
+
+		if leader == nil {
+			return false
+		}
+
+		for _, node := range c.nodes {
+			if !node.stopped && node.store.Leader() != leader.addr {
+				return false
+			}
+		}
+		return true
+	})
+
+	return leader
+}
+
+func (c *testCluster) followers() []*clusterNode {
+	leader := c.leader()
+
+	var followers []*clusterNode
+	for _, node := range c.nodes {
+		if node != leader && !node.stopped {
+			followers = append(followers, node)
+		}
+	}
+
+	return followers
+}
+
+// converged waits for every running node to hold key at value and version.
+func (c *testCluster) converged(key, value string, version int64) {
+	c.t.Helper()
+
+	c.waitFor(fmt.Sprintf("%s=%s to replicate", key, value), func() bool {
+		for _, node := range c.nodes {
+			if node.stopped {
+				continue
+			}
+
+			got, gotVersion, err := node.store.GetWithVersion(key)
+			if err != nil || got != value || gotVersion != version {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (c *testCluster) waitFor(what string, cond func() bool) {
+	c.t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			c.t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplicatedCluster(t *testing.T) {
+	cluster := startCluster(t, 3)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	t.Run("Writes on the leader reach every node", func(t *testing.T) {
+		leader := cluster.leader()
+
+		version, err := leader.store.Set("user:1", "alice")
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		cluster.converged("user:1", "alice", version)
+
+		result, err := leader.store.Txn(store.Txn{
+			Compare: []store.Compare{{Key: "user:1", Target: store.CompareVersion, Result: store.Equal, Version: version}},
+			Success: []store.TxnOp{{Type: store.TxnPut, Key: "user:1", Value: "alice2"}},
+		})
+		if err != nil || !result.Succeeded {
+			t.Fatalf("Txn failed: %v %+v", err, result)
+		}
+
+		cluster.converged("user:1", "alice2", result.Revision)
+	})
+
+	t.Run("Counters replicate", func(t *testing.T) {
+		leader := cluster.leader()
+
+		leader.store.Increment("hits", 2, store.CounterOptions{})
+		value, version, err := leader.store.Increment("hits", 3, store.CounterOptions{})
+		if err != nil || value != 5 {
+			t.Fatalf("Expected 5, got %d (%v)", value, err)
+		}
+
+		cluster.converged("hits", "5", version)
+	})
+
+	t.Run("Binary values replicate intact", func(t *testing.T) {
+		value := string([]byte{0x00, 0xff, 0xfe, 0x80})
+
+		version, err := cluster.leader().store.Set("blob", value)
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		cluster.converged("blob", value, version)
+	})
+
+	t.Run("Expired keys are removed on every node through the log", func(t *testing.T) {
+		leader := cluster.leader()
+
+		if _, err := leader.store.SetWithTTL("session", "s1", 200*time.Millisecond); err != nil {
+			t.Fatalf("SetWithTTL failed: %v", err)
+		}
+		time.Sleep(200 * time.Millisecond)
+
+		for _, node := range cluster.nodes {
+			if _, err := node.store.Get("session"); !errors.Is(err, store.ErrKeyNotFound) {
+				t.Errorf("Expected the key to be gone on %s, got %v", node.addr, err)
+			}
+		}
+
+		cluster.waitFor("every node to remove the key", func() bool {
+			for _, node := range cluster.nodes {
+				if node.store.MemoryStats().Expirations != 1 {
+					return false
+				}
+			}
+			return true
+		})
+	})
+
+	t.Run("Followers turn writes down naming the leader", func(t *testing.T) {
+		leader := cluster.leader()
+		follower := cluster.followers()[0]
+
+		_, err := follower.store.Set("user:2", "bob")
+
+		var notLeader *store.NotLeaderError
+		if !errors.As(err, &notLeader) || notLeader.Leader != leader.addr {
+			t.Fatalf("Expected NotLeaderError naming %s, got %v", leader.addr, err)
+		}
+
+		if _, err := follower.store.CompareAndSwap("user:1", store.Condition{Version: 999}, "x", 0); !errors.Is(err, store.ErrNotLeader) {
+			t.Errorf("Expected ErrNotLeader before the compare is checked, got %v", err)
+		}
+	})
+
+	t.Run("Followers without forwarding answer with the leader", func(t *testing.T) {
+		leader := cluster.leader()
+		follower := cluster.followers()[0]
+
+		client := dialInProcess(t, server.StartServer(follower.store))
+		_, err := client.Set(ctx, &pb.SetRequest{Key: "user:2", Value: "bob"})
+
+		if status.Code(err) != codes.Unavailable || server.LeaderFromError(err) != leader.addr {
+			t.Errorf("Expected Unavailable naming %s, got %v", leader.addr, err)
+		}
+	})
+
+	t.Run("Followers forward writes to the leader", func(t *testing.T) {
+		follower := cluster.followers()[0]
+
+		resp, err := follower.client.Set(ctx, &pb.SetRequest{Key: "user:2", Value: "bob"})
+		if err != nil {
+			t.Fatalf("Set through a follower failed: %v", err)
+		}
+
+		cluster.converged("user:2", "bob", resp.Version)
+
+		if _, err := follower.client.Delete(ctx, &pb.DeleteRequest{Key: "missing"}); status.Code(err) != codes.NotFound {
+			t.Errorf("Expected the leader's NotFound, got %v", err)
+		}
+	})
+
+	t.Run("Watches on followers see replicated writes", func(t *testing.T) {
+		follower := cluster.followers()[0]
+
+		watch, err := follower.store.Watch(store.WatchOptions{Key: "config/", Prefix: true})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+		defer watch.Close()
+
+		version, err := cluster.leader().store.Set("config/mode", "ha")
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		if ev := nextEvent(t, watch); ev.Key != "config/mode" || ev.Value != "ha" || ev.Revision != version {
+			t.Errorf("Expected config/mode=ha at %d, got %+v", version, ev)
+		}
+	})
+
+	t.Run("A lagging follower catches up from a snapshot", func(t *testing.T) {
+		leader := cluster.leader()
+		lagging := cluster.followers()[0]
+
+		for _, node := range cluster.nodes {
+			if node != lagging {
+				node.transport.Disconnect(lagging.transport.LocalAddr())
+				lagging.transport.Disconnect(node.transport.LocalAddr())
+			}
+		}
+
+		var version int64
+		for n := range 100 {
+			v, err := leader.store.Set(fmt.Sprintf("bulk:%03d", n), fmt.Sprint(n))
+			if err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			version = v
+		}
+
+		// Snapshotting compacts the log down to the last few entries, so
+		// the lagging follower can only catch up from the snapshot.
+		if _, err := leader.store.Snapshot(); err != nil {
+			t.Fatalf("Snapshot failed: %v", err)
+		}
+
+		for _, node := range cluster.nodes {
+			if node != lagging {
+				node.transport.Connect(lagging.transport.LocalAddr(), lagging.transport)
+				lagging.transport.Connect(node.transport.LocalAddr(), node.transport)
+			}
+		}
+
+		cluster.converged("bulk:099", "99", version)
+		cluster.converged("user:2", "bob", mustVersion(t, cluster.leader().store, "user:2"))
+	})
+
+	t.Run("A new leader takes over when the leader stops", func(t *testing.T) {
+		old := cluster.leader()
+		before := mustVersion(t, old.store, "user:1")
+
+		cluster.stop(old)
+
+		leader := cluster.leader()
+		if leader == old {
+			t.Fatal("Expected a different leader")
+		}
+
+		version, err := leader.store.Set("user:1", "carol")
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		if version <= before {
+			t.Errorf("Expected a version after %d, got %d", before, version)
+		}
+
+		cluster.converged("user:1", "carol", version)
+	})
+}
+
+func TestReplicatedStoreRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	open := func() *store.ReplicatedStore {
+		_, transport := raft.NewInmemTransport("")
+		kvStore, err := store.OpenReplicatedStore(store.ReplicationOptions{
+			ID:        "node-1",
+			Transport: transport,
+			Dir:       dir,
+			Config:    raftTestConfig(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to open store: %v", err)
+		}
+
+		deadline := time.Now().Add(10 * time.Second)
+		for !kvStore.IsLeader() {
+			if time.Now().After(deadline) {
+				t.Fatal("Timed out waiting for the node to lead")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		return kvStore
+	}
+
+	kvStore := open()
+	kvStore.Set("before", "snapshot")
+	if _, err := kvStore.Snapshot(); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	version, _ := kvStore.Set("after", "snapshot")
+	kvStore.Close()
+
+	kvStore = open()
+	defer kvStore.Close()
+
+	// Entries after the snapshot are only applied once the node commits
+	// in its new term, which the next write waits for.
+	next, err := kvStore.Set("next", "value")
+	if err != nil || next <= version {
+		t.Errorf("Expected a version after %d, got %d (%v)", version, next, err)
+	}
+
+	for _, key := range []string{"before", "after"} {
+		if _, err := kvStore.Get(key); err != nil {
+			t.Errorf("Expected %s to be recovered, got %v", key, err)
+		}
+	}
+}
+
+func mustVersion(t *testing.T, kvStore store.Store, key string) int64 {
+	t.Helper()
+
+	_, version, err := kvStore.GetWithVersion(key)
+	if err != nil {
+		t.Fatalf("Get %s failed: %v", key, err)
+	}
+
+	return version
+}
+
+func TestTLSTransport(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "raft-ca")
+	caFile := writeFile(t, dir, "ca.pem", ca.pem)
+	certFile, keyFile := ca.issue(t, dir, "node", x509.ExtKeyUsageAny)
+
+	open := func(opts certs.Options) *raft.NetworkTransport {
+		opts.RequireClientCert = true
+		serverConfig, err := certs.ServerConfig(opts)
+		if err != nil {
+			t.Fatalf("ServerConfig failed: %v", err)
+		}
+		clientConfig, err := certs.ClientConfig(opts)
+		if err != nil {
+			t.Fatalf("ClientConfig failed: %v", err)
+		}
+
+		transport, err := store.OpenTLSTransport("127.0.0.1:0", nil, serverConfig, clientConfig, io.Discard)
+		if err != nil {
+			t.Fatalf("OpenTLSTransport failed: %v", err)
+		}
+		t.Cleanup(func() { transport.Close() })
+		return transport
+	}
+
+	opts := certs.Options{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}
+	a, b := open(opts), open(opts)
+
+	go func() {
+		for rpc := range b.Consumer() {
+			rpc.Respond(&raft.AppendEntriesResponse{Success: true}, nil)
+		}
+	}()
+
+	t.Run("Nodes with a certificate from the CA talk", func(t *testing.T) {
+		var resp raft.AppendEntriesResponse
+		if err := a.AppendEntries("b", b.LocalAddr(), &raft.AppendEntriesRequest{Term: 1}, &resp); err != nil || !resp.Success {
+			t.Errorf("Expected the entries to be taken, got %+v (%v)", resp, err)
+		}
+	})
+
+	t.Run("Try appending entries without a certificate", func(t *testing.T) {
+		plain, err := raft.NewTCPTransport("127.0.0.1:0", nil, 1, time.Second, io.Discard)
+		if err != nil {
+			t.Fatalf("NewTCPTransport failed: %v", err)
+		}
+		defer plain.Close()
+
+		var resp raft.AppendEntriesResponse
+		if err := plain.AppendEntries("b", b.LocalAddr(), &raft.AppendEntriesRequest{Term: 1}, &resp); err == nil {
+			t.Error("Expected a peer in plaintext to be turned down")
+		}
+
+		config, err := certs.ClientConfig(certs.Options{CAFile: caFile})
+		if err != nil {
+			t.Fatalf("ClientConfig failed: %v", err)
+		}
+		conn, err := tls.Dial("tcp", string(b.LocalAddr()), config)
+		if err == nil {
+			defer conn.Close()
+			// Under TLS 1.3 the server checks the certificate after the
+			// client has finished its side of the handshake.
+			conn.Write([]byte{0})
+			_, err = conn.Read(make([]byte, 1))
+		}
+		if err == nil {
+			t.Error("Expected a peer without a certificate to be turned down")
+		}
+	})
+}
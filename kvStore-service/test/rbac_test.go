@@ -0,0 +1,336 @@
+package test
+
+import (
+	"context"
+	"crypto/x509"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	"GRPC-KV-Store-System/schemas/certs"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+	"GRPC-KV-Store-System/schemas/rbac"
+)
+
+const testPolicy = `{
+	"roles": {
+		"config-reader": [
+			{"prefix": "config:*", "verbs": ["read"]},
+			{"prefix": "secrets:", "verbs": ["*"], "deny": true}
+		],
+		"app": [
+			{"prefix": "app:", "verbs": ["read", "write", "delete"]},
+			{"prefix": "", "verbs": ["read"]}
+		],
+		"team-a": [{"namespace": "team-a", "prefix": "", "verbs": ["read", "write"]}],
+		"admin": [{"namespace": "*", "prefix": "", "verbs": ["*"]}]
+	},
+	"bindings": {
+		"billing": ["config-reader", "app"],
+		"alice": ["team-a"],
+		"ops": ["admin"],
+		"*": ["config-reader"]
+	}
+}`
+
+func writePolicy(t *testing.T, policy string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(policy), 0o644); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+	return path
+}
+
+func TestPolicy(t *testing.T) {
+	policy, err := rbac.LoadPolicy(writePolicy(t, testPolicy))
+	if err != nil {
+		t.Fatalf("LoadPolicy failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		principal string
+		access    rbac.Access
+		allowed   bool
+		reason    string
+	}{
+		{"billing", rbac.OnKey("", rbac.Read, "config:db"), true, `role "app" allows read`},
+		{"billing", rbac.OnKey("", rbac.Write, "config:db"), false, `no role of principal "billing" (app, config-reader) allows write key "config:db"`},
+		{"billing", rbac.OnKey("", rbac.Write, "app:counter"), true, ""},
+		{"billing", rbac.OnKey("", rbac.Read, "secrets:token"), false, `role "config-reader" denies read on keys starting with "secrets:"`},
+		{"billing", rbac.OnPrefix("", rbac.Read, "app:"), true, ""},
+		{"billing", rbac.OnPrefix("", rbac.Read, ""), false, `denies read`},
+		{"billing", rbac.OnPrefix("", rbac.Read, "secrets:old:"), false, `denies read`},
+		{"billing", rbac.Access{Verb: rbac.Admin}, false, "allows admin"},
+		{"ops", rbac.OnKey("", rbac.Delete, "config:db"), true, `role "admin" allows delete on every key`},
+		{"ops", rbac.Access{Verb: rbac.Admin}, true, ""},
+		{"", rbac.OnKey("", rbac.Read, "config:db"), true, ""},
+		{"", rbac.OnKey("", rbac.Read, "app:x"), false, "principal without credentials (config-reader)"},
+		{"billing", rbac.OnKey("team-a", rbac.Read, "config:db"), false, `allows read key "config:db" of namespace "team-a"`},
+		{"alice", rbac.OnKey("team-a", rbac.Write, "config:db"), true, `role "team-a" allows write on every key of namespace "team-a"`},
+		{"alice", rbac.OnKey("", rbac.Read, "config:db"), true, `role "config-reader"`},
+		{"alice", rbac.OnKey("", rbac.Write, "config:db"), false, ""},
+		{"alice", rbac.Access{Namespace: "team-a", Verb: rbac.Admin}, false, ""},
+		{"ops", rbac.OnKey("team-a", rbac.Delete, "config:db"), true, "every namespace"},
+		{"ops", rbac.Access{Namespace: "team-a", Verb: rbac.Admin}, true, ""},
+	} {
+		decision := policy.Check(tc.principal, tc.access)
+		if decision.Allowed != tc.allowed || !strings.Contains(decision.Reason, tc.reason) {
+			t.Errorf("%q %v: expected allowed=%t with reason containing %q, got %+v", tc.principal, tc.access, tc.allowed, tc.reason, decision)
+		}
+	}
+
+	t.Run("Try loading invalid policies", func(t *testing.T) {
+		for name, policy := range map[string]string{
+			"unknown verb": `{"roles": {"r": [{"prefix": "a", "verbs": ["grant"]}]}}`,
+			"no verbs":     `{"roles": {"r": [{"prefix": "a"}]}}`,
+			"unknown role": `{"roles": {}, "bindings": {"svc": ["r"]}}`,
+			"not json":     `roles: []`,
+		} {
+			if _, err := rbac.LoadPolicy(writePolicy(t, policy)); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
+
+func TestEnforcer(t *testing.T) {
+	path := writePolicy(t, testPolicy)
+
+	enforcer, err := rbac.LoadEnforcer(path, false)
+	if err != nil {
+		t.Fatalf("LoadEnforcer failed: %v", err)
+	}
+
+	err = enforcer.Authorize("billing", rbac.OnKey("", rbac.Read, "config:db"), rbac.OnKey("", rbac.Write, "config:db"))
+	if denied, ok := err.(*rbac.DeniedError); !ok || denied.Access.Verb != rbac.Write {
+		t.Errorf("Expected the write to be denied, got %v", err)
+	}
+
+	t.Run("Reload", func(t *testing.T) {
+		os.WriteFile(path, []byte(`{"roles": {"all": [{"prefix": "", "verbs": ["*"]}]}, "bindings": {"billing": ["all"]}}`), 0o644)
+		if err := enforcer.Reload(); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+		if err := enforcer.Authorize("billing", rbac.OnKey("", rbac.Write, "config:db")); err != nil {
+			t.Errorf("Expected the new policy to allow the write, got %v", err)
+		}
+
+		os.WriteFile(path, []byte(`{"bindings": {"billing": ["missing"]}}`), 0o644)
+		if err := enforcer.Reload(); err == nil {
+			t.Error("Expected an invalid policy to fail to reload")
+		}
+		if err := enforcer.Authorize("billing", rbac.OnKey("", rbac.Write, "config:db")); err != nil {
+			t.Errorf("Expected the last valid policy to stay in place, got %v", err)
+		}
+	})
+
+	t.Run("Dry run allows what it would deny", func(t *testing.T) {
+		dryRun, err := rbac.LoadEnforcer(writePolicy(t, testPolicy), true)
+		if err != nil {
+			t.Fatalf("LoadEnforcer failed: %v", err)
+		}
+
+		if err := dryRun.Authorize("billing", rbac.OnKey("", rbac.Read, "secrets:token")); err != nil {
+			t.Errorf("Expected a dry run to allow the read, got %v", err)
+		}
+		if decision := dryRun.Explain("billing", rbac.OnKey("", rbac.Read, "secrets:token")); decision.Allowed {
+			t.Error("Expected Explain to tell the read would be denied")
+		}
+	})
+}
+
+func TestAuthorizer(t *testing.T) {
+	enforcer, err := rbac.LoadEnforcer(writePolicy(t, testPolicy), false)
+	if err != nil {
+		t.Fatalf("LoadEnforcer failed: %v", err)
+	}
+
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+	kvStore.Set("config:db", "postgres://")
+	kvStore.Set("secrets:token", "hunter2")
+
+	authorizer := server.StartAuthorizer(enforcer, []string{"api-service"})
+	dial := dialWithCertificates(t, server.StartServer(kvStore),
+		grpc.UnaryInterceptor(authorizer.UnaryInterceptor),
+		grpc.StreamInterceptor(authorizer.StreamInterceptor))
+
+	// The REST API is a trusted proxy, which names the principal.
+	client := dial("api-service")
+
+	as := func(principal string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), rbac.PrincipalHeader, principal)
+	}
+	billing := as("billing")
+
+	t.Run("Allowed requests go through", func(t *testing.T) {
+		if resp, err := client.Get(billing, &pb.GetRequest{Key: "config:db"}); err != nil || resp.Value != "postgres://" {
+			t.Errorf("Unexpected response %v (%v)", resp, err)
+		}
+		if _, err := client.Set(billing, &pb.SetRequest{Key: "app:a", Value: "1"}); err != nil {
+			t.Errorf("Set failed: %v", err)
+		}
+		if _, err := client.Stats(as("ops"), &pb.StatsRequest{}); err != nil {
+			t.Errorf("Stats failed: %v", err)
+		}
+	})
+
+	t.Run("Try requests the policy denies", func(t *testing.T) {
+		_, err := client.Set(billing, &pb.SetRequest{Key: "config:db", Value: "mysql://"})
+		if status.Code(err) != codes.PermissionDenied || !strings.Contains(err.Error(), "allows write") {
+			t.Errorf("Expected PermissionDenied explaining why, got %v", err)
+		}
+
+		if _, err := client.Get(context.Background(), &pb.GetRequest{Key: "app:a"}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected requests without a principal to get only the bindings of everyone, got %v", err)
+		}
+
+		if _, err := client.Scan(billing, &pb.ScanRequest{Start: "a", End: "z"}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected a range over secrets: to be denied, got %v", err)
+		}
+
+		_, err = client.Txn(billing, &pb.TxnRequest{
+			Success: []*pb.TxnOp{{Type: pb.TxnOp_PUT, Key: "app:a", Value: "2"}},
+			Failure: []*pb.TxnOp{{Type: pb.TxnOp_GET, Key: "secrets:token"}},
+		})
+		if status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected a transaction reading secrets: on failure to be denied, got %v", err)
+		}
+
+		if _, err := client.CreateNamespace(billing, &pb.CreateNamespaceRequest{Name: "team"}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected creating a namespace to need admin, got %v", err)
+		}
+	})
+
+	t.Run("Grants are on their own namespace", func(t *testing.T) {
+		if _, err := client.CreateNamespace(as("ops"), &pb.CreateNamespaceRequest{Name: "team-a"}); err != nil {
+			t.Fatalf("CreateNamespace failed: %v", err)
+		}
+
+		if _, err := client.Set(as("alice"), &pb.SetRequest{Namespace: "team-a", Key: "config:db", Value: "sqlite://"}); err != nil {
+			t.Errorf("Expected alice to write in team-a, got %v", err)
+		}
+		if _, err := client.Set(as("alice"), &pb.SetRequest{Key: "config:db", Value: "sqlite://"}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected alice's grant to stay in team-a, got %v", err)
+		}
+		if _, err := client.Get(billing, &pb.GetRequest{Namespace: "team-a", Key: "config:db"}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected billing's grant on config: to stay in the default namespace, got %v", err)
+		}
+		if _, err := client.Usage(as("alice"), &pb.UsageRequest{Namespace: "team-a"}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected usage to need admin, got %v", err)
+		}
+	})
+
+	t.Run("Try streaming keys the policy denies", func(t *testing.T) {
+		stream, err := client.ScanStream(billing, &pb.ScanRequest{Prefix: "secrets:"})
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected PermissionDenied, got %v", err)
+		}
+
+		load, err := client.BulkLoad(billing)
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+		load.Send(&pb.SetRequest{Key: "app:b", Value: "1"})
+		load.Send(&pb.SetRequest{Key: "config:b", Value: "1"})
+
+		if _, err := load.CloseAndRecv(); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected PermissionDenied, got %v", err)
+		}
+	})
+
+	t.Run("Scans within an allowed prefix go through", func(t *testing.T) {
+		stream, err := client.ScanStream(billing, &pb.ScanRequest{Prefix: "config:"})
+		if err != nil {
+			t.Fatalf("Failed to open stream: %v", err)
+		}
+
+		var keys []string
+		for {
+			kv, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Recv failed: %v", err)
+			}
+			keys = append(keys, kv.Key)
+		}
+		if len(keys) != 1 || keys[0] != "config:db" {
+			t.Errorf("Expected config:db, got %v", keys)
+		}
+	})
+
+	t.Run("Other callers are the principal of their certificate", func(t *testing.T) {
+		direct := dial("billing")
+
+		if _, err := direct.Set(context.Background(), &pb.SetRequest{Key: "app:c", Value: "1"}); err != nil {
+			t.Errorf("Expected billing's certificate to allow the write, got %v", err)
+		}
+
+		if _, err := direct.Stats(as("ops"), &pb.StatsRequest{}); status.Code(err) != codes.PermissionDenied || !strings.Contains(err.Error(), `"billing"`) {
+			t.Errorf("Expected the principal named by an untrusted caller to be ignored, got %v", err)
+		}
+	})
+}
+
+// dialWithCertificates serves kvServer over an in-memory listener with
+// mutual TLS, and returns a function connecting to it with a client
+// certificate named name. Everything is stopped when the test ends.
+func dialWithCertificates(t *testing.T, kvServer *server.Server, opts ...grpc.ServerOption) func(name string) pb.KeyValueStoreClient {
+	t.Helper()
+
+	dir := t.TempDir()
+	ca := newTestCA(t, "kv-test-ca")
+	caFile := writeFile(t, dir, "ca.crt", ca.pem)
+	serverCert, serverKey := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+
+	serverConfig, err := certs.ServerConfig(certs.Options{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile, RequireClientCert: true})
+	if err != nil {
+		t.Fatalf("ServerConfig failed: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(append(opts, grpc.Creds(credentials.NewTLS(serverConfig)))...)
+	pb.RegisterKeyValueStoreServer(grpcServer, kvServer)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	return func(name string) pb.KeyValueStoreClient {
+		certFile, keyFile := ca.issue(t, dir, name, x509.ExtKeyUsageClientAuth)
+		config, err := certs.ClientConfig(certs.Options{CertFile: certFile, KeyFile: keyFile, CAFile: caFile})
+		if err != nil {
+			t.Fatalf("ClientConfig failed: %v", err)
+		}
+		config.ServerName = "localhost"
+
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(credentials.NewTLS(config)))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+
+		return pb.NewKeyValueStoreClient(conn)
+	}
+}
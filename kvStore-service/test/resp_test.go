@@ -0,0 +1,409 @@
+package test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/resp"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+)
+
+// respError is an error reply, kept apart from simple strings so tests can
+// tell them apart.
+type respError string
+
+// respClient is a minimal Redis protocol client. Replies are decoded as
+// string (simple and bulk strings), respError, int64, nil, []any and
+// map[string]any.
+type respClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func startRESP(t *testing.T) (*respClient, store.Store) {
+	t.Helper()
+
+	kvStore := store.CreateStore()
+	t.Cleanup(func() { kvStore.Close() })
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	respServer := resp.StartServer(kvStore)
+	go respServer.Serve(lis)
+	t.Cleanup(func() { respServer.Close() })
+
+	return dialRESP(t, lis.Addr().String()), kvStore
+}
+
+func dialRESP(t *testing.T, addr string) *respClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	return &respClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *respClient) send(args ...string) {
+	c.t.Helper()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		c.t.Fatalf("Failed to send %v: %v", args, err)
+	}
+}
+
+func (c *respClient) read() any {
+	c.t.Helper()
+
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("Failed to read reply: %v", err)
+	}
+	kind, line := line[0], strings.TrimSuffix(line[1:], "\r\n")
+
+	switch kind {
+	case '+':
+		return line
+	case '-':
+		return respError(line)
+	case ':':
+		n, _ := strconv.ParseInt(line, 10, 64)
+		return n
+	case '_':
+		return nil
+	case '$':
+		n, _ := strconv.Atoi(line)
+		if n < 0 {
+			return nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			c.t.Fatalf("Failed to read bulk string: %v", err)
+		}
+		return string(buf[:n])
+	case '*':
+		n, _ := strconv.Atoi(line)
+		items := make([]any, n)
+		for i := range items {
+			items[i] = c.read()
+		}
+		return items
+	case '%':
+		n, _ := strconv.Atoi(line)
+		m := make(map[string]any, n)
+		for range n {
+			key := c.read().(string)
+			m[key] = c.read()
+		}
+		return m
+	}
+
+	c.t.Fatalf("Unexpected reply type %q", kind)
+	return nil
+}
+
+func (c *respClient) do(args ...string) any {
+	c.t.Helper()
+
+	c.send(args...)
+	return c.read()
+}
+
+// expect runs a command and checks its reply. An error reply is checked
+// by its prefix.
+func (c *respClient) expect(want any, args ...string) {
+	c.t.Helper()
+
+	got := c.do(args...)
+	if wantErr, ok := want.(respError); ok {
+		if gotErr, ok := got.(respError); !ok || !strings.HasPrefix(string(gotErr), string(wantErr)) {
+			c.t.Errorf("%v: expected error %q, got %#v", args, wantErr, got)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		c.t.Errorf("%v: expected %#v, got %#v", args, want, got)
+	}
+}
+
+func TestRESP(t *testing.T) {
+	client, kvStore := startRESP(t)
+
+	t.Run("Ping", func(t *testing.T) {
+		client.expect("PONG", "PING")
+		client.expect("hello", "PING", "hello")
+	})
+
+	t.Run("Set and get a key", func(t *testing.T) {
+		client.expect("OK", "SET", "user:1", "alice")
+		client.expect("alice", "GET", "user:1")
+		client.expect(nil, "GET", "missing")
+
+		if value, _ := kvStore.Get("user:1"); value != "alice" {
+			t.Errorf("Expected the store to hold 'alice', got '%s'", value)
+		}
+	})
+
+	t.Run("Values are binary safe", func(t *testing.T) {
+		value := "\x00\xff\r\n$3\r\n"
+		client.expect("OK", "SET", "blob", value)
+		client.expect(value, "GET", "blob")
+	})
+
+	t.Run("Set options", func(t *testing.T) {
+		client.expect(nil, "SET", "user:1", "bob", "NX")
+		client.expect("OK", "SET", "user:2", "bob", "NX")
+		client.expect(nil, "SET", "user:3", "carol", "XX")
+		client.expect("alice", "SET", "user:1", "alicia", "XX", "GET")
+		client.expect("alicia", "GET", "user:1")
+
+		client.expect("OK", "SET", "session", "token", "EX", "100")
+		client.expect(int64(100), "TTL", "session")
+		client.expect("OK", "SET", "session", "token2", "KEEPTTL")
+		client.expect(int64(100), "TTL", "session")
+
+		client.expect(respError("ERR syntax error"), "SET", "user:1", "x", "NX", "XX")
+		client.expect(respError("ERR invalid expire time"), "SET", "user:1", "x", "EX", "0")
+	})
+
+	t.Run("Expire and ttl", func(t *testing.T) {
+		client.expect(int64(-1), "TTL", "user:1")
+		client.expect(int64(-2), "TTL", "missing")
+
+		client.expect(int64(1), "EXPIRE", "user:1", "60")
+		client.expect(int64(60), "TTL", "user:1")
+		client.expect("alicia", "GET", "user:1")
+		client.expect(int64(0), "EXPIRE", "missing", "60")
+
+		client.expect(int64(1), "EXPIRE", "user:1", "0")
+		client.expect(nil, "GET", "user:1")
+	})
+
+	t.Run("Delete and exists", func(t *testing.T) {
+		client.expect("OK", "SET", "a", "1")
+		client.expect("OK", "SET", "b", "2")
+
+		client.expect(int64(3), "EXISTS", "a", "b", "a", "missing")
+		client.expect(int64(2), "DEL", "a", "b", "missing")
+		client.expect(int64(0), "EXISTS", "a", "b")
+	})
+
+	t.Run("Counters", func(t *testing.T) {
+		client.expect(int64(1), "INCR", "hits")
+		client.expect(int64(11), "INCRBY", "hits", "10")
+		client.expect(int64(10), "DECR", "hits")
+		client.expect(int64(5), "DECRBY", "hits", "5")
+		client.expect("5", "GET", "hits")
+
+		client.expect(respError("ERR value is not an integer"), "INCR", "user:2")
+		client.expect("OK", "SET", "big", "9223372036854775807")
+		client.expect(respError("ERR increment or decrement would overflow"), "INCR", "big")
+	})
+
+	t.Run("Mset and mget", func(t *testing.T) {
+		client.expect("OK", "MSET", "m:1", "one", "m:2", "two")
+		client.expect([]any{"one", nil, "two"}, "MGET", "m:1", "m:missing", "m:2")
+		client.expect(respError("ERR wrong number of arguments for 'mset'"), "MSET", "m:1", "one", "m:2")
+
+		if _, v1, _ := kvStore.GetWithVersion("m:1"); v1 != mustVersion(t, kvStore, "m:2") {
+			t.Errorf("Expected MSET to write its keys at one revision")
+		}
+	})
+
+	t.Run("Keys by glob pattern", func(t *testing.T) {
+		for _, key := range []string{"k:apple", "k:apricot", "k:banana", "k:a*b", "other"} {
+			client.expect("OK", "SET", key, "v")
+		}
+
+		client.expect([]any{"k:a*b", "k:apple", "k:apricot"}, "KEYS", "k:a*")
+		client.expect([]any{"k:apple", "k:apricot"}, "KEYS", "k:ap?[li]*")
+		client.expect([]any{"k:banana"}, "KEYS", "k:[^a]*")
+		client.expect([]any{"k:a*b"}, "KEYS", `k:a\*b`)
+		client.expect([]any{}, "KEYS", "nothing*")
+		client.expect([]any{"k:apricot"}, "KEYS", "*p*c*t")
+		client.expect([]any{"k:a*b"}, "KEYS", "*[*]?")
+	})
+
+	t.Run("Patterns with many stars match quickly", func(t *testing.T) {
+		long := strings.Repeat("a", 4096)
+		client.expect("OK", "SET", long, "v")
+		defer client.do("DEL", long)
+
+		client.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		defer client.conn.SetReadDeadline(time.Time{})
+
+		client.expect([]any{}, "KEYS", "*a*a*a*a*a*a*a*b")
+		client.expect([]any{long}, "KEYS", "*a*a*a*a*a*a*a*a")
+	})
+
+	t.Run("Scan the keyspace with a cursor", func(t *testing.T) {
+		var keys []string
+		cursor := "0"
+		for {
+			reply := client.do("SCAN", cursor, "MATCH", "k:*", "COUNT", "2").([]any)
+			for _, key := range reply[1].([]any) {
+				keys = append(keys, key.(string))
+			}
+
+			cursor = reply[0].(string)
+			if cursor == "0" {
+				break
+			}
+		}
+
+		if !slices.Equal(keys, []string{"k:a*b", "k:apple", "k:apricot", "k:banana"}) {
+			t.Errorf("Unexpected keys %v", keys)
+		}
+
+		client.expect(respError("ERR invalid cursor"), "SCAN", "123456")
+		client.expect([]any{"0", []any{}}, "SCAN", "0", "MATCH", "k:*", "TYPE", "hash")
+	})
+
+	t.Run("Pipelined commands", func(t *testing.T) {
+		client.send("SET", "p", "1")
+		client.send("INCR", "p")
+		client.send("GET", "p")
+
+		for _, want := range []any{"OK", int64(2), "2"} {
+			if got := client.read(); got != want {
+				t.Errorf("Expected %#v, got %#v", want, got)
+			}
+		}
+	})
+
+	t.Run("Inline commands", func(t *testing.T) {
+		io.WriteString(client.conn, "SET inline value\r\nGET inline\r\n")
+
+		if got := client.read(); got != "OK" {
+			t.Errorf("Expected OK, got %#v", got)
+		}
+		if got := client.read(); got != "value" {
+			t.Errorf("Expected value, got %#v", got)
+		}
+	})
+
+	t.Run("Try unknown commands and bad arguments", func(t *testing.T) {
+		client.expect(respError("ERR unknown command 'FLUSHALL'"), "FLUSHALL")
+		client.expect(respError("ERR wrong number of arguments for 'get'"), "GET")
+		client.expect(respError("ERR value is not an integer"), "EXPIRE", "p", "soon")
+	})
+}
+
+func TestRESP3(t *testing.T) {
+	client, _ := startRESP(t)
+
+	t.Run("Hello switches to RESP3", func(t *testing.T) {
+		reply, ok := client.do("HELLO", "3", "SETNAME", "tests").(map[string]any)
+		if !ok || reply["proto"] != int64(3) {
+			t.Fatalf("Unexpected HELLO reply %#v", reply)
+		}
+
+		client.expect("tests", "CLIENT", "GETNAME")
+	})
+
+	t.Run("Nulls are sent as RESP3 nulls", func(t *testing.T) {
+		client.send("GET", "missing")
+
+		line, err := client.r.ReadString('\n')
+		if err != nil || line != "_\r\n" {
+			t.Errorf("Expected a RESP3 null, got %q (%v)", line, err)
+		}
+	})
+
+	t.Run("Try an unsupported protocol version", func(t *testing.T) {
+		client.expect(respError("NOPROTO"), "HELLO", "4")
+	})
+
+	t.Run("Protocol errors close the connection", func(t *testing.T) {
+		io.WriteString(client.conn, "*1\r\n+PING\r\n")
+
+		if got, ok := client.read().(respError); !ok || !strings.HasPrefix(string(got), "ERR Protocol error") {
+			t.Errorf("Expected a protocol error, got %#v", got)
+		}
+		if _, err := client.r.ReadByte(); err != io.EOF {
+			t.Errorf("Expected the connection to be closed, got %v", err)
+		}
+	})
+}
+
+func TestRESPLimits(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	respServer := resp.StartServer(kvStore)
+	respServer.SetLimits(resp.Limits{ReadTimeout: 300 * time.Millisecond, MaxInflightBytes: 1 << 20})
+	go respServer.Serve(lis)
+	defer respServer.Close()
+
+	addr := lis.Addr().String()
+
+	t.Run("Lengths alone take up no memory", func(t *testing.T) {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		for range 4 {
+			io.WriteString(dialRESP(t, addr).conn, "*2\r\n$3\r\nGET\r\n$536870000\r\n")
+			io.WriteString(dialRESP(t, addr).conn, "*1048576\r\n")
+		}
+		dialRESP(t, addr).expect("PONG", "PING")
+
+		runtime.ReadMemStats(&after)
+		if grown := int64(after.HeapAlloc) - int64(before.HeapAlloc); grown > 64<<20 {
+			t.Errorf("Expected declared lengths not to be allocated, the heap grew by %d bytes", grown)
+		}
+	})
+
+	t.Run("Try sending more than the server holds in flight", func(t *testing.T) {
+		client := dialRESP(t, addr)
+
+		// Everything sent fits the budget, so the server has read it all
+		// when it turns the rest of the value down.
+		io.WriteString(client.conn, "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$2000000\r\n"+strings.Repeat("x", 1<<20))
+
+		if got, ok := client.read().(respError); !ok || !strings.Contains(string(got), "too much data in flight") {
+			t.Errorf("Expected the value to be turned down, got %#v", got)
+		}
+
+		// What the connection held is given back once it is closed.
+		dialRESP(t, addr).expect("OK", "SET", "k", strings.Repeat("y", 1<<19))
+	})
+
+	t.Run("Idle clients are disconnected", func(t *testing.T) {
+		client := dialRESP(t, addr)
+		client.expect("PONG", "PING")
+
+		if _, err := client.r.ReadByte(); err != io.EOF {
+			t.Errorf("Expected the connection to be closed, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+func countSegments(t *testing.T, dir string) int {
+	t.Helper()
+
+	segments, err := filepath.Glob(filepath.Join(dir, "wal-*.log"))
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+
+	return len(segments)
+}
+
+func TestSnapshotAndCompaction(t *testing.T) {
+	dir := t.TempDir()
+
+	kvStore := openDurableStore(t, dir)
+	for n := 0; n < 100; n++ {
+		kvStore.Set(fmt.Sprintf("key:%d", n), fmt.Sprintf("value:%d", n))
+	}
+	kvStore.Delete("key:0")
+	kvStore.SetWithTTL("session", "token", time.Hour)
+
+	info, err := kvStore.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	if info.Keys != 100 {
+		t.Errorf("Expected 100 keys in snapshot, got %d", info.Keys)
+	}
+
+	if stat, err := os.Stat(info.Path); err != nil || stat.Size() != info.Size {
+		t.Errorf("Expected snapshot of %d bytes at %s (%v)", info.Size, info.Path, err)
+	}
+
+	if n := countSegments(t, dir); n != 1 {
+		t.Errorf("Expected the log to be truncated to 1 segment, got %d", n)
+	}
+
+	// Writes after the snapshot land in the log tail.
+	kvStore.Set("key:1", "updated")
+	kvStore.Delete("key:2")
+	kvStore.Close()
+
+	kvStore = openDurableStore(t, dir)
+	defer kvStore.Close()
+
+	expected := map[string]string{
+		"key:1":  "updated",
+		"key:3":  "value:3",
+		"key:99": "value:99",
+	}
+	for key, want := range expected {
+		if got, err := kvStore.Get(key); err != nil || got != want {
+			t.Errorf("Get(%s) = '%s' (%v), want '%s'", key, got, err, want)
+		}
+	}
+
+	for _, key := range []string{"key:0", "key:2"} {
+		if _, err := kvStore.Get(key); err == nil {
+			t.Errorf("Expected %s to stay deleted", key)
+		}
+	}
+
+	if ttl, err := kvStore.TTL("session"); err != nil || ttl <= 0 {
+		t.Errorf("Expected ttl to survive a snapshot, got %v (%v)", ttl, err)
+	}
+}
+
+func TestCorruptSnapshotIsRejected(t *testing.T) {
+	dir := t.TempDir()
+
+	kvStore := openDurableStore(t, dir)
+	kvStore.Set("key", "value")
+	info, err := kvStore.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	kvStore.Close()
+
+	data, err := os.ReadFile(info.Path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	data[len(data)-6] ^= 0xff
+	if err := os.WriteFile(info.Path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if _, err := store.OpenDurableStore(dir, store.DurableOptions{}); err == nil {
+		t.Error("Expected a corrupt snapshot to be rejected")
+	}
+}
+
+func TestSnapshotRPC(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Snapshot a durable store", func(t *testing.T) {
+		kvStore := openDurableStore(t, t.TempDir())
+		defer kvStore.Close()
+
+		kvServer := server.StartServer(kvStore)
+		kvServer.Set(ctx, &pb.SetRequest{Key: "key", Value: "value"})
+
+		resp, err := kvServer.Snapshot(ctx, &pb.SnapshotRequest{})
+		if err != nil {
+			t.Fatalf("Snapshot failed: %v", err)
+		}
+
+		if resp.KeyCount != 1 || resp.SizeBytes == 0 || resp.Path == "" {
+			t.Errorf("Unexpected snapshot response: %v", resp)
+		}
+	})
+
+	t.Run("Snapshot an in-memory store", func(t *testing.T) {
+		kvServer := server.StartServer(store.CreateStore())
+
+		_, err := kvServer.Snapshot(ctx, &pb.SnapshotRequest{})
+		if st, _ := status.FromError(err); st.Code() != codes.FailedPrecondition {
+			t.Errorf("Expected FailedPrecondition, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,247 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+)
+
+func TestStoreExpiry(t *testing.T) {
+	kvStore := store.CreateExpiringStore(10 * time.Millisecond)
+	defer kvStore.Close()
+
+	t.Run("Key without ttl never expires", func(t *testing.T) {
+		if _, err := kvStore.Set("forever", "value"); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		ttl, err := kvStore.TTL("forever")
+		if err != nil {
+			t.Fatalf("TTL failed: %v", err)
+		}
+		if ttl != store.NoExpiry {
+			t.Errorf("Expected NoExpiry, got %v", ttl)
+		}
+	})
+
+	t.Run("Key expires lazily on Get", func(t *testing.T) {
+		lazy := store.CreateStore()
+		defer lazy.Close()
+
+		if _, err := lazy.SetWithTTL("session", "token", 20*time.Millisecond); err != nil {
+			t.Fatalf("SetWithTTL failed: %v", err)
+		}
+
+		if value, err := lazy.Get("session"); err != nil || value != "token" {
+			t.Fatalf("Expected 'token', got '%s' (%v)", value, err)
+		}
+
+		time.Sleep(30 * time.Millisecond)
+
+		if _, err := lazy.Get("session"); !errors.Is(err, store.ErrKeyNotFound) {
+			t.Errorf("Expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Key is removed by the sweeper", func(t *testing.T) {
+		if _, err := kvStore.SetWithTTL("swept", "value", 20*time.Millisecond); err != nil {
+			t.Fatalf("SetWithTTL failed: %v", err)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		if _, err := kvStore.TTL("swept"); !errors.Is(err, store.ErrKeyNotFound) {
+			t.Errorf("Expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Persist clears the expiry", func(t *testing.T) {
+		if _, err := kvStore.SetWithTTL("kept", "value", 20*time.Millisecond); err != nil {
+			t.Fatalf("SetWithTTL failed: %v", err)
+		}
+
+		if err := kvStore.Persist("kept"); err != nil {
+			t.Fatalf("Persist failed: %v", err)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		if value, err := kvStore.Get("kept"); err != nil || value != "value" {
+			t.Errorf("Expected 'value', got '%s' (%v)", value, err)
+		}
+	})
+
+	t.Run("Overwriting a key drops its ttl", func(t *testing.T) {
+		if _, err := kvStore.SetWithTTL("rewritten", "old", 20*time.Millisecond); err != nil {
+			t.Fatalf("SetWithTTL failed: %v", err)
+		}
+
+		if _, err := kvStore.Set("rewritten", "new"); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+
+		if value, err := kvStore.Get("rewritten"); err != nil || value != "new" {
+			t.Errorf("Expected 'new', got '%s' (%v)", value, err)
+		}
+	})
+
+	t.Run("Non-positive ttl is rejected", func(t *testing.T) {
+		if _, err := kvStore.SetWithTTL("bad", "value", 0); !errors.Is(err, store.ErrInvalidTTL) {
+			t.Errorf("Expected ErrInvalidTTL, got %v", err)
+		}
+	})
+}
+
+func TestStoreVersions(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	first, err := kvStore.Set("config", "v1")
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	second, err := kvStore.Set("config", "v2")
+	if err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if second <= first {
+		t.Errorf("Expected version to grow, got %d then %d", first, second)
+	}
+
+	t.Run("Get returns the current version", func(t *testing.T) {
+		value, version, err := kvStore.GetWithVersion("config")
+		if err != nil || value != "v2" || version != second {
+			t.Errorf("Expected 'v2' at %d, got '%s' at %d (%v)", second, value, version, err)
+		}
+	})
+
+	t.Run("Swap on a stale version fails", func(t *testing.T) {
+		_, err := kvStore.CompareAndSwap("config", store.Condition{Version: first}, "v3", 0)
+		if !errors.Is(err, store.ErrVersionMismatch) {
+			t.Errorf("Expected ErrVersionMismatch, got %v", err)
+		}
+	})
+
+	t.Run("Swap on the current version succeeds", func(t *testing.T) {
+		version, err := kvStore.CompareAndSwap("config", store.Condition{Version: second}, "v3", 0)
+		if err != nil {
+			t.Fatalf("CompareAndSwap failed: %v", err)
+		}
+		if version <= second {
+			t.Errorf("Expected version to grow past %d, got %d", second, version)
+		}
+	})
+
+	t.Run("Swap on the expected value", func(t *testing.T) {
+		stale := "v1"
+		if _, err := kvStore.CompareAndSwap("config", store.Condition{Value: &stale}, "v4", 0); !errors.Is(err, store.ErrVersionMismatch) {
+			t.Errorf("Expected ErrVersionMismatch, got %v", err)
+		}
+
+		current := "v3"
+		if _, err := kvStore.CompareAndSwap("config", store.Condition{Value: &current}, "v4", 0); err != nil {
+			t.Errorf("CompareAndSwap failed: %v", err)
+		}
+	})
+
+	t.Run("Version zero creates a missing key only", func(t *testing.T) {
+		if _, err := kvStore.CompareAndSwap("lock", store.Condition{}, "owner-a", 0); err != nil {
+			t.Fatalf("CompareAndSwap failed: %v", err)
+		}
+
+		if _, err := kvStore.CompareAndSwap("lock", store.Condition{}, "owner-b", 0); !errors.Is(err, store.ErrVersionMismatch) {
+			t.Errorf("Expected ErrVersionMismatch, got %v", err)
+		}
+	})
+
+	t.Run("Re-creating a deleted key never reuses a version", func(t *testing.T) {
+		_, before, _ := kvStore.GetWithVersion("lock")
+		kvStore.Delete("lock")
+
+		after, err := kvStore.Set("lock", "owner-b")
+		if err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if after <= before {
+			t.Errorf("Expected version to grow past %d, got %d", before, after)
+		}
+	})
+}
+
+func TestStoreScan(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	for _, key := range []string{"user:3", "user:1", "order:1", "user:2", "usher"} {
+		if _, err := kvStore.Set(key, "value-"+key); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	scanKeys := func(t *testing.T, opts store.ScanOptions) ([]string, bool) {
+		items, more, err := kvStore.Scan(opts)
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+
+		keys := make([]string, 0, len(items))
+		for _, item := range items {
+			keys = append(keys, item.Key)
+		}
+		return keys, more
+	}
+
+	t.Run("Scan everything in order", func(t *testing.T) {
+		keys, more := scanKeys(t, store.ScanOptions{})
+		if fmt.Sprint(keys) != "[order:1 user:1 user:2 user:3 usher]" || more {
+			t.Errorf("Unexpected scan result %v (more=%v)", keys, more)
+		}
+	})
+
+	t.Run("Scan by prefix", func(t *testing.T) {
+		keys, _ := scanKeys(t, store.ScanOptions{Prefix: "user:"})
+		if fmt.Sprint(keys) != "[user:1 user:2 user:3]" {
+			t.Errorf("Expected the user: keys, got %v", keys)
+		}
+	})
+
+	t.Run("Scan a range", func(t *testing.T) {
+		keys, _ := scanKeys(t, store.ScanOptions{Start: "user:2", End: "usher"})
+		if fmt.Sprint(keys) != "[user:2 user:3]" {
+			t.Errorf("Expected [user:2 user:3], got %v", keys)
+		}
+	})
+
+	t.Run("Limit reports more keys", func(t *testing.T) {
+		keys, more := scanKeys(t, store.ScanOptions{Prefix: "user:", Limit: 2})
+		if fmt.Sprint(keys) != "[user:1 user:2]" || !more {
+			t.Errorf("Unexpected scan result %v (more=%v)", keys, more)
+		}
+	})
+
+	t.Run("Deleted and expired keys are skipped", func(t *testing.T) {
+		kvStore.Delete("user:1")
+		if _, err := kvStore.SetWithTTL("user:2", "gone", time.Millisecond); err != nil {
+			t.Fatalf("SetWithTTL failed: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		keys, _ := scanKeys(t, store.ScanOptions{Prefix: "user:"})
+		if fmt.Sprint(keys) != "[user:3]" {
+			t.Errorf("Expected [user:3], got %v", keys)
+		}
+	})
+
+	t.Run("Negative limit is rejected", func(t *testing.T) {
+		if _, _, err := kvStore.Scan(store.ScanOptions{Limit: -1}); !errors.Is(err, store.ErrInvalidLimit) {
+			t.Errorf("Expected ErrInvalidLimit, got %v", err)
+		}
+	})
+}
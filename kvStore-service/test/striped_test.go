@@ -0,0 +1,123 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+)
+
+func TestStripedStore(t *testing.T) {
+	kvStore := store.CreateStripedStore(8, 10*time.Millisecond)
+	defer kvStore.Close()
+
+	t.Run("Versions grow across stripes", func(t *testing.T) {
+		var last int64
+		for n := range 50 {
+			version, err := kvStore.Set(fmt.Sprintf("user:%02d", n), fmt.Sprint(n))
+			if err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			if version <= last {
+				t.Fatalf("Expected version to grow past %d, got %d", last, version)
+			}
+			last = version
+		}
+
+		if value, version, err := kvStore.GetWithVersion("user:49"); err != nil || value != "49" || version != last {
+			t.Errorf("Expected '49' at %d, got '%s' at %d (%v)", last, value, version, err)
+		}
+	})
+
+	t.Run("Scan merges the stripes in order", func(t *testing.T) {
+		var keys []string
+		start := ""
+		for {
+			items, more, err := kvStore.Scan(store.ScanOptions{Prefix: "user:", Start: start, Limit: 7})
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+
+			for _, item := range items {
+				keys = append(keys, item.Key)
+			}
+
+			if !more {
+				break
+			}
+			start = items[len(items)-1].Key + "\x00"
+		}
+
+		if len(keys) != 50 || !slices.IsSorted(keys) {
+			t.Errorf("Expected the 50 keys in order, got %v", keys)
+		}
+	})
+
+	t.Run("Txn spans stripes atomically", func(t *testing.T) {
+		result, err := kvStore.Txn(store.Txn{
+			Compare: []store.Compare{{Key: "user:00", Target: store.CompareValue, Result: store.Equal, Value: "0"}},
+			Success: []store.TxnOp{
+				{Type: store.TxnPut, Key: "user:00", Value: "moved"},
+				{Type: store.TxnDelete, Key: "user:01"},
+				{Type: store.TxnGet, Key: "user:00"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Txn failed: %v", err)
+		}
+
+		if !result.Succeeded || result.Results[2].Value != "moved" || result.Results[2].Version != result.Revision {
+			t.Errorf("Unexpected result %+v", result)
+		}
+
+		if _, err := kvStore.Get("user:01"); !errors.Is(err, store.ErrKeyNotFound) {
+			t.Errorf("Expected user:01 to be deleted, got %v", err)
+		}
+	})
+
+	t.Run("Expired keys are swept", func(t *testing.T) {
+		kvStore.SetWithTTL("session", "token", 20*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+
+		if _, err := kvStore.TTL("session"); !errors.Is(err, store.ErrKeyNotFound) {
+			t.Errorf("Expected ErrKeyNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Watches see concurrent writes in revision order", func(t *testing.T) {
+		watch, err := kvStore.Watch(store.WatchOptions{Key: "race:", Prefix: true})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+		defer watch.Close()
+
+		var wg sync.WaitGroup
+		for w := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for n := range 25 {
+					kvStore.Set(fmt.Sprintf("race:%d:%d", w, n), "v")
+				}
+			}()
+		}
+		wg.Wait()
+
+		var last int64
+		for range 200 {
+			ev := nextEvent(t, watch)
+			if ev.Revision <= last {
+				t.Fatalf("Expected revisions to grow, got %d after %d", ev.Revision, last)
+			}
+			last = ev.Revision
+		}
+
+		if stats := kvStore.MemoryStats(); stats.Keys != 249 {
+			t.Errorf("Expected 249 keys, got %d", stats.Keys)
+		}
+	})
+}
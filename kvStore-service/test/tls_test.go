@@ -0,0 +1,222 @@
+package test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	"GRPC-KV-Store-System/schemas/certs"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// testCA is a throwaway certificate authority.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate for localhost signed by the CA, and its key,
+// to dir as name.crt and name.key.
+func (ca *testCA) issue(t *testing.T, dir, name string, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to issue certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	certFile := writeFile(t, dir, name+".crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyFile := writeFile(t, dir, name+".key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certFile, keyFile
+}
+
+// writeFile writes data to dir/name, moving its modification time on so
+// rewrites are noticed however quickly they follow each other.
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime().Add(time.Second)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	if !modTime.IsZero() {
+		os.Chtimes(path, modTime, modTime)
+	}
+	return path
+}
+
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "kv-test-ca")
+	caFile := writeFile(t, dir, "ca.crt", ca.pem)
+
+	serverCert, serverKey := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "client", x509.ExtKeyUsageClientAuth)
+
+	serverConfig, err := certs.ServerConfig(certs.Options{
+		CertFile:          serverCert,
+		KeyFile:           serverKey,
+		CAFile:            caFile,
+		RequireClientCert: true,
+	})
+	if err != nil {
+		t.Fatalf("ServerConfig failed: %v", err)
+	}
+
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(serverConfig)))
+	pb.RegisterKeyValueStoreServer(grpcServer, server.StartServer(kvStore))
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	// call makes a request over a new connection, so each one goes through
+	// a handshake with the files as they are.
+	call := func(opts certs.Options) error {
+		config, err := certs.ClientConfig(opts)
+		if err != nil {
+			t.Fatalf("ClientConfig failed: %v", err)
+		}
+
+		conn, err := grpc.NewClient("localhost:"+portOf(lis), grpc.WithTransportCredentials(credentials.NewTLS(config)))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err = pb.NewKeyValueStoreClient(conn).Set(ctx, &pb.SetRequest{Key: "k", Value: "v"})
+		return err
+	}
+
+	t.Run("Mutual TLS", func(t *testing.T) {
+		if err := call(certs.Options{CertFile: clientCert, KeyFile: clientKey, CAFile: caFile}); err != nil {
+			t.Errorf("Expected the request to succeed, got %v", err)
+		}
+	})
+
+	t.Run("Try connecting without a valid client certificate", func(t *testing.T) {
+		if err := call(certs.Options{CAFile: caFile}); err == nil {
+			t.Error("Expected a client without a certificate to be turned down")
+		}
+
+		other := newTestCA(t, "other-ca")
+		otherCert, otherKey := other.issue(t, t.TempDir(), "client", x509.ExtKeyUsageClientAuth)
+		if err := call(certs.Options{CertFile: otherCert, KeyFile: otherKey, CAFile: caFile}); err == nil {
+			t.Error("Expected a client certificate from another CA to be turned down")
+		}
+
+		// A server certificate is not for authenticating clients.
+		if err := call(certs.Options{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile}); err == nil {
+			t.Error("Expected a certificate without client auth usage to be turned down")
+		}
+	})
+
+	t.Run("Try trusting another CA", func(t *testing.T) {
+		otherFile := writeFile(t, t.TempDir(), "ca.crt", newTestCA(t, "other-ca").pem)
+		if err := call(certs.Options{CertFile: clientCert, KeyFile: clientKey, CAFile: otherFile}); err == nil {
+			t.Error("Expected a server certificate from an untrusted CA to be turned down")
+		}
+	})
+
+	t.Run("Certificates reload without a restart", func(t *testing.T) {
+		rotated := newTestCA(t, "rotated-ca")
+		writeFile(t, dir, "ca.crt", append(ca.pem, rotated.pem...))
+		rotated.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+
+		rotatedDir := t.TempDir()
+		rotatedCA := writeFile(t, rotatedDir, "ca.crt", rotated.pem)
+		newCert, newKey := rotated.issue(t, rotatedDir, "client", x509.ExtKeyUsageClientAuth)
+
+		if err := call(certs.Options{CertFile: newCert, KeyFile: newKey, CAFile: rotatedCA}); err != nil {
+			t.Errorf("Expected the rotated certificates to be used, got %v", err)
+		}
+		if err := call(certs.Options{CertFile: clientCert, KeyFile: clientKey, CAFile: caFile}); err != nil {
+			t.Errorf("Expected clients of the old CA to still be trusted, got %v", err)
+		}
+
+		// A key that does not match the certificate is not picked up.
+		writeFile(t, dir, "server.key", []byte("not a key"))
+		if err := call(certs.Options{CertFile: newCert, KeyFile: newKey, CAFile: rotatedCA}); err != nil {
+			t.Errorf("Expected the last valid certificate to stay in place, got %v", err)
+		}
+	})
+
+	t.Run("Try serving without a certificate", func(t *testing.T) {
+		if _, err := certs.ServerConfig(certs.Options{CAFile: caFile}); err == nil {
+			t.Error("Expected an error")
+		}
+		if _, err := certs.ServerConfig(certs.Options{CertFile: serverCert, KeyFile: serverKey, RequireClientCert: true}); err == nil {
+			t.Error("Expected requiring client certificates without a CA bundle to fail")
+		}
+	})
+}
+
+func portOf(lis net.Listener) string {
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	return port
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+	"GRPC-KV-Store-System/schemas/tracing"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	client := dialInProcess(t, server.StartServer(kvStore), grpc.StatsHandler(tracing.ServerHandler()))
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+
+	spans := func() map[string]sdktrace.ReadOnlySpan {
+		byName := make(map[string]sdktrace.ReadOnlySpan)
+		for _, span := range recorder.Ended() {
+			byName[span.Name()] = span
+		}
+		return byName
+	}
+
+	t.Run("Store calls are children of the RPC's span", func(t *testing.T) {
+		if _, err := client.Set(ctx, &pb.SetRequest{Key: "a", Value: "1"}); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		ended := spans()
+		rpc, ok := ended["kvstore.KeyValueStore/Set"]
+		if !ok {
+			t.Fatalf("Expected a span for the RPC, got %v", ended)
+		}
+		if rpc.SpanContext().TraceID().String() != traceID {
+			t.Errorf("Expected the RPC to continue trace %s, got %s", traceID, rpc.SpanContext().TraceID())
+		}
+
+		storeSpan, ok := ended["store.Set"]
+		if !ok {
+			t.Fatalf("Expected a span for the store call, got %v", ended)
+		}
+		if storeSpan.Parent().SpanID() != rpc.SpanContext().SpanID() {
+			t.Error("Expected the store span to be a child of the RPC span")
+		}
+	})
+
+	t.Run("Sensitive keys are redacted", func(t *testing.T) {
+		client.Get(ctx, &pb.GetRequest{Key: "secret/db"})
+
+		span, ok := spans()["store.GetWithVersion"]
+		if !ok {
+			t.Fatal("Expected a span for the store call")
+		}
+		var key string
+		for _, attr := range span.Attributes() {
+			if attr.Key == "kv.key" {
+				key = attr.Value.AsString()
+			}
+		}
+		if !strings.HasPrefix(key, "sha256:") {
+			t.Errorf("Expected a hashed key, got %q", key)
+		}
+	})
+
+	t.Run("Missing keys are not errors", func(t *testing.T) {
+		client.Get(ctx, &pb.GetRequest{Key: "missing"})
+
+		span, ok := spans()["store.GetWithVersion"]
+		if !ok {
+			t.Fatal("Expected a span for the store call")
+		}
+		if span.Status().Code == codes.Error {
+			t.Errorf("Expected no error on the span, got %s", span.Status().Description)
+		}
+	})
+}
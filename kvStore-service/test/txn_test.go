@@ -0,0 +1,183 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// transfer moves amount from one balance to another if the source still
+// holds the balance that was read.
+func transfer(from, to, fromBalance, newFrom, newTo string) store.Txn {
+	return store.Txn{
+		Compare: []store.Compare{
+			{Key: from, Target: store.CompareValue, Result: store.Equal, Value: fromBalance},
+		},
+		Success: []store.TxnOp{
+			{Type: store.TxnPut, Key: from, Value: newFrom},
+			{Type: store.TxnPut, Key: to, Value: newTo},
+		},
+		Failure: []store.TxnOp{
+			{Type: store.TxnGet, Key: from},
+		},
+	}
+}
+
+func TestStoreTxn(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	kvStore.Set("alice", "100")
+	kvStore.Set("bob", "0")
+
+	t.Run("Success ops run when every compare holds", func(t *testing.T) {
+		result, err := kvStore.Txn(transfer("alice", "bob", "100", "70", "30"))
+		if err != nil {
+			t.Fatalf("Txn failed: %v", err)
+		}
+
+		if !result.Succeeded || len(result.Results) != 2 {
+			t.Fatalf("Expected the success branch, got %+v", result)
+		}
+
+		// Both writes share the transaction's revision.
+		if result.Results[0].Version != result.Revision || result.Results[1].Version != result.Revision {
+			t.Errorf("Expected both puts at revision %d, got %+v", result.Revision, result.Results)
+		}
+
+		alice, _ := kvStore.Get("alice")
+		bob, _ := kvStore.Get("bob")
+		if alice != "70" || bob != "30" {
+			t.Errorf("Expected 70/30, got %s/%s", alice, bob)
+		}
+	})
+
+	t.Run("Failure ops run when a compare fails", func(t *testing.T) {
+		result, err := kvStore.Txn(transfer("alice", "bob", "100", "40", "60"))
+		if err != nil {
+			t.Fatalf("Txn failed: %v", err)
+		}
+
+		if result.Succeeded || len(result.Results) != 1 || result.Results[0].Value != "70" {
+			t.Fatalf("Expected the failure branch to read 70, got %+v", result)
+		}
+
+		if bob, _ := kvStore.Get("bob"); bob != "30" {
+			t.Errorf("Expected bob to be untouched, got %s", bob)
+		}
+	})
+
+	t.Run("Version and existence compares", func(t *testing.T) {
+		_, version, _ := kvStore.GetWithVersion("alice")
+
+		result, err := kvStore.Txn(store.Txn{
+			Compare: []store.Compare{
+				{Key: "alice", Target: store.CompareVersion, Result: store.Equal, Version: version},
+				{Key: "carol", Target: store.CompareExists, Result: store.Equal, Exists: false},
+				{Key: "carol", Target: store.CompareVersion, Result: store.Less, Version: 1},
+			},
+			Success: []store.TxnOp{
+				{Type: store.TxnPut, Key: "carol", Value: "0"},
+			},
+		})
+		if err != nil || !result.Succeeded {
+			t.Errorf("Expected the success branch, got %+v (%v)", result, err)
+		}
+	})
+
+	t.Run("Ops see earlier writes in the same transaction", func(t *testing.T) {
+		result, err := kvStore.Txn(store.Txn{
+			Success: []store.TxnOp{
+				{Type: store.TxnDelete, Key: "carol"},
+				{Type: store.TxnGet, Key: "carol"},
+				{Type: store.TxnPut, Key: "dave", Value: "5"},
+				{Type: store.TxnGet, Key: "dave"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Txn failed: %v", err)
+		}
+
+		if !result.Results[0].Found || result.Results[1].Found || result.Results[3].Value != "5" {
+			t.Errorf("Unexpected results %+v", result.Results)
+		}
+	})
+
+	t.Run("Invalid transactions are rejected", func(t *testing.T) {
+		_, err := kvStore.Txn(store.Txn{
+			Compare: []store.Compare{{Key: "alice", Target: store.CompareExists, Result: store.Greater}},
+		})
+		if !errors.Is(err, store.ErrInvalidCompare) {
+			t.Errorf("Expected ErrInvalidCompare, got %v", err)
+		}
+
+		_, err = kvStore.Txn(store.Txn{Success: []store.TxnOp{{Type: store.TxnPut}}})
+		if !errors.Is(err, store.ErrEmptyKey) {
+			t.Errorf("Expected ErrEmptyKey, got %v", err)
+		}
+	})
+}
+
+func TestDurableStoreTxnRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	kvStore := openDurableStore(t, dir)
+	kvStore.Set("alice", "100")
+	kvStore.Set("bob", "0")
+	result, err := kvStore.Txn(transfer("alice", "bob", "100", "70", "30"))
+	if err != nil || !result.Succeeded {
+		t.Fatalf("Txn failed: %+v (%v)", result, err)
+	}
+	kvStore.Close()
+
+	kvStore = openDurableStore(t, dir)
+	defer kvStore.Close()
+
+	alice, _, _ := kvStore.GetWithVersion("alice")
+	bob, version, _ := kvStore.GetWithVersion("bob")
+	if alice != "70" || bob != "30" || version != result.Revision {
+		t.Errorf("Expected 70/30 at %d after a restart, got %s/%s at %d", result.Revision, alice, bob, version)
+	}
+}
+
+func TestTxnRPC(t *testing.T) {
+	ctx := context.Background()
+	kvServer := server.StartServer(store.CreateStore())
+
+	kvServer.Set(ctx, &pb.SetRequest{Key: "counter", Value: "1"})
+
+	t.Run("Run a transaction", func(t *testing.T) {
+		resp, err := kvServer.Txn(ctx, &pb.TxnRequest{
+			Compare: []*pb.Compare{
+				{Key: "counter", Target: pb.Compare_VALUE, Result: pb.Compare_EQUAL, Value: "1"},
+			},
+			Success: []*pb.TxnOp{
+				{Type: pb.TxnOp_PUT, Key: "counter", Value: "2"},
+				{Type: pb.TxnOp_GET, Key: "counter"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Txn failed: %v", err)
+		}
+
+		if !resp.Succeeded || len(resp.Results) != 2 || resp.Results[1].Value != "2" {
+			t.Errorf("Unexpected response %v", resp)
+		}
+	})
+
+	t.Run("Try an unknown compare target", func(t *testing.T) {
+		_, err := kvServer.Txn(ctx, &pb.TxnRequest{
+			Compare: []*pb.Compare{{Key: "counter", Target: pb.Compare_Target(9)}},
+		})
+		if st, _ := status.FromError(err); st.Code() != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+// binaryValue is not valid UTF-8, so it cannot travel in a proto string.
+var binaryValue = []byte{0x00, 0xff, 0xfe, 'k', 'v', 0x80}
+
+func TestBinaryValueRPCs(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	client := dialInProcess(t, server.StartServer(kvStore))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &pb.WatchRequest{Key: "blob"})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	t.Run("Set and get a binary value", func(t *testing.T) {
+		if _, err := client.Set(ctx, &pb.SetRequest{Key: "blob", ValueBytes: binaryValue}); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		if value, _ := kvStore.Get("blob"); value != string(binaryValue) {
+			t.Errorf("Expected the bytes to be stored as sent, got %q", value)
+		}
+
+		resp, err := client.Get(ctx, &pb.GetRequest{Key: "blob"})
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if resp.Value != "" || !bytes.Equal(resp.ValueBytes, binaryValue) {
+			t.Errorf("Expected the value in value_bytes, got %v", resp)
+		}
+	})
+
+	t.Run("Watches carry binary values", func(t *testing.T) {
+		ev, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if !bytes.Equal(ev.ValueBytes, binaryValue) {
+			t.Errorf("Unexpected event %v", ev)
+		}
+	})
+
+	t.Run("Compare and swap on a binary value", func(t *testing.T) {
+		_, err := client.CompareAndSwap(ctx, &pb.CompareAndSwapRequest{
+			Key:      "blob",
+			Expected: &pb.CompareAndSwapRequest_ExpectedValueBytes{ExpectedValueBytes: binaryValue},
+			Value:    "text",
+		})
+		if err != nil {
+			t.Fatalf("CompareAndSwap failed: %v", err)
+		}
+
+		resp, err := client.Get(ctx, &pb.GetRequest{Key: "blob"})
+		if err != nil || resp.Value != "text" || resp.ValueBytes != nil {
+			t.Errorf("Expected text values to stay in value, got %v (%v)", resp, err)
+		}
+	})
+
+	t.Run("Scan and transactions carry binary values", func(t *testing.T) {
+		_, err := client.Txn(ctx, &pb.TxnRequest{Success: []*pb.TxnOp{
+			{Type: pb.TxnOp_PUT, Key: "blob", ValueBytes: binaryValue},
+		}})
+		if err != nil {
+			t.Fatalf("Txn failed: %v", err)
+		}
+
+		txnResp, err := client.Txn(ctx, &pb.TxnRequest{
+			Compare: []*pb.Compare{{Key: "blob", Target: pb.Compare_VALUE, ValueBytes: binaryValue}},
+			Success: []*pb.TxnOp{{Type: pb.TxnOp_GET, Key: "blob"}},
+		})
+		if err != nil || !txnResp.Succeeded || !bytes.Equal(txnResp.Results[0].ValueBytes, binaryValue) {
+			t.Errorf("Unexpected txn response %v (%v)", txnResp, err)
+		}
+
+		scanResp, err := client.Scan(ctx, &pb.ScanRequest{Prefix: "blob"})
+		if err != nil || len(scanResp.Items) != 1 || !bytes.Equal(scanResp.Items[0].ValueBytes, binaryValue) {
+			t.Errorf("Unexpected scan response %v (%v)", scanResp, err)
+		}
+	})
+}
+
+func TestDurableStoreBinaryValues(t *testing.T) {
+	dir := t.TempDir()
+
+	kvStore := openDurableStore(t, dir)
+	kvStore.Set("blob", string(binaryValue))
+	kvStore.Close()
+
+	kvStore = openDurableStore(t, dir)
+	defer kvStore.Close()
+
+	if value, err := kvStore.Get("blob"); err != nil || value != string(binaryValue) {
+		t.Errorf("Expected the bytes to survive a restart, got %q (%v)", value, err)
+	}
+}
@@ -0,0 +1,277 @@
+package test
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+)
+
+func openDurableStore(t *testing.T, dir string) *store.DurableStore {
+	t.Helper()
+
+	kvStore, err := store.OpenDurableStore(dir, store.DurableOptions{
+		Fsync: store.FsyncPolicy{Mode: store.FsyncAlways},
+	})
+	if err != nil {
+		t.Fatalf("Failed to open durable store: %v", err)
+	}
+
+	return kvStore
+}
+
+func TestDurableStoreRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	kvStore := openDurableStore(t, dir)
+	kvStore.Set("user:1", "alice")
+	kvStore.Set("user:2", "bob")
+	kvStore.Set("user:1", "carol")
+	kvStore.Delete("user:2")
+	kvStore.SetWithTTL("session", "token", time.Hour)
+	kvStore.SetWithTTL("expired", "token", time.Millisecond)
+
+	if err := kvStore.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	kvStore = openDurableStore(t, dir)
+	defer kvStore.Close()
+
+	if value, err := kvStore.Get("user:1"); err != nil || value != "carol" {
+		t.Errorf("Expected 'carol', got '%s' (%v)", value, err)
+	}
+
+	if _, err := kvStore.Get("user:2"); !errors.Is(err, store.ErrKeyNotFound) {
+		t.Errorf("Expected deleted key to stay deleted, got %v", err)
+	}
+
+	if ttl, err := kvStore.TTL("session"); err != nil || ttl <= 0 || ttl > time.Hour {
+		t.Errorf("Expected ttl to survive a restart, got %v (%v)", ttl, err)
+	}
+
+	if _, err := kvStore.Get("expired"); !errors.Is(err, store.ErrKeyNotFound) {
+		t.Errorf("Expected expired key to stay expired, got %v", err)
+	}
+}
+
+func TestDurableStoreKeepsVersions(t *testing.T) {
+	dir := t.TempDir()
+
+	kvStore := openDurableStore(t, dir)
+	kvStore.Set("config", "v1")
+	version, _ := kvStore.Set("config", "v2")
+	latest, _ := kvStore.Set("scratch", "value")
+	kvStore.Delete("scratch")
+	kvStore.Close()
+
+	kvStore = openDurableStore(t, dir)
+	defer kvStore.Close()
+
+	if _, got, err := kvStore.GetWithVersion("config"); err != nil || got != version {
+		t.Errorf("Expected version %d after a restart, got %d (%v)", version, got, err)
+	}
+
+	// The revision of the deleted key must not be handed out again.
+	if next, _ := kvStore.Set("other", "value"); next <= latest {
+		t.Errorf("Expected version to grow past %d, got %d", latest, next)
+	}
+}
+
+func TestDurableStoreTornTail(t *testing.T) {
+	dir := t.TempDir()
+	walPath := filepath.Join(dir, "wal-0000000000000000.log")
+
+	kvStore := openDurableStore(t, dir)
+	kvStore.Set("kept", "value")
+	kvStore.Close()
+
+	info, err := os.Stat(walPath)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	goodSize := info.Size()
+
+	// Simulate a crash halfway through writing the next record.
+	kvStore = openDurableStore(t, dir)
+	kvStore.Set("torn", "value")
+	kvStore.Close()
+
+	if err := os.Truncate(walPath, goodSize+5); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+
+	kvStore = openDurableStore(t, dir)
+
+	if value, err := kvStore.Get("kept"); err != nil || value != "value" {
+		t.Errorf("Expected 'value', got '%s' (%v)", value, err)
+	}
+
+	if _, err := kvStore.Get("torn"); !errors.Is(err, store.ErrKeyNotFound) {
+		t.Errorf("Expected torn write to be dropped, got %v", err)
+	}
+
+	// The store must keep appending after the truncated tail.
+	kvStore.Set("after", "value")
+	kvStore.Close()
+
+	kvStore = openDurableStore(t, dir)
+	defer kvStore.Close()
+
+	if value, err := kvStore.Get("after"); err != nil || value != "value" {
+		t.Errorf("Expected 'value', got '%s' (%v)", value, err)
+	}
+}
+
+func TestDurableStoreTornSegmentHeader(t *testing.T) {
+	for _, header := range [][]byte{nil, []byte("KVW")} {
+		dir := t.TempDir()
+
+		kvStore := openDurableStore(t, dir)
+		kvStore.Set("kept", "value")
+		kvStore.Close()
+
+		// Simulate a crash in the middle of starting the next segment.
+		if err := os.WriteFile(filepath.Join(dir, "wal-0000000000000001.log"), header, 0o600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+
+		kvStore = openDurableStore(t, dir)
+
+		if value, err := kvStore.Get("kept"); err != nil || value != "value" {
+			t.Errorf("Expected 'value', got '%s' (%v)", value, err)
+		}
+
+		// The store must keep appending to the segment it started again.
+		kvStore.Set("after", "value")
+		kvStore.Close()
+
+		kvStore = openDurableStore(t, dir)
+
+		if value, err := kvStore.Get("after"); err != nil || value != "value" {
+			t.Errorf("Expected 'value', got '%s' (%v)", value, err)
+		}
+		kvStore.Close()
+	}
+}
+
+func TestDurableStoreCorruption(t *testing.T) {
+	dir := t.TempDir()
+	walPath := filepath.Join(dir, "wal-0000000000000000.log")
+
+	kvStore := openDurableStore(t, dir)
+	kvStore.Set("first", "value")
+	kvStore.Set("second", "value")
+	kvStore.Close()
+
+	data, err := os.ReadFile(walPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	// Flip a byte inside the first record, which is not the tail.
+	data[20] ^= 0xff
+	if err := os.WriteFile(walPath, data, 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	_, err = store.OpenDurableStore(dir, store.DurableOptions{})
+	if !errors.Is(err, store.ErrCorruptLog) {
+		t.Errorf("Expected ErrCorruptLog, got %v", err)
+	}
+}
+
+// writeLegacyLog writes the single wal.log of stores from before segments,
+// in format version 1, with one put record per key.
+func writeLegacyLog(t *testing.T, dir string, puts map[string]string) {
+	t.Helper()
+
+	data := binary.LittleEndian.AppendUint32([]byte("KVWL"), 1)
+	for key, value := range puts {
+		payload := binary.AppendUvarint(nil, 1)
+		payload = append(payload, 1)
+		payload = binary.AppendUvarint(payload, uint64(len(key)))
+		payload = append(payload, key...)
+		payload = binary.AppendUvarint(payload, uint64(len(value)))
+		payload = append(payload, value...)
+		payload = binary.AppendVarint(payload, 0)
+
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(payload)))
+		data = binary.LittleEndian.AppendUint32(data, crc32.Checksum(payload, crc32.MakeTable(crc32.Castagnoli)))
+		data = append(data, payload...)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "wal.log"), data, 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
+
+func TestDurableStoreLegacyLog(t *testing.T) {
+	t.Run("A legacy log becomes the first segment", func(t *testing.T) {
+		dir := t.TempDir()
+		writeLegacyLog(t, dir, map[string]string{"user:1": "alice"})
+
+		kvStore := openDurableStore(t, dir)
+		kvStore.Set("user:2", "bob")
+		kvStore.Close()
+
+		if _, err := os.Stat(filepath.Join(dir, "wal.log")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected wal.log to be migrated away, got %v", err)
+		}
+
+		kvStore = openDurableStore(t, dir)
+		defer kvStore.Close()
+
+		for key, want := range map[string]string{"user:1": "alice", "user:2": "bob"} {
+			if value, err := kvStore.Get(key); err != nil || value != want {
+				t.Errorf("Expected '%s' under %s, got '%s' (%v)", want, key, value, err)
+			}
+		}
+	})
+
+	t.Run("Try a legacy log beside segments", func(t *testing.T) {
+		dir := t.TempDir()
+
+		kvStore := openDurableStore(t, dir)
+		kvStore.Set("user:1", "alice")
+		kvStore.Close()
+
+		writeLegacyLog(t, dir, map[string]string{"user:2": "bob"})
+
+		if _, err := store.OpenDurableStore(dir, store.DurableOptions{}); err == nil {
+			t.Error("Expected opening to fail while wal.log sits beside segments")
+		}
+	})
+}
+
+func TestParseFsyncPolicy(t *testing.T) {
+	tests := []struct {
+		input string
+		want  store.FsyncPolicy
+		valid bool
+	}{
+		{"always", store.FsyncPolicy{Mode: store.FsyncAlways}, true},
+		{"never", store.FsyncPolicy{Mode: store.FsyncNever}, true},
+		{"100ms", store.FsyncPolicy{Mode: store.FsyncInterval, Interval: 100 * time.Millisecond}, true},
+		{"sometimes", store.FsyncPolicy{}, false},
+		{"-1s", store.FsyncPolicy{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := store.ParseFsyncPolicy(tt.input)
+		if (err == nil) != tt.valid {
+			t.Errorf("ParseFsyncPolicy(%q) error = %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseFsyncPolicy(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"GRPC-KV-Store-System/kvStore-service/internal/server"
+	"GRPC-KV-Store-System/kvStore-service/internal/store"
+	pb "GRPC-KV-Store-System/schemas/grpc"
+)
+
+func nextEvent(t *testing.T, watch *store.Watch) store.Event {
+	t.Helper()
+
+	select {
+	case ev, ok := <-watch.Events():
+		if !ok {
+			t.Fatalf("Watch ended: %v", watch.Err())
+		}
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+
+	return store.Event{}
+}
+
+func TestStoreWatch(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	t.Run("Watch a prefix", func(t *testing.T) {
+		watch, err := kvStore.Watch(store.WatchOptions{Key: "config/", Prefix: true})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+		defer watch.Close()
+
+		kvStore.Set("other", "ignored")
+		version, _ := kvStore.Set("config/a", "1")
+		kvStore.Delete("config/a")
+
+		if ev := nextEvent(t, watch); ev.Type != store.EventPut || ev.Key != "config/a" || ev.Value != "1" || ev.Revision != version {
+			t.Errorf("Expected a put of config/a at %d, got %+v", version, ev)
+		}
+
+		if ev := nextEvent(t, watch); ev.Type != store.EventDelete || ev.Key != "config/a" {
+			t.Errorf("Expected a delete of config/a, got %+v", ev)
+		}
+	})
+
+	t.Run("Replay from a start revision", func(t *testing.T) {
+		start, _ := kvStore.Set("replayed", "1")
+		kvStore.Set("replayed", "2")
+
+		watch, err := kvStore.Watch(store.WatchOptions{Key: "replayed", StartRevision: start})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+		defer watch.Close()
+
+		if ev := nextEvent(t, watch); ev.Value != "1" || ev.Revision != start {
+			t.Errorf("Expected to replay '1' at %d, got %+v", start, ev)
+		}
+		if ev := nextEvent(t, watch); ev.Value != "2" {
+			t.Errorf("Expected to replay '2', got %+v", ev)
+		}
+	})
+
+	t.Run("Transaction writes share a revision", func(t *testing.T) {
+		watch, err := kvStore.Watch(store.WatchOptions{Prefix: true})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+		defer watch.Close()
+
+		result, _ := kvStore.Txn(store.Txn{Success: []store.TxnOp{
+			{Type: store.TxnPut, Key: "txn/a", Value: "1"},
+			{Type: store.TxnPut, Key: "txn/b", Value: "2"},
+		}})
+
+		first, second := nextEvent(t, watch), nextEvent(t, watch)
+		if first.Revision != result.Revision || second.Revision != result.Revision {
+			t.Errorf("Expected both events at %d, got %+v and %+v", result.Revision, first, second)
+		}
+	})
+
+	t.Run("Persist does not produce an event", func(t *testing.T) {
+		kvStore.SetWithTTL("kept", "value", time.Hour)
+
+		watch, err := kvStore.Watch(store.WatchOptions{Key: "kept"})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+		defer watch.Close()
+
+		kvStore.Persist("kept")
+		kvStore.Set("kept", "new")
+
+		if ev := nextEvent(t, watch); ev.Value != "new" {
+			t.Errorf("Expected the put of 'new', got %+v", ev)
+		}
+	})
+}
+
+func TestStoreWatchCompaction(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	first, _ := kvStore.Set("key", "0")
+
+	slow, err := kvStore.Watch(store.WatchOptions{Key: "key", StartRevision: first})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer slow.Close()
+
+	// Push the first revision out of the event history without reading
+	// from the slow watch.
+	for n := 1; n <= 20000; n++ {
+		kvStore.Set("key", fmt.Sprint(n))
+	}
+
+	t.Run("Starting at a compacted revision fails", func(t *testing.T) {
+		if _, err := kvStore.Watch(store.WatchOptions{Key: "key", StartRevision: first}); !errors.Is(err, store.ErrCompacted) {
+			t.Errorf("Expected ErrCompacted, got %v", err)
+		}
+	})
+
+	t.Run("A watch that falls behind the history fails", func(t *testing.T) {
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case _, ok := <-slow.Events():
+				if ok {
+					continue
+				}
+				if !errors.Is(slow.Err(), store.ErrCompacted) {
+					t.Errorf("Expected ErrCompacted, got %v", slow.Err())
+				}
+				return
+			case <-timeout:
+				t.Fatal("Timed out waiting for the slow watch to fail")
+			}
+		}
+	})
+}
+
+func TestWatchRPC(t *testing.T) {
+	kvStore := store.CreateStore()
+	defer kvStore.Close()
+
+	kvServer := server.StartServer(kvStore)
+	client := dialInProcess(t, kvServer)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Watch a prefix", func(t *testing.T) {
+		stream, err := client.Watch(ctx, &pb.WatchRequest{Key: "app/", Prefix: true})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+
+		// The headers arrive once the watch is in place.
+		if _, err := stream.Header(); err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+
+		client.Set(ctx, &pb.SetRequest{Key: "app/name", Value: "kv"})
+
+		ev, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if ev.Type != pb.WatchEvent_PUT || ev.Key != "app/name" || ev.Value != "kv" {
+			t.Errorf("Unexpected event %v", ev)
+		}
+	})
+
+	t.Run("Try watching a compacted revision", func(t *testing.T) {
+		for n := 0; n < 10001; n++ {
+			kvStore.Set("churn", "value")
+		}
+
+		stream, err := client.Watch(ctx, &pb.WatchRequest{Key: "app/name", StartRevision: 1})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+
+		if st, _ := status.FromError(err); st.Code() != codes.OutOfRange {
+			t.Errorf("Expected OutOfRange, got %v", err)
+		}
+	})
+
+	t.Run("Shutdown ends open watches", func(t *testing.T) {
+		stream, err := client.Watch(ctx, &pb.WatchRequest{Key: "app/name"})
+		if err != nil {
+			t.Fatalf("Watch failed: %v", err)
+		}
+		stream.Header()
+
+		kvServer.Shutdown()
+
+		if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+			t.Errorf("Expected Unavailable, got %v", err)
+		}
+	})
+}
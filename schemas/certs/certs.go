@@ -0,0 +1,216 @@
+// Package certs builds the TLS configurations the services serve and dial
+// with. Certificates, keys and CA bundles are read again whenever their
+// files change, so they can be rotated without a restart.
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+type Options struct {
+	// CertFile and KeyFile are the PEM certificate and key to present.
+	// Servers need them; clients only to authenticate to servers that
+	// require client certificates.
+	CertFile string
+	KeyFile  string
+	// CAFile is a PEM bundle of the CAs to trust the other side's
+	// certificate from. Clients fall back to the system roots without
+	// one.
+	CAFile string
+	// RequireClientCert makes servers turn down clients without a
+	// certificate signed by one of CAFile's CAs.
+	RequireClientCert bool
+}
+
+// ServerConfig is the TLS configuration of a server.
+func ServerConfig(opts Options) (*tls.Config, error) {
+	if opts.CertFile == "" || opts.KeyFile == "" {
+		return nil, errors.New("serving TLS needs a certificate and a key")
+	}
+	if opts.RequireClientCert && opts.CAFile == "" {
+		return nil, errors.New("requiring client certificates needs a CA bundle to verify them with")
+	}
+
+	files, err := watch(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := files.current()
+			return cert, nil
+		},
+	}
+
+	if opts.CAFile != "" {
+		// Client certificates are verified here rather than through
+		// ClientCAs, which would keep the CA bundle the config was made
+		// with.
+		config.ClientAuth = tls.RequestClientCert
+		if opts.RequireClientCert {
+			config.ClientAuth = tls.RequireAnyClientCert
+		}
+
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return nil
+			}
+
+			_, pool := files.current()
+			return verify(cs, x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+		}
+	}
+
+	return config, nil
+}
+
+// ClientConfig is the TLS configuration of a client.
+func ClientConfig(opts Options) (*tls.Config, error) {
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, errors.New("a client certificate needs both a certificate and a key")
+	}
+
+	files, err := watch(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if opts.CertFile != "" {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := files.current()
+			return cert, nil
+		}
+	}
+
+	if opts.CAFile != "" {
+		// The standard verification only knows the roots the config was
+		// made with, so servers are verified here against the CA bundle
+		// as it is now instead.
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server presented no certificate")
+			}
+
+			_, pool := files.current()
+			return verify(cs, x509.VerifyOptions{DNSName: cs.ServerName, Roots: pool})
+		}
+	}
+
+	return config, nil
+}
+
+// verify checks the certificate the other side of a connection presented
+// chains up to one of opts.Roots, through the intermediates it sent along.
+func verify(cs tls.ConnectionState, opts x509.VerifyOptions) error {
+	opts.Intermediates = x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// files holds the certificate and CA bundle of a set of options, reloading
+// them when their files are modified.
+type files struct {
+	opts Options
+
+	mu       sync.Mutex
+	modTimes [3]time.Time
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+}
+
+func watch(opts Options) (*files, error) {
+	f := &files{opts: opts}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// current returns the certificate and CA pool, either of which is nil when
+// not configured. A failed reload, such as one between a new certificate
+// and its key being written, keeps the previous ones until the next
+// handshake tries again.
+func (f *files) current() (*tls.Certificate, *x509.CertPool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.modified() {
+		if err := f.reload(); err != nil {
+			slog.Error("Failed to reload TLS files, keeping the current ones", "error", err)
+		} else {
+			slog.Info("Reloaded TLS certificate", "cert", f.opts.CertFile)
+		}
+	}
+
+	return f.cert, f.pool
+}
+
+func (f *files) modified() bool {
+	for n, path := range f.paths() {
+		if info, err := os.Stat(path); err == nil && !info.ModTime().Equal(f.modTimes[n]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *files) paths() [3]string {
+	return [3]string{f.opts.CertFile, f.opts.KeyFile, f.opts.CAFile}
+}
+
+func (f *files) reload() error {
+	var modTimes [3]time.Time
+	for n, path := range f.paths() {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTimes[n] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if f.opts.CertFile != "" {
+		loaded, err := tls.LoadX509KeyPair(f.opts.CertFile, f.opts.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate %s: %w", f.opts.CertFile, err)
+		}
+		cert = &loaded
+	}
+
+	var pool *x509.CertPool
+	if f.opts.CAFile != "" {
+		pem, err := os.ReadFile(f.opts.CAFile)
+		if err != nil {
+			return err
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in CA bundle %s", f.opts.CAFile)
+		}
+	}
+
+	f.modTimes, f.cert, f.pool = modTimes, cert, pool
+	return nil
+}
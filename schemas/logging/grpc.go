@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDUnaryInterceptor tags the RPC's context, and so its log records,
+// with the request ID the caller sent in the x-request-id metadata, or a
+// new one when it sent none or one too long or unprintable to keep. The ID
+// is sent back in the response header.
+func RequestIDUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := serverRequestID(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+
+	return handler(WithRequestID(ctx, id), req)
+}
+
+// RequestIDStreamInterceptor is RequestIDUnaryInterceptor for streams. The
+// ID goes out with the headers the handler sends, or its first message,
+// rather than making the stream send headers of its own: clients such as
+// the REST API's watches take headers to mean the stream got going. It is
+// always sent back in the trailer.
+func RequestIDStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := serverRequestID(ss.Context())
+	md := metadata.Pairs(RequestIDHeader, id)
+	ss.SetTrailer(md)
+
+	return handler(srv, &requestIDStream{ServerStream: ss, ctx: WithRequestID(ss.Context(), id), md: md})
+}
+
+type requestIDStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	md  metadata.MD
+	// headerSent is set once the ID is on its way with the headers.
+	headerSent bool
+}
+
+func (s *requestIDStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *requestIDStream) SendHeader(md metadata.MD) error {
+	s.headerSent = true
+	return s.ServerStream.SendHeader(metadata.Join(md, s.md))
+}
+
+func (s *requestIDStream) SendMsg(m any) error {
+	if !s.headerSent {
+		s.headerSent = true
+		s.ServerStream.SetHeader(s.md)
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
+
+// serverRequestID returns the valid request ID the caller sent, or a new
+// one.
+func serverRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDHeader); len(ids) > 0 && ValidRequestID(ids[0]) {
+			return ids[0]
+		}
+	}
+
+	return NewRequestID()
+}
@@ -0,0 +1,125 @@
+// Package logging sets up the structured logging both services share. Log
+// records carry the ID of the request they were made for, which travels
+// from the REST API to the KV store in the X-Request-ID header and gRPC
+// metadata, and values are redacted before they reach the log.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// RequestIDHeader carries request IDs, as an HTTP header and as gRPC
+// metadata, which is lower case.
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLength caps request IDs callers choose, so they cannot fill
+// the log.
+const maxRequestIDLength = 128
+
+// Formats records can be written in.
+const (
+	Text = "text"
+	JSON = "json"
+)
+
+type Options struct {
+	// Level is the least severe level logged: debug, info, warn or error.
+	Level string
+	// Format is Text or JSON.
+	Format string
+	// Redaction is how values and sensitive keys are logged.
+	Redaction Redaction
+}
+
+// Setup makes a logger writing to w the default, for slog and for the log
+// package alike.
+func Setup(w io.Writer, opts Options) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+		return fmt.Errorf("unknown log level %q, expected debug, info, warn or error", opts.Level)
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch opts.Format {
+	case Text, "":
+		handler = slog.NewTextHandler(w, handlerOpts)
+	case JSON:
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	default:
+		return fmt.Errorf("unknown log format %q, expected %s or %s", opts.Format, Text, JSON)
+	}
+
+	if err := opts.Redaction.validate(); err != nil {
+		return err
+	}
+
+	redaction.Store(&opts.Redaction)
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// Fatal logs msg at error level and exits with status 1.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying id, which records logged with it are
+// tagged with.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID ctx carries, or empty.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID.
+func NewRequestID() string {
+	var b [12]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ValidRequestID reports whether a request ID a caller sent may be kept
+// rather than replaced: short and printable, so it cannot forge log lines.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	return !strings.ContainsFunc(id, func(r rune) bool { return r < 0x21 || r > 0x7e })
+}
+
+// contextHandler tags records with the request ID of the context they were
+// logged with.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
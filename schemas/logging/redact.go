@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// How values are logged.
+const (
+	// Hash logs a short SHA-256 of a value and its length, so equal
+	// values can be matched across records without being revealed.
+	// Short, guessable values can still be found by hashing guesses.
+	Hash = "hash"
+	// Omit leaves values out.
+	Omit = "omit"
+	// Plain logs values as they are, for development only.
+	Plain = "plain"
+)
+
+// DefaultSensitivePrefixes are the key prefixes redacted unless configured
+// otherwise.
+var DefaultSensitivePrefixes = []string{"secret", "password", "passwd", "token", "credential", "private", "apikey", "api_key"}
+
+type Redaction struct {
+	// Values is how values are logged: Hash, Omit or Plain. Hash when
+	// empty.
+	Values string
+	// SensitivePrefixes are the prefixes, matched ignoring case, of keys
+	// that are logged like values rather than as they are.
+	SensitivePrefixes []string
+}
+
+var redaction atomic.Pointer[Redaction]
+
+func init() {
+	redaction.Store(&Redaction{Values: Hash, SensitivePrefixes: DefaultSensitivePrefixes})
+}
+
+func (r Redaction) validate() error {
+	switch r.Values {
+	case Hash, Omit, Plain, "":
+		return nil
+	}
+	return fmt.Errorf("unknown value redaction %q, expected %s, %s or %s", r.Values, Hash, Omit, Plain)
+}
+
+func (r Redaction) sensitive(key string) bool {
+	for _, prefix := range r.SensitivePrefixes {
+		if len(key) >= len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the attribute to log a value under name with, redacted.
+func Value(name, value string) slog.Attr {
+	return redact(redaction.Load().Values, name, value)
+}
+
+// Key returns the attribute to log a key, or a key prefix, under name with.
+// Keys with a sensitive prefix are redacted like values, others are logged
+// as they are.
+func Key(name, key string) slog.Attr {
+	r := redaction.Load()
+	if !r.sensitive(key) {
+		return slog.String(name, key)
+	}
+	return redact(r.Values, name, key)
+}
+
+// Keys returns the attribute to log several keys under name with, each
+// redacted like Key would.
+func Keys(name string, keys []string) slog.Attr {
+	logged := make([]string, 0, len(keys))
+	for _, key := range keys {
+		logged = append(logged, RedactedKey(key))
+	}
+	return slog.Any(name, logged)
+}
+
+// RedactedKey returns key redacted like Keys logs it, for span attributes
+// and other places that take a plain string.
+func RedactedKey(key string) string {
+	r := redaction.Load()
+	switch {
+	case !r.sensitive(key), r.Values == Plain:
+		return key
+	case r.Values == Omit:
+		return "<redacted>"
+	default:
+		return "sha256:" + digest(key)
+	}
+}
+
+func redact(mode, name, value string) slog.Attr {
+	switch mode {
+	case Omit:
+		// Handlers drop empty attributes.
+		return slog.Attr{}
+	case Plain:
+		if !utf8.ValidString(value) {
+			return slog.String(name, fmt.Sprintf("<%d bytes>", len(value)))
+		}
+		return slog.String(name, value)
+	}
+
+	return slog.Group(name, slog.String("sha256", digest(value)), slog.Int("len", len(value)))
+}
+
+// digest is the first 12 hex digits of value's SHA-256.
+func digest(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:6])
+}
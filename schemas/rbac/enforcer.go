@@ -0,0 +1,110 @@
+package rbac
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync/atomic"
+
+	"GRPC-KV-Store-System/schemas/logging"
+)
+
+// DeniedError is the error of an access a policy does not allow.
+type DeniedError struct {
+	Principal string
+	Access    Access
+	Reason    string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("principal %s may not %s: %s", describe(e.Principal), e.Access, e.Reason)
+}
+
+// attrs describes the denial for the log, with the key redacted.
+func (e *DeniedError) attrs() []any {
+	return []any{
+		"principal", describe(e.Principal),
+		"namespace", e.Access.Namespace,
+		"verb", e.Access.Verb,
+		logging.Key("key", e.Access.Key),
+		"prefix", e.Access.Prefix,
+		"reason", e.Reason,
+	}
+}
+
+// Enforcer checks accesses against the policy in a file, which Reload
+// reads again without dropping requests.
+type Enforcer struct {
+	path string
+	// dryRun logs the accesses the policy denies instead of turning them
+	// down, to try a policy out before enforcing it.
+	dryRun bool
+	policy atomic.Pointer[Policy]
+}
+
+func LoadEnforcer(path string, dryRun bool) (*Enforcer, error) {
+	e := &Enforcer{path: path, dryRun: dryRun}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Reload reads the policy file again. The current policy stays in place
+// if the file is invalid.
+func (e *Enforcer) Reload() error {
+	policy, err := LoadPolicy(e.path)
+	if err != nil {
+		return err
+	}
+
+	e.policy.Store(policy)
+	slog.Info("Loaded RBAC policy", "path", e.path, "roles", len(policy.Roles), "bindings", len(policy.Bindings))
+	return nil
+}
+
+// ReloadOnSignal reloads the policy whenever the process gets one of
+// signals, usually SIGHUP, keeping the current one if the file has become
+// invalid.
+func (e *Enforcer) ReloadOnSignal(signals ...os.Signal) {
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+
+	go func() {
+		for range received {
+			if err := e.Reload(); err != nil {
+				slog.Error("Failed to reload RBAC policy, keeping the current one", "error", err)
+			}
+		}
+	}()
+}
+
+// Explain decides whether principal may make access under the current
+// policy, whether or not the enforcer is a dry run.
+func (e *Enforcer) Explain(principal string, access Access) Decision {
+	return e.policy.Load().Check(principal, access)
+}
+
+// Authorize returns a *DeniedError for the first of accesses principal
+// may not make. In a dry run it logs why and returns nil.
+func (e *Enforcer) Authorize(principal string, accesses ...Access) error {
+	for _, access := range accesses {
+		decision := e.Explain(principal, access)
+		if decision.Allowed {
+			continue
+		}
+
+		err := &DeniedError{Principal: principal, Access: access, Reason: decision.Reason}
+		if e.dryRun {
+			slog.Warn("RBAC dry run, would deny", err.attrs()...)
+			continue
+		}
+
+		slog.Warn("RBAC denied", err.attrs()...)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+// Package rbac is the role-based access policy on key prefixes that both
+// the REST API and the KV store enforce.
+//
+// A policy file binds principals to roles, and each role is a list of
+// rules granting or denying verbs on the keys starting with a prefix in a
+// namespace:
+//
+//	{
+//	  "roles": {
+//	    "config-reader": [
+//	      {"prefix": "config:", "verbs": ["read"]},
+//	      {"prefix": "secrets:", "verbs": ["*"], "deny": true}
+//	    ],
+//	    "team-a": [{"namespace": "team-a", "prefix": "", "verbs": ["*"]}],
+//	    "admin": [{"namespace": "*", "prefix": "", "verbs": ["*"]}]
+//	  },
+//	  "bindings": {"billing": ["config-reader"], "alice": ["team-a"], "ops": ["admin"]}
+//	}
+//
+// Rules without a namespace are on the default namespace, and rules on the
+// namespace "*" on every namespace. A rule denying an access wins over any
+// rule allowing it, and an access no rule allows is denied. Principals bound under "*" are every principal,
+// including the unnamed one of requests made without credentials.
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// PrincipalHeader is the gRPC metadata the REST API names the principal it
+// makes a request for in.
+const PrincipalHeader = "x-kv-principal"
+
+// Everyone is the binding that applies to every principal.
+const Everyone = "*"
+
+// AllNamespaces is the namespace of rules on every namespace.
+const AllNamespaces = "*"
+
+type Verb string
+
+const (
+	Read   Verb = "read"
+	Write  Verb = "write"
+	Delete Verb = "delete"
+	// Admin covers namespaces, usage, snapshots and stats. Only rules on
+	// the empty prefix, which is every key, can grant it. Managing
+	// namespaces, snapshots and stats is admin of the default namespace;
+	// the usage of a namespace is admin of that namespace.
+	Admin Verb = "admin"
+
+	// anyVerb in a rule stands for every verb.
+	anyVerb Verb = "*"
+)
+
+var verbs = []Verb{Read, Write, Delete, Admin, anyVerb}
+
+// Rule allows, or denies, verbs on the keys starting with Prefix in
+// Namespace. A trailing * on the prefix is ignored, so config:* is the same
+// as config:.
+type Rule struct {
+	// Namespace is "" for the default namespace, or AllNamespaces.
+	Namespace string `json:"namespace"`
+	Prefix    string `json:"prefix"`
+	Verbs  []Verb `json:"verbs"`
+	Deny   bool   `json:"deny"`
+}
+
+func (r Rule) covers(verb Verb) bool {
+	return slices.Contains(r.Verbs, verb) || slices.Contains(r.Verbs, anyVerb)
+}
+
+// matches reports whether r applies to access. An allow rule must cover
+// every key a prefix access could touch, while a deny rule applies as soon
+// as it covers any of them. Admin is not on keys, so only rules on every
+// key decide it.
+func (r Rule) matches(access Access) bool {
+	if !r.covers(access.Verb) {
+		return false
+	}
+
+	if r.Namespace != AllNamespaces && r.Namespace != access.Namespace {
+		return false
+	}
+
+	if access.Verb == Admin {
+		return r.Prefix == ""
+	}
+
+	if strings.HasPrefix(access.Key, r.Prefix) {
+		return true
+	}
+	return r.Deny && access.Prefix && strings.HasPrefix(r.Prefix, access.Key)
+}
+
+func (r Rule) String() string {
+	keys := "every key"
+	if r.Prefix != "" {
+		keys = fmt.Sprintf("keys starting with %q", r.Prefix)
+	}
+
+	switch r.Namespace {
+	case "":
+		return keys
+	case AllNamespaces:
+		return keys + " of every namespace"
+	default:
+		return fmt.Sprintf("%s of namespace %q", keys, r.Namespace)
+	}
+}
+
+// Access is a verb on a key of Namespace, "" for the default namespace, or
+// on every key starting with Key when Prefix is set.
+type Access struct {
+	Namespace string
+	Verb      Verb
+	Key       string
+	Prefix    bool
+}
+
+// OnKey is the access of verb to a single key of namespace.
+func OnKey(namespace string, verb Verb, key string) Access {
+	return Access{Namespace: namespace, Verb: verb, Key: key}
+}
+
+// OnPrefix is the access of verb to every key of namespace starting with
+// prefix.
+func OnPrefix(namespace string, verb Verb, prefix string) Access {
+	return Access{Namespace: namespace, Verb: verb, Key: prefix, Prefix: true}
+}
+
+func (a Access) String() string {
+	var access string
+	switch {
+	case a.Verb == Admin:
+		access = string(Admin)
+	case a.Prefix && a.Key == "":
+		access = fmt.Sprintf("%s every key", a.Verb)
+	case a.Prefix:
+		access = fmt.Sprintf("%s keys starting with %q", a.Verb, a.Key)
+	default:
+		access = fmt.Sprintf("%s key %q", a.Verb, a.Key)
+	}
+
+	if a.Namespace != "" {
+		access += fmt.Sprintf(" of namespace %q", a.Namespace)
+	}
+	return access
+}
+
+type Policy struct {
+	Roles map[string][]Rule `json:"roles"`
+	// Bindings maps a principal, or Everyone, to the names of its roles.
+	Bindings map[string][]string `json:"bindings"`
+}
+
+// Decision is whether a policy allows an access, with the reason why.
+type Decision struct {
+	Allowed bool
+	Reason  string
+}
+
+// LoadPolicy reads a policy from a JSON file.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+
+	for name, rules := range policy.Roles {
+		for n, rule := range rules {
+			if len(rule.Verbs) == 0 {
+				return nil, fmt.Errorf("invalid policy file %s: rule %d of role %q has no verbs", path, n, name)
+			}
+			for _, verb := range rule.Verbs {
+				if !slices.Contains(verbs, verb) {
+					return nil, fmt.Errorf("invalid policy file %s: unknown verb %q in role %q, expected read, write, delete, admin or *", path, verb, name)
+				}
+			}
+			rules[n].Prefix = strings.TrimSuffix(rule.Prefix, "*")
+		}
+	}
+
+	for principal, roles := range policy.Bindings {
+		for _, role := range roles {
+			if _, ok := policy.Roles[role]; !ok {
+				return nil, fmt.Errorf("invalid policy file %s: principal %q is bound to unknown role %q", path, principal, role)
+			}
+		}
+	}
+
+	return &policy, nil
+}
+
+// roles returns the roles bound to principal, directly or through
+// Everyone, in order.
+func (p *Policy) roles(principal string) []string {
+	roles := slices.Clone(p.Bindings[Everyone])
+	if principal != "" {
+		roles = append(roles, p.Bindings[principal]...)
+	}
+
+	sort.Strings(roles)
+	return slices.Compact(roles)
+}
+
+// Check decides whether principal may make access, and explains why.
+func (p *Policy) Check(principal string, access Access) Decision {
+	roles := p.roles(principal)
+
+	var allowedBy string
+	for _, role := range roles {
+		for _, rule := range p.Roles[role] {
+			if !rule.matches(access) {
+				continue
+			}
+
+			if rule.Deny {
+				return Decision{Reason: fmt.Sprintf("role %q denies %s on %s", role, access.Verb, rule)}
+			}
+			if allowedBy == "" {
+				allowedBy = fmt.Sprintf("role %q allows %s on %s", role, access.Verb, rule)
+			}
+		}
+	}
+
+	if allowedBy != "" {
+		return Decision{Allowed: true, Reason: allowedBy}
+	}
+
+	if len(roles) == 0 {
+		return Decision{Reason: fmt.Sprintf("principal %s is bound to no roles", describe(principal))}
+	}
+	return Decision{Reason: fmt.Sprintf("no role of principal %s (%s) allows %s", describe(principal), strings.Join(roles, ", "), access)}
+}
+
+func describe(principal string) string {
+	if principal == "" {
+		return "without credentials"
+	}
+	return fmt.Sprintf("%q", principal)
+}
@@ -0,0 +1,156 @@
+// Package tracing sets up the OpenTelemetry tracing both services share.
+// Trace context travels between them, and from REST clients, in W3C
+// traceparent headers and gRPC metadata.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/stats"
+)
+
+// Exporters spans can be sent to.
+const (
+	// None records no spans, but still passes trace context on.
+	None = "none"
+	// OTLP sends spans to an OpenTelemetry collector over gRPC.
+	OTLP = "otlp"
+	// Stdout writes spans to standard output as JSON.
+	Stdout = "stdout"
+	// File writes spans to Options.File as JSON, one per line.
+	File = "file"
+)
+
+type Options struct {
+	// ServiceName names the service in every span.
+	ServiceName string
+	// Exporter is one of None, OTLP, Stdout or File.
+	Exporter string
+	// Endpoint is the collector's host:port for OTLP. The
+	// OTEL_EXPORTER_OTLP_ENDPOINT environment variable, or localhost:4317,
+	// when empty.
+	Endpoint string
+	// Insecure sends spans to the collector in plaintext.
+	Insecure bool
+	// File is where the File exporter writes.
+	File string
+	// SampleRatio is the fraction of new traces to record. Traces started
+	// by a caller follow the caller's decision.
+	SampleRatio float64
+}
+
+// Provider records the spans of a service and sends them to its exporter.
+type Provider struct {
+	*sdktrace.TracerProvider
+	closer io.Closer
+}
+
+// StartProvider installs the propagator and, unless opts.Exporter is
+// None, a tracer provider as the global ones. The Provider is nil for None.
+func StartProvider(opts Options) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	switch opts.Exporter {
+	case None, "":
+		return nil, nil
+	case OTLP:
+		clientOpts := []otlptracegrpc.Option{}
+		if opts.Endpoint != "" {
+			clientOpts = append(clientOpts, otlptracegrpc.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+		}
+
+		var err error
+		exporter, err = otlptracegrpc.New(context.Background(), clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+	case Stdout:
+		var err error
+		exporter, err = stdouttrace.New()
+		if err != nil {
+			return nil, err
+		}
+	case File:
+		if opts.File == "" {
+			return nil, fmt.Errorf("the %s exporter needs a file to write to", File)
+		}
+
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		closer = f
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, expected %s, %s, %s or %s", opts.Exporter, None, OTLP, Stdout, File)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", opts.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	slog.Info("Exporting traces", "service", opts.ServiceName, "exporter", opts.Exporter)
+
+	return &Provider{TracerProvider: provider, closer: closer}, nil
+}
+
+// Shutdown sends the spans still buffered and stops exporting.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	err := p.TracerProvider.Shutdown(ctx)
+	if p.closer != nil {
+		if closeErr := p.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// ServerHandler traces the RPCs a gRPC server handles, continuing the
+// trace context callers send in their metadata. Health checks, which
+// probes make every few seconds, are not traced.
+func ServerHandler() stats.Handler {
+	return otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))
+}
+
+// ClientHandler traces the RPCs a gRPC client makes, sending the trace
+// context along in their metadata.
+func ClientHandler() stats.Handler {
+	return otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))
+}
@@ -36,7 +36,7 @@ wait_for_service() {
     echo -e "${YELLOW}⏳ Waiting for services to be ready...${NC}"
     elapsed=0
     while [ $elapsed -lt $MAX_WAIT ]; do
-        if curl -s "$BASE_URL/health" > /dev/null 2>&1; then
+        if curl -sf "$BASE_URL/readyz" > /dev/null 2>&1; then
             echo -e "${GREEN}✓ Services are ready!${NC}"
             return 0
         fi
@@ -128,6 +128,8 @@ run_test() {
 
 echo -e "${BLUE}--- Health Check ---${NC}"
 run_test "Health Check" "GET" "/health" "" "200"
+run_test "Liveness" "GET" "/livez" "" "200"
+run_test "Readiness" "GET" "/readyz" "" "200"
 
 echo ""
 echo -e "${BLUE}--- Set Operations ---${NC}"